@@ -0,0 +1,66 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type AlertConfig struct {
+	WEBHOOK_URLS       []string
+	NEW_ISSUE_COOLDOWN time.Duration
+	RATE_THRESHOLD     int
+	RATE_WINDOW        time.Duration
+	RATE_COOLDOWN      time.Duration
+	MAX_RETRIES        int
+}
+
+func GetAlertConfig() AlertConfig {
+	var urls []string
+	for _, url := range strings.Split(os.Getenv("ALERT_WEBHOOK_URLS"), ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			urls = append(urls, url)
+		}
+	}
+
+	return AlertConfig{
+		WEBHOOK_URLS:       urls,
+		NEW_ISSUE_COOLDOWN: getEnvDuration("ALERT_NEW_ISSUE_COOLDOWN", 5*time.Minute),
+		RATE_THRESHOLD:     getEnvInt("ALERT_RATE_THRESHOLD", 50),
+		RATE_WINDOW:        getEnvDuration("ALERT_RATE_WINDOW", 10*time.Minute),
+		RATE_COOLDOWN:      getEnvDuration("ALERT_RATE_COOLDOWN", 30*time.Minute),
+		MAX_RETRIES:        getEnvInt("ALERT_MAX_RETRIES", 3),
+	}
+}
+
+// getEnvInt reads a non-negative integer environment variable, falling back to the default when unset or invalid
+func getEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		log.Printf("⚠️ Warning: Invalid %s %q, using %d", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// getEnvDuration reads a duration environment variable (e.g. "10m"), falling back to the default when unset or invalid
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed < 0 {
+		log.Printf("⚠️ Warning: Invalid %s %q, using %s", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
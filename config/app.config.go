@@ -8,8 +8,9 @@ import (
 )
 
 type AppConfig struct {
-	ADMIN_AUTH_KEY string
-	ENVIRONMENT    string
+	ADMIN_AUTH_KEY    string
+	ENVIRONMENT       string
+	ERR_LOG_BATCH_MAX int
 }
 
 func GetAppConfig() AppConfig {
@@ -31,8 +32,14 @@ func GetAppConfig() AppConfig {
 		env = "development" // Set default environment
 	}
 
+	batchMax := getEnvInt("ERR_LOG_BATCH_MAX", 100)
+	if batchMax == 0 {
+		batchMax = 100
+	}
+
 	return AppConfig{
-		ADMIN_AUTH_KEY: adminKey,
-		ENVIRONMENT:    env,
+		ADMIN_AUTH_KEY:    adminKey,
+		ENVIRONMENT:       env,
+		ERR_LOG_BATCH_MAX: batchMax,
 	}
 }
@@ -11,6 +11,8 @@ type Messages struct {
 	User       UserMessages
 	API        APIMessages
 	Academic   AcademicMessages
+	Alert      AlertMessages
+	Privacy    PrivacyMessages
 }
 
 // SuccessMessages contains all success related messages
@@ -34,10 +36,14 @@ type ErrorMessages struct {
 
 // ValidationMessages contains all validation related messages
 type ValidationMessages struct {
-	Required      string
-	InvalidFormat string
-	TooLong       string
-	TooShort      string
+	Required           string
+	InvalidFormat      string
+	TooLong            string
+	TooShort           string
+	InvalidDept        string
+	InvalidRole        string
+	BatchOutOfRange    string
+	SemesterOutOfRange string
 }
 
 // GameMessages contains all game related messages
@@ -52,13 +58,28 @@ type GameMessages struct {
 
 // ErrorLogMessages contains all error logging related messages
 type ErrorLogMessages struct {
-	BadRequest            string
-	InvalidEmail          string
-	OperationUnsuccessful string
-	FetchError            string
-	LogInsertSuccess      string
-	LogsFetchSuccess      string
-	EmailFetchError       string
+	BadRequest              string
+	InvalidEmail            string
+	InvalidPayload          string
+	FieldTooLong            string
+	OperationUnsuccessful   string
+	FetchError              string
+	LogInsertSuccess        string
+	LogsFetchSuccess        string
+	EmailFetchError         string
+	IssuesFetchSuccess      string
+	IssueUpdateSuccess      string
+	IssueNotFound           string
+	InvalidIssueStatus      string
+	RetentionPreviewSuccess string
+	RetentionRunSuccess     string
+	RetentionDisabled       string
+	DuplicateEvent          string
+	BatchTooLarge           string
+	BatchProcessed          string
+	BatchRetryable          string
+	StatsFetchSuccess       string
+	InvalidDateRange        string
 }
 
 // MissedWordMessages contains all missed word related messages
@@ -67,6 +88,19 @@ type MissedWordMessages struct {
 	OperationUnsuccessful string
 	BadRequest            string
 	InsertSuccess         string
+	UnauthorizedAccess    string
+	InvalidSort           string
+	AggregateSuccess      string
+	InvalidClusterParams  string
+	InvalidMapping        string
+	MappingTargetNotFound string
+	MappingSuccess        string
+	MappingsFetchSuccess  string
+	MappingNotFound       string
+	MappingDeleteSuccess  string
+	InvalidWindow         string
+	InvalidPlatform       string
+	InvalidSession        string
 }
 
 // UserMessages contains all user related messages
@@ -78,6 +112,25 @@ type UserMessages struct {
 	CountError            string
 	IncrementSuccess      string
 	OperationUnsuccessful string
+	NotFound              string
+	EmailTaken            string
+	UpdateSuccess         string
+	DeleteSuccess         string
+	AlreadyRegistered     string
+	DedupeSuccess         string
+	AccountDeleted        string
+	RestoreSuccess        string
+	ValidationFailed      string
+	NormalizeSuccess      string
+	InvalidInterval       string
+	InvalidCSV            string
+	DuplicateInFile       string
+	ImportSuccess         string
+	ImageMissing          string
+	ImageTooLarge         string
+	ImageInvalidType      string
+	ImageInvalid          string
+	AvatarUploadSuccess   string
 }
 
 // APIMessages contains all API related messages
@@ -103,6 +156,38 @@ type AcademicMessages struct {
 	TopLabsError          string
 	SubjectUpdateError    string
 	LabUpdateError        string
+	BadRequest            string
+	ValidationFailed      string
+	InvalidSubjectType    string
+	SubjectNotFound       string
+	SubjectExists         string
+	SubjectSaveSuccess    string
+	SubjectDeleteSuccess  string
+	CountRowMissing       string
+	InvalidDateRange      string
+	InvalidNormalize      string
+}
+
+// AlertMessages contains all error alerting related messages
+type AlertMessages struct {
+	Disabled          string
+	TestSuccess       string
+	TestUnsuccessful  string
+	RulesFetchSuccess string
+}
+
+// PrivacyMessages contains all personal data export and erasure related messages
+type PrivacyMessages struct {
+	BadRequest            string
+	InvalidEmail          string
+	InvalidMode           string
+	OperationUnsuccessful string
+	ExportSuccess         string
+	EraseSuccess          string
+	AuditFetchSuccess     string
+	ArchiveScrubFailed    string
+	SessionsNotErased     string
+	InvalidSessions       string
 }
 
 // AppMessages is the global messages instance
@@ -123,10 +208,14 @@ var AppMessages = Messages{
 		FetchError:    "🔴 Error while fetching hof",
 	},
 	Validation: ValidationMessages{
-		Required:      "This field is required",
-		InvalidFormat: "Invalid format",
-		TooLong:       "Value is too long",
-		TooShort:      "Value is too short",
+		Required:           "This field is required",
+		InvalidFormat:      "Invalid format",
+		TooLong:            "Value is too long",
+		TooShort:           "Value is too short",
+		InvalidDept:        "Unknown department, see /users/catalog",
+		InvalidRole:        "Unknown role, see /users/catalog",
+		BatchOutOfRange:    "Batch must be a number within the accepted range, see /users/catalog",
+		SemesterOutOfRange: "Semester must be between 1 and 8, or 0 to clear it",
 	},
 	Game: GameMessages{
 		ScoreInsertSuccess:    "🟢 Game score insertion was successful",
@@ -137,19 +226,47 @@ var AppMessages = Messages{
 		FetchError:            "🔴 Error while fetching hof",
 	},
 	ErrorLog: ErrorLogMessages{
-		BadRequest:            "🔴 Bad Request",
-		InvalidEmail:          "🔴 Bad Request, Invalid Email",
-		OperationUnsuccessful: "🔴 Operation was unsuccessful!",
-		FetchError:            "🔴 Error while fetching logs by email",
-		LogInsertSuccess:      "🟢 New Error log insertion was successful",
-		LogsFetchSuccess:      "🟢 Logs Data fetching was successful",
-		EmailFetchError:       "🔴 Error while fetching logs by email",
+		BadRequest:              "🔴 Bad Request",
+		InvalidEmail:            "🔴 Bad Request, Invalid Email",
+		InvalidPayload:          "🔴 Bad Request - Invalid stack trace or tags",
+		FieldTooLong:            "🔴 Bad Request - App version, build, device, OS version or screen is too long",
+		OperationUnsuccessful:   "🔴 Operation was unsuccessful!",
+		FetchError:              "🔴 Error while fetching logs by email",
+		LogInsertSuccess:        "🟢 New Error log insertion was successful",
+		LogsFetchSuccess:        "🟢 Logs Data fetching was successful",
+		EmailFetchError:         "🔴 Error while fetching logs by email",
+		IssuesFetchSuccess:      "🟢 Issues fetching was successful",
+		IssueUpdateSuccess:      "🟢 Issue status update was successful",
+		IssueNotFound:           "🔴 Issue not found",
+		InvalidIssueStatus:      "🔴 Bad Request - Invalid issue status",
+		RetentionPreviewSuccess: "🟢 Retention preview was successful",
+		RetentionRunSuccess:     "🟢 Retention run was successful",
+		RetentionDisabled:       "🔴 Retention is disabled, no retention age is configured",
+		DuplicateEvent:          "🟢 Error log was already recorded",
+		BatchTooLarge:           "🔴 Bad Request - Too many error logs in one batch",
+		BatchProcessed:          "🟢 Error log batch was processed",
+		BatchRetryable:          "🔴 Some error logs could not be stored, retry the failed entries",
+		StatsFetchSuccess:       "🟢 Error stats fetching was successful",
+		InvalidDateRange:        "🔴 Bad Request - Dates must use the YYYY-MM-DD format and startDate must not be after endDate",
 	},
 	MissedWord: MissedWordMessages{
 		FetchError:            "🔴 Error while fetching missed words",
 		OperationUnsuccessful: "🔴 Operation was unsuccessful!",
 		BadRequest:            "🔴 Bad Request",
 		InsertSuccess:         "🟢 Word insertion was successful",
+		UnauthorizedAccess:    "🔴 Unauthorized Access !",
+		InvalidSort:           "🔴 Bad Request - Sort must be recent, frequency, last_seen or first_seen",
+		AggregateSuccess:      "🟢 Missed word aggregation was successful",
+		InvalidClusterParams:  "🔴 Bad Request - Metric must be trigram or levenshtein and threshold between 0 and 1",
+		InvalidMapping:        "🔴 Bad Request - A mapping needs words or ids, a target_type of subject, lab or intent and a target",
+		MappingTargetNotFound: "🔴 Bad Request - Mapping target does not exist",
+		MappingSuccess:        "🟢 Missed word mapping was successful",
+		MappingsFetchSuccess:  "🟢 Missed word mappings fetching was successful",
+		MappingNotFound:       "🔴 Missed word mapping not found",
+		MappingDeleteSuccess:  "🟢 Missed word mapping deletion was successful",
+		InvalidWindow:         "🔴 Bad Request - Window must be a number of days between 1 and 90, like 7d",
+		InvalidPlatform:       "🔴 Bad Request - Invalid Platform",
+		InvalidSession:        "🔴 Bad Request - Session id must be an anonymous id of at most 64 characters",
 	},
 	User: UserMessages{
 		UnauthorizedAccess:    "🔴 Unauthorized Access !",
@@ -159,6 +276,25 @@ var AppMessages = Messages{
 		CountError:            "🔴 Error while fetching app user count",
 		IncrementSuccess:      "🟢 Incrementing user count was successful",
 		OperationUnsuccessful: "🔴 Operation was unsuccessful!",
+		NotFound:              "🔴 User not found",
+		EmailTaken:            "🔴 Another user already uses this email",
+		UpdateSuccess:         "🟢 User info update was successful",
+		DeleteSuccess:         "🟢 User deletion was successful",
+		AlreadyRegistered:     "🟢 User was already registered, profile info is up to date",
+		DedupeSuccess:         "🟢 Duplicate user merge was successful",
+		AccountDeleted:        "🔴 This account was deleted, ask an admin to restore it",
+		RestoreSuccess:        "🟢 User restore was successful",
+		ValidationFailed:      "🔴 Bad Request - Invalid user fields",
+		NormalizeSuccess:      "🟢 User field normalisation was successful",
+		InvalidInterval:       "🔴 Bad Request - Interval must be day, week or month",
+		InvalidCSV:            "🔴 Bad Request - Invalid users CSV",
+		DuplicateInFile:       "Email appears more than once in the file",
+		ImportSuccess:         "🟢 User import was successful",
+		ImageMissing:          "🔴 Bad Request - Missing image file",
+		ImageTooLarge:         "🔴 Image is too large",
+		ImageInvalidType:      "🔴 Unsupported image type, use JPEG, PNG or GIF",
+		ImageInvalid:          "🔴 Bad Request - Image could not be decoded",
+		AvatarUploadSuccess:   "🟢 Profile image upload was successful",
 	},
 	API: APIMessages{
 		UnauthorizedAccess:    "🔴 Unauthorized Access !",
@@ -180,5 +316,33 @@ var AppMessages = Messages{
 		TopLabsError:          "🔴 Error while retrieving top lab subjects",
 		SubjectUpdateError:    "🔴 Error while updating count for subject",
 		LabUpdateError:        "🔴 Error while updating count for lab",
+		BadRequest:            "🔴 Bad Request",
+		ValidationFailed:      "🔴 Bad Request - Invalid subject fields",
+		InvalidSubjectType:    "🔴 Bad Request - Subject type must be note or lab",
+		SubjectNotFound:       "🔴 Subject not found in the catalog",
+		SubjectExists:         "🔴 A subject with this type and code already exists",
+		SubjectSaveSuccess:    "🟢 Subject catalog entry was saved",
+		SubjectDeleteSuccess:  "🟢 Subject catalog entry deletion was successful",
+		CountRowMissing:       "🔴 Subject has no count row, see /catalog/subjects/report",
+		InvalidDateRange:      "🔴 Bad Request - Dates must use the YYYY-MM-DD format and span at most 366 days",
+		InvalidNormalize:      "🔴 Bad Request - Normalize must be dept or left out",
+	},
+	Alert: AlertMessages{
+		Disabled:          "🔴 Alerting is disabled, no webhook URL is configured",
+		TestSuccess:       "🟢 Test alert delivery was successful",
+		TestUnsuccessful:  "🔴 Test alert delivery was unsuccessful!",
+		RulesFetchSuccess: "🟢 Alert rules fetching was successful",
+	},
+	Privacy: PrivacyMessages{
+		BadRequest:            "🔴 Bad Request",
+		InvalidEmail:          "🔴 Bad Request, Invalid Email",
+		InvalidMode:           "🔴 Bad Request - Mode must be delete or anonymise",
+		OperationUnsuccessful: "🔴 Operation was unsuccessful!",
+		ExportSuccess:         "🟢 Personal data export was successful",
+		EraseSuccess:          "🟢 Personal data erasure was successful",
+		ArchiveScrubFailed:    "🔴 Personal data was erased from the database but the error log archives could not be scrubbed, retry the erasure",
+		AuditFetchSuccess:     "🟢 Erasure audit fetching was successful",
+		SessionsNotErased:     "Missed word events are only linked to anonymous session ids; pass the user's session_ids to erase the messages they sent",
+		InvalidSessions:       "🔴 Bad Request - Session ids must be anonymous ids of at most 64 characters",
 	},
 }
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+)
+
+type MissedWordConfig struct {
+	REMOVE_STOPWORDS bool
+}
+
+func GetMissedWordConfig() MissedWordConfig {
+	return MissedWordConfig{
+		REMOVE_STOPWORDS: getEnvBool("MISSED_WORD_REMOVE_STOPWORDS", false),
+	}
+}
+
+// getEnvBool reads a boolean env var, falling back when it is unset or invalid
+func getEnvBool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("⚠️ Warning: Invalid %s %q, using %t", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
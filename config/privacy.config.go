@@ -0,0 +1,27 @@
+package config
+
+import (
+	"log"
+	"os"
+)
+
+type PrivacyConfig struct {
+	HASH_SECRET string
+}
+
+// GetPrivacyConfig reads the secret that keys erasure audit hashes. Changing it makes
+// older audit records unsearchable by email, so it must be its own value, set once and
+// kept, rather than a key that gets rotated such as ADMIN_KEY.
+func GetPrivacyConfig() PrivacyConfig {
+	secret := os.Getenv("PRIVACY_HASH_SECRET")
+	if secret == "" {
+		log.Fatal("🔴 PRIVACY_HASH_SECRET environment variable is required")
+	}
+	if secret == os.Getenv("ADMIN_KEY") {
+		log.Fatal("🔴 PRIVACY_HASH_SECRET must differ from ADMIN_KEY")
+	}
+
+	return PrivacyConfig{
+		HASH_SECRET: secret,
+	}
+}
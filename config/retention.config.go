@@ -0,0 +1,49 @@
+package config
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+const (
+	RetentionModeDelete  = "delete"
+	RetentionModeArchive = "archive"
+)
+
+type RetentionConfig struct {
+	MAX_AGE     time.Duration
+	MODE        string
+	ARCHIVE_DIR string
+	INTERVAL    time.Duration
+	BATCH_SIZE  int
+}
+
+func GetRetentionConfig() RetentionConfig {
+	mode := os.Getenv("ERR_LOG_RETENTION_MODE")
+	if mode == "" {
+		mode = RetentionModeArchive
+	}
+	if mode != RetentionModeDelete && mode != RetentionModeArchive {
+		log.Printf("⚠️ Warning: Invalid ERR_LOG_RETENTION_MODE %q, using %s", mode, RetentionModeArchive)
+		mode = RetentionModeArchive
+	}
+
+	archiveDir := os.Getenv("ERR_LOG_ARCHIVE_DIR")
+	if archiveDir == "" {
+		archiveDir = "archive"
+	}
+
+	batchSize := getEnvInt("ERR_LOG_RETENTION_BATCH_SIZE", 1000)
+	if batchSize == 0 {
+		batchSize = 1000
+	}
+
+	return RetentionConfig{
+		MAX_AGE:     time.Duration(getEnvInt("ERR_LOG_RETENTION_DAYS", 90)) * 24 * time.Hour,
+		MODE:        mode,
+		ARCHIVE_DIR: archiveDir,
+		INTERVAL:    getEnvDuration("ERR_LOG_RETENTION_INTERVAL", 24*time.Hour),
+		BATCH_SIZE:  batchSize,
+	}
+}
@@ -0,0 +1,31 @@
+package config
+
+import "os"
+
+type StorageConfig struct {
+	UPLOAD_DIR        string
+	UPLOAD_BASE_URL   string
+	AVATAR_MAX_BYTES  int
+	AVATAR_MAX_PIXELS int
+	AVATAR_THUMB_SIZE int
+}
+
+func GetStorageConfig() StorageConfig {
+	uploadDir := os.Getenv("UPLOAD_DIR")
+	if uploadDir == "" {
+		uploadDir = "uploads"
+	}
+
+	baseURL := os.Getenv("UPLOAD_BASE_URL")
+	if baseURL == "" {
+		baseURL = "/uploads"
+	}
+
+	return StorageConfig{
+		UPLOAD_DIR:        uploadDir,
+		UPLOAD_BASE_URL:   baseURL,
+		AVATAR_MAX_BYTES:  getEnvInt("AVATAR_MAX_BYTES", 2*1024*1024),
+		AVATAR_MAX_PIXELS: getEnvInt("AVATAR_MAX_PIXELS", 4096*4096),
+		AVATAR_THUMB_SIZE: getEnvInt("AVATAR_THUMB_SIZE", 256),
+	}
+}
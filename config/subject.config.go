@@ -0,0 +1,23 @@
+package config
+
+// Subject types in the subject catalog
+const (
+	SubjectTypeNote = "note"
+	SubjectTypeLab  = "lab"
+)
+
+// BUTEX programmes run for eight semesters
+const (
+	SubjectSemesterMin = 1
+	SubjectSemesterMax = 8
+)
+
+type SubjectConfig struct {
+	AUTO_REGISTER bool
+}
+
+func GetSubjectConfig() SubjectConfig {
+	return SubjectConfig{
+		AUTO_REGISTER: getEnvBool("SUBJECT_AUTO_REGISTER", false),
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+// Department is a BUTEX department accepted for app users. Users send all kinds of
+// spellings, so Aliases lists the common ones besides the code and the full name.
+type Department struct {
+	Code    string   `json:"code"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// UserRole is a role accepted for app users
+type UserRole struct {
+	Code    string   `json:"code"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// Departments is the catalog of valid user departments
+var Departments = []Department{
+	{Code: "TE", Name: "Textile Engineering", Aliases: []string{"textile", "textile eng", "tex"}},
+	{Code: "YE", Name: "Yarn Engineering", Aliases: []string{"yme", "yarn manufacturing engineering"}},
+	{Code: "FE", Name: "Fabric Engineering", Aliases: []string{"fme", "fabric manufacturing engineering"}},
+	{Code: "WPE", Name: "Wet Process Engineering", Aliases: []string{"wet processing engineering"}},
+	{Code: "AE", Name: "Apparel Engineering", Aliases: []string{"ame", "apparel manufacturing engineering"}},
+	{Code: "FDAE", Name: "Fashion Design and Apparel Engineering", Aliases: []string{"fd", "fashion design"}},
+	{Code: "TEM", Name: "Textile Engineering Management", Aliases: []string{"textile management"}},
+	{Code: "TMDM", Name: "Textile Machinery Design and Maintenance", Aliases: []string{"tmd", "textile machinery"}},
+	{Code: "IPE", Name: "Industrial and Production Engineering", Aliases: []string{"industrial production"}},
+	{Code: "DCE", Name: "Dyes and Chemical Engineering", Aliases: []string{"dyes", "dyes chemical"}},
+	{Code: "ESE", Name: "Environmental Science and Engineering", Aliases: []string{"environmental science"}},
+}
+
+// UserRoles is the catalog of valid user roles
+var UserRoles = []UserRole{
+	{Code: "student", Aliases: []string{"std", "stu", "undergrad"}},
+	{Code: "teacher", Aliases: []string{"faculty", "lecturer", "professor"}},
+	{Code: "alumni", Aliases: []string{"alumnus", "graduate", "ex student"}},
+	{Code: "staff", Aliases: []string{"employee", "officer"}},
+}
+
+// Batches are numbered from the first intake; anything outside this range is a typo
+const (
+	UserBatchMin = 1
+	UserBatchMax = 99
+)
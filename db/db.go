@@ -23,10 +23,11 @@ func InitDB() *gorm.DB {
 
 	log.Println("⏳ Connecting to database...")
 
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	// TranslateError maps driver errors such as duplicate keys to gorm.ErrDuplicatedKey
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{TranslateError: true})
 
 	if err != nil {
-		log.Println("🔴 DSN: %v", dsn)
+		log.Printf("🔴 DSN: %v", dsn)
 		panic(fmt.Sprintf("🔴 Failed to connect database: %v", err))
 	}
 	log.Println("🟢 Connected to database")
@@ -0,0 +1,429 @@
+package db
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"gorm.io/gorm"
+)
+
+// migration is a single idempotent schema change. Every migration runs on each
+// startup, so it must be safe to apply to a database that already has it. Data
+// migrations too costly to repeat set Once, they are recorded in schema_migrations
+// and skipped from then on.
+type migration struct {
+	Name string
+	Run  func(db *gorm.DB) error
+	Once bool
+}
+
+var migrations = []migration{
+	{Name: "create app_err_issues", Run: createErrorIssuesTable},
+	{Name: "add app_err_logs issue columns", Run: addErrorLogIssueColumns},
+	{Name: "add app_err_logs client metadata columns", Run: addErrorLogMetadataColumns},
+	{Name: "add app_err_logs event_id", Run: addErrorLogEventID},
+	{Name: "add app_users deleted_at", Run: addUserDeletedAt},
+	{Name: "add app_users unique email", Run: addUserUniqueEmail},
+	{Name: "normalize app_users batch, dept and role", Run: normalizeUsers, Once: true},
+	{Name: "add app_users created_at", Run: addUserCreatedAt},
+	{Name: "create data_erasure_audit", Run: createErasureAuditTable},
+	{Name: "add missed_words_table aggregation columns", Run: addMissedWordAggregation},
+	{Name: "create missed_word_mappings", Run: createMissedWordMappings},
+	{Name: "create missed_word_daily", Run: createMissedWordDaily},
+	{Name: "create missed_word_events", Run: createMissedWordEvents},
+	{Name: "create subject_catalog", Run: createSubjectCatalog},
+	{Name: "add subject_catalog pending", Run: addSubjectCatalogPending},
+	{Name: "create subject_daily_counts", Run: createSubjectDailyCounts},
+}
+
+// Migrate applies all schema migrations in order
+func Migrate(db *gorm.DB) {
+	log.Println("⏳ Running database migrations...")
+	if err := createSchemaMigrations(db); err != nil {
+		panic("🔴 Migration \"create schema_migrations\" failed: " + err.Error())
+	}
+	for _, m := range migrations {
+		if err := runMigration(db, m); err != nil {
+			panic("🔴 Migration \"" + m.Name + "\" failed: " + err.Error())
+		}
+	}
+	log.Println("🟢 Database migrations completed")
+}
+
+// addColumnIfMissing adds a column to an existing table unless it is already there
+// schema_migrations records the Once migrations that have been applied
+func createSchemaMigrations(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			name VARCHAR(128) PRIMARY KEY,
+			applied_at DATETIME NOT NULL
+		) DEFAULT CHARSET=utf8mb4
+	`).Error
+}
+
+// runMigration applies a migration, skipping Once migrations that are already recorded
+func runMigration(db *gorm.DB, m migration) error {
+	if !m.Once {
+		return m.Run(db)
+	}
+
+	var applied int64
+	if err := db.Raw("SELECT COUNT(*) FROM schema_migrations WHERE name = ?", m.Name).Scan(&applied).Error; err != nil {
+		return err
+	}
+	if applied > 0 {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := m.Run(tx); err != nil {
+			return err
+		}
+		return tx.Exec("INSERT INTO schema_migrations (name, applied_at) VALUES (?, ?)", m.Name, time.Now()).Error
+	})
+}
+
+func addColumnIfMissing(db *gorm.DB, table, column, definition string) error {
+	if db.Migrator().HasColumn(table, column) {
+		return nil
+	}
+	return db.Exec("ALTER TABLE " + table + " ADD COLUMN " + column + " " + definition).Error
+}
+
+// addIndexIfMissing creates an index on an existing table unless it is already there
+func addIndexIfMissing(db *gorm.DB, table, index, definition string) error {
+	if db.Migrator().HasIndex(table, index) {
+		return nil
+	}
+	return db.Exec("CREATE " + definition).Error
+}
+
+func createErrorIssuesTable(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE TABLE IF NOT EXISTS app_err_issues (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			fingerprint CHAR(40) NOT NULL UNIQUE,
+			title VARCHAR(255) NOT NULL,
+			status VARCHAR(16) NOT NULL DEFAULT 'open',
+			occurrences INT NOT NULL DEFAULT 0,
+			first_seen DATETIME NOT NULL,
+			last_seen DATETIME NOT NULL,
+			resolved_at DATETIME NULL,
+			resolved_in_version VARCHAR(64) NULL,
+			is_regression BOOLEAN NOT NULL DEFAULT FALSE,
+			regressed_at DATETIME NULL,
+			regressed_in_version VARCHAR(64) NULL,
+			INDEX idx_app_err_issues_status (status)
+		) DEFAULT CHARSET=utf8mb4
+	`).Error
+}
+
+func addErrorLogIssueColumns(db *gorm.DB) error {
+	if err := addColumnIfMissing(db, "app_err_logs", "fingerprint", "CHAR(40) NULL"); err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(db, "app_err_logs", "app_version", "VARCHAR(64) NULL"); err != nil {
+		return err
+	}
+	return addIndexIfMissing(db, "app_err_logs", "idx_app_err_logs_fingerprint",
+		"INDEX idx_app_err_logs_fingerprint ON app_err_logs (fingerprint)")
+}
+
+func addErrorLogMetadataColumns(db *gorm.DB) error {
+	columns := []struct{ Name, Definition string }{
+		{"app_build", "VARCHAR(64) NULL"},
+		{"device_model", "VARCHAR(128) NULL"},
+		{"os_version", "VARCHAR(64) NULL"},
+		{"screen", "VARCHAR(255) NULL"},
+		{"stack_trace", "JSON NULL"},
+		{"tags", "JSON NULL"},
+	}
+	for _, column := range columns {
+		if err := addColumnIfMissing(db, "app_err_logs", column.Name, column.Definition); err != nil {
+			return err
+		}
+	}
+	return addIndexIfMissing(db, "app_err_logs", "idx_app_err_logs_app_version",
+		"INDEX idx_app_err_logs_app_version ON app_err_logs (app_version)")
+}
+
+func addErrorLogEventID(db *gorm.DB) error {
+	if err := addColumnIfMissing(db, "app_err_logs", "event_id", "VARCHAR(64) NULL"); err != nil {
+		return err
+	}
+	return addIndexIfMissing(db, "app_err_logs", "uq_app_err_logs_event_id",
+		"UNIQUE INDEX uq_app_err_logs_event_id ON app_err_logs (event_id)")
+}
+
+func addUserDeletedAt(db *gorm.DB) error {
+	if err := addColumnIfMissing(db, "app_users", "deleted_at", "DATETIME NULL"); err != nil {
+		return err
+	}
+	return addIndexIfMissing(db, "app_users", "idx_app_users_deleted_at",
+		"INDEX idx_app_users_deleted_at ON app_users (deleted_at)")
+}
+
+func addUserUniqueEmail(db *gorm.DB) error {
+	created, err := EnsureUserEmailUniqueIndex(db)
+	if err != nil {
+		return err
+	}
+	if !created {
+		log.Println("⚠️ app_users has duplicate emails, unique email index skipped until POST /users/app/dedupe is run")
+	}
+	return nil
+}
+
+// EnsureUserEmailUniqueIndex adds the unique email index to app_users. The index cannot be
+// created while duplicate emails exist, in which case it reports false and changes nothing.
+func EnsureUserEmailUniqueIndex(db *gorm.DB) (bool, error) {
+	if db.Migrator().HasIndex("app_users", "uq_app_users_email") {
+		return true, nil
+	}
+
+	var duplicates int64
+	if err := db.Raw(`
+		SELECT COUNT(*) FROM (
+			SELECT email FROM app_users GROUP BY email HAVING COUNT(*) > 1
+		) t`).Scan(&duplicates).Error; err != nil {
+		return false, err
+	}
+	if duplicates > 0 {
+		return false, nil
+	}
+
+	if err := db.Exec("CREATE UNIQUE INDEX uq_app_users_email ON app_users (email)").Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Existing users are rewritten to catalog values once, so dept and role filters match every
+// spelling; users written since are validated. Values that cannot be mapped stay as they are
+// until fixed by hand or through POST /users/app/normalize.
+func normalizeUsers(db *gorm.DB) error {
+	result, err := NormalizeUserValues(db, false)
+	if err != nil {
+		return err
+	}
+	if result.RowsUpdated > 0 {
+		log.Printf("🟢 Normalised batch, dept or role of %d app users", result.RowsUpdated)
+	}
+
+	unmapped := 0
+	for _, values := range result.Unmappable {
+		for _, value := range values {
+			unmapped += value.Count
+		}
+	}
+	if unmapped > 0 {
+		log.Printf("⚠️ %d app user values could not be normalised, see POST /users/app/normalize?dryRun=true", unmapped)
+	}
+	return nil
+}
+
+func addUserCreatedAt(db *gorm.DB) error {
+	if db.Migrator().HasColumn("app_users", "created_at") {
+		return nil
+	}
+
+	// Add the column without a default first so existing users stay undated instead of
+	// all getting the migration time, then default new rows to their insert time
+	if err := db.Exec("ALTER TABLE app_users ADD COLUMN created_at DATETIME NULL").Error; err != nil {
+		return err
+	}
+	return db.Exec("ALTER TABLE app_users MODIFY COLUMN created_at DATETIME NULL DEFAULT CURRENT_TIMESTAMP").Error
+}
+
+func createErasureAuditTable(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE TABLE IF NOT EXISTS data_erasure_audit (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			email_hash CHAR(64) NOT NULL,
+			mode VARCHAR(16) NOT NULL,
+			rows_affected JSON NOT NULL,
+			reason VARCHAR(255) NULL,
+			requested_ip VARCHAR(64) NULL,
+			created_at DATETIME NOT NULL,
+			INDEX idx_data_erasure_audit_email_hash (email_hash)
+		) DEFAULT CHARSET=utf8mb4
+	`).Error
+}
+
+func addMissedWordAggregation(db *gorm.DB) error {
+	// Legacy rows are one occurrence each and have no known timestamps
+	if err := addColumnIfMissing(db, "missed_words_table", "normalized_word", "VARCHAR(255) NULL"); err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(db, "missed_words_table", "count", "INT NOT NULL DEFAULT 1"); err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(db, "missed_words_table", "first_seen", "DATETIME NULL"); err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(db, "missed_words_table", "last_seen", "DATETIME NULL"); err != nil {
+		return err
+	}
+	if err := addIndexIfMissing(db, "missed_words_table", "uq_missed_words_normalized_word",
+		"UNIQUE INDEX uq_missed_words_normalized_word ON missed_words_table (normalized_word)"); err != nil {
+		return err
+	}
+
+	var pending int64
+	if err := db.Raw("SELECT COUNT(*) FROM missed_words_table WHERE normalized_word IS NULL").Scan(&pending).Error; err != nil {
+		return err
+	}
+	if pending > 0 {
+		log.Printf("⚠️ %d missed words are not aggregated yet, run POST /missed/aggregate to merge them", pending)
+	}
+	return nil
+}
+
+func createMissedWordMappings(db *gorm.DB) error {
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS missed_word_mappings (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			normalized_word VARCHAR(255) NOT NULL UNIQUE,
+			target_type VARCHAR(16) NOT NULL,
+			target VARCHAR(255) NOT NULL,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL,
+			INDEX idx_missed_word_mappings_target (target_type, target)
+		) DEFAULT CHARSET=utf8mb4
+	`).Error; err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(db, "missed_words_table", "resolved_at", "DATETIME NULL"); err != nil {
+		return err
+	}
+	return addIndexIfMissing(db, "missed_words_table", "idx_missed_words_resolved_at",
+		"INDEX idx_missed_words_resolved_at ON missed_words_table (resolved_at)")
+}
+
+// Per day occurrence counts of each missed word, kept from when this table was added
+func createMissedWordDaily(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE TABLE IF NOT EXISTS missed_word_daily (
+			missed_word_id INT NOT NULL,
+			day DATE NOT NULL,
+			count INT NOT NULL DEFAULT 0,
+			PRIMARY KEY (missed_word_id, day),
+			INDEX idx_missed_word_daily_day (day)
+		) DEFAULT CHARSET=utf8mb4
+	`).Error
+}
+
+// One row per missed word occurrence with the context it was sent in
+func createMissedWordEvents(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE TABLE IF NOT EXISTS missed_word_events (
+			id BIGINT AUTO_INCREMENT PRIMARY KEY,
+			missed_word_id INT NOT NULL,
+			raw_word VARCHAR(1024) NOT NULL,
+			platform VARCHAR(16) NULL,
+			session_id VARCHAR(64) NULL,
+			previous_message TEXT NULL,
+			created_at DATETIME NOT NULL,
+			INDEX idx_missed_word_events_word (missed_word_id),
+			INDEX idx_missed_word_events_platform (platform),
+			INDEX idx_missed_word_events_session (session_id)
+		) DEFAULT CHARSET=utf8mb4
+	`).Error
+}
+
+// subjectSeed is a subject that had its own hardcoded route before the catalog existed. Code is
+// the URL segment, SubName the row in subnamedb or labsdb. Dept is set for subjects taught by one
+// department and left empty for those every department shares. Semester is set where the course
+// numbering gives it: the first year sciences (I and II in semesters 1 and 2) and the second year
+// manufacturing and processing courses (I and II in semesters 3 and 4).
+type subjectSeed struct {
+	Code     string
+	SubName  string
+	Dept     string
+	Semester int
+}
+
+var subjectCatalogSeed = map[string][]subjectSeed{
+	config.SubjectTypeNote: {
+		{"math1", "math1", "", 1}, {"math2", "math2", "", 2}, {"phy1", "phy1", "", 1}, {"phy2", "phy2", "", 2},
+		{"chem1", "chem1", "", 1}, {"chem2", "chem2", "", 2}, {"pse", "pse", "", 0}, {"cp", "cp", "", 0},
+		{"ntf", "ntf", "", 0}, {"em", "em", "", 0}, {"bce", "bce", "", 0},
+		{"am1", "am1", "AE", 3}, {"am2", "am2", "AE", 4}, {"ym1", "ym1", "YE", 3}, {"ym2", "ym2", "YE", 4},
+		{"fm1", "fm1", "FE", 3}, {"fm2", "fm2", "FE", 4}, {"wp1", "wp1", "WPE", 3}, {"wp2", "wp2", "WPE", 4},
+		{"stat", "stat", "", 0}, {"feee", "feee", "", 0}, {"market", "marketing", "", 0}, {"ttqc", "ttqc", "", 0},
+		{"tp", "tp", "", 0}, {"mp", "mp", "", 0}, {"mmtf", "mmtf", "", 0}, {"acm", "acm", "", 0},
+		{"tqm", "tqm", "", 0}, {"fsd", "fsd", "FDAE", 0}, {"ace", "ace", "", 0}, {"mic", "mic", "", 0},
+		{"sss1", "sss1", "", 0}, {"sss2", "sss2", "", 0}, {"wpp", "wpp", "", 0}, {"econo", "econo", "", 0},
+	},
+	config.SubjectTypeLab: {
+		{"phy1", "phy1", "", 1}, {"phy2", "phy2", "", 2}, {"chem1", "chem1", "", 1}, {"chem2", "chem2", "", 2},
+		{"cp", "cp", "", 0}, {"bce", "bce", "", 0}, {"msp", "msp", "", 0},
+		{"am1", "am1", "AE", 3}, {"am2", "am2", "AE", 4}, {"ym1", "ym1", "YE", 3}, {"ym2", "ym2", "YE", 4},
+		{"wp1", "wp1", "WPE", 3}, {"wp2", "wp2", "WPE", 4}, {"fm1", "fm1", "FE", 3}, {"fm2", "fm2", "FE", 4},
+		{"feee", "feee", "", 0}, {"fme", "fme", "", 0}, {"ttqc", "ttqc", "", 0}, {"ap1", "ap1", "", 0},
+		{"ap2", "ap2", "", 0}, {"mp", "mp", "", 0}, {"fsd", "fsd", "FDAE", 0}, {"lss", "lss", "", 0},
+	},
+}
+
+func createSubjectCatalog(db *gorm.DB) error {
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS subject_catalog (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			code VARCHAR(32) NOT NULL,
+			type VARCHAR(8) NOT NULL,
+			sub_name VARCHAR(64) NOT NULL,
+			display_name VARCHAR(128) NOT NULL,
+			dept VARCHAR(16) NULL,
+			semester TINYINT NULL,
+			active TINYINT(1) NOT NULL DEFAULT 1,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE KEY uq_subject_catalog_type_code (type, code)
+		) DEFAULT CHARSET=utf8mb4
+	`).Error; err != nil {
+		return err
+	}
+
+	// Seed only an empty catalog, so subjects removed by an admin don't come back on restart
+	var existing int64
+	if err := db.Raw("SELECT COUNT(*) FROM subject_catalog").Scan(&existing).Error; err != nil {
+		return err
+	}
+	if existing > 0 {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, subjectType := range []string{config.SubjectTypeNote, config.SubjectTypeLab} {
+			for _, subject := range subjectCatalogSeed[subjectType] {
+				if err := tx.Exec(`
+					INSERT INTO subject_catalog (code, type, sub_name, display_name, dept, semester)
+					VALUES (?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, 0))`,
+					subject.Code, subjectType, subject.SubName, strings.ToUpper(subject.Code), subject.Dept, subject.Semester,
+				).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Pending subjects were auto registered from an unknown code and wait for an admin to review them
+func addSubjectCatalogPending(db *gorm.DB) error {
+	return addColumnIfMissing(db, "subject_catalog", "pending", "TINYINT(1) NOT NULL DEFAULT 0")
+}
+
+// Per day access counts of each note and lab, next to the lifetime counters in subnamedb and labsdb
+func createSubjectDailyCounts(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE TABLE IF NOT EXISTS subject_daily_counts (
+			type VARCHAR(8) NOT NULL,
+			sub_name VARCHAR(64) NOT NULL,
+			day DATE NOT NULL,
+			count INT NOT NULL DEFAULT 0,
+			PRIMARY KEY (type, sub_name, day),
+			INDEX idx_subject_daily_counts_day (type, day)
+		) DEFAULT CHARSET=utf8mb4
+	`).Error
+}
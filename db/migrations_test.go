@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/utils"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// migrationRecorder is a database/sql driver that records statements and answers every
+// schema_migrations lookup with applied
+type migrationRecorder struct {
+	applied    int64
+	statements []string
+}
+
+func (r *migrationRecorder) Connect(context.Context) (driver.Conn, error) { return r, nil }
+func (r *migrationRecorder) Driver() driver.Driver                        { return nil }
+func (r *migrationRecorder) Prepare(string) (driver.Stmt, error)          { return nil, driver.ErrSkip }
+func (r *migrationRecorder) Close() error                                 { return nil }
+func (r *migrationRecorder) Begin() (driver.Tx, error)                    { return r, nil }
+func (r *migrationRecorder) Commit() error                                { return nil }
+func (r *migrationRecorder) Rollback() error                              { return nil }
+
+func (r *migrationRecorder) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	r.statements = append(r.statements, query)
+	return driver.RowsAffected(0), nil
+}
+
+func (r *migrationRecorder) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	r.statements = append(r.statements, query)
+	return &countRows{count: r.applied}, nil
+}
+
+type countRows struct {
+	count int64
+	done  bool
+}
+
+func (r *countRows) Columns() []string { return []string{"COUNT(*)"} }
+func (r *countRows) Close() error      { return nil }
+
+func (r *countRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	dest[0] = r.count
+	r.done = true
+	return nil
+}
+
+func TestRunMigration(t *testing.T) {
+	tests := []struct {
+		name    string
+		once    bool
+		applied int64
+		runs    int
+		marks   int
+	}{
+		{"repeatable migration runs every time", false, 1, 1, 0},
+		{"once migration runs and is recorded", true, 0, 1, 1},
+		{"recorded once migration is skipped", true, 1, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &migrationRecorder{applied: tt.applied}
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sql.OpenDB(rec),
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{DisableAutomaticPing: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			runs := 0
+			m := migration{Name: "test", Once: tt.once, Run: func(*gorm.DB) error {
+				runs++
+				return nil
+			}}
+			if err := runMigration(db, m); err != nil {
+				t.Fatal(err)
+			}
+
+			marks := 0
+			for _, statement := range rec.statements {
+				if strings.Contains(statement, "INSERT INTO schema_migrations") {
+					marks++
+				}
+			}
+			if runs != tt.runs || marks != tt.marks {
+				t.Errorf("ran %d times and recorded %d markers, want %d and %d", runs, marks, tt.runs, tt.marks)
+			}
+		})
+	}
+}
+
+func TestSubjectCatalogSeed(t *testing.T) {
+	for subjectType, subjects := range subjectCatalogSeed {
+		codes := map[string]bool{}
+		for _, subject := range subjects {
+			if codes[subject.Code] {
+				t.Errorf("%s %s is seeded twice", subjectType, subject.Code)
+			}
+			codes[subject.Code] = true
+
+			if subject.Dept != "" {
+				if code, ok := utils.NormalizeDept(subject.Dept); !ok || code != subject.Dept {
+					t.Errorf("%s %s has dept %q, not a catalog code", subjectType, subject.Code, subject.Dept)
+				}
+			}
+			if subject.Semester != 0 && (subject.Semester < config.SubjectSemesterMin || subject.Semester > config.SubjectSemesterMax) {
+				t.Errorf("%s %s has semester %d out of range", subjectType, subject.Code, subject.Semester)
+			}
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"strings"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/utils"
+	"gorm.io/gorm"
+)
+
+// UserValueChange counts users whose field value was rewritten from one spelling to another
+type UserValueChange struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Count int    `json:"count"`
+}
+
+// UnmappedUserValue is a field value that does not match any catalog entry
+type UnmappedUserValue struct {
+	Value   string  `json:"value"`
+	Count   int     `json:"count"`
+	UserIDs []int64 `json:"user_ids"`
+}
+
+// UserNormalization reports the changes made, or planned, by NormalizeUserValues.
+// Changes and Unmappable are keyed by field name (batch, dept and role).
+type UserNormalization struct {
+	RowsUpdated int64                           `json:"rows_updated"`
+	Changes     map[string][]*UserValueChange   `json:"changes"`
+	Unmappable  map[string][]*UnmappedUserValue `json:"unmappable"`
+}
+
+// normalizeUserValue maps a batch, dept or role to its catalog value
+func normalizeUserValue(field, value string) (string, bool) {
+	switch field {
+	case "batch":
+		return utils.NormalizeBatch(value)
+	case "dept":
+		return utils.NormalizeDept(value)
+	case "role":
+		return utils.NormalizeRole(strings.TrimSpace(value))
+	}
+	return "", false
+}
+
+// NormalizeUserValues rewrites the batch, dept and role of existing users to their catalog
+// values in one transaction. Values that cannot be mapped are left untouched and reported so
+// they can be fixed by hand. With dryRun the changes are only reported.
+func NormalizeUserValues(db *gorm.DB, dryRun bool) (UserNormalization, error) {
+	fields := []string{"batch", "dept", "role"}
+	changes := map[string]map[string]*UserValueChange{}
+	unmappable := map[string]map[string]*UnmappedUserValue{}
+	for _, field := range fields {
+		changes[field] = map[string]*UserValueChange{}
+		unmappable[field] = map[string]*UnmappedUserValue{}
+	}
+	var rowsUpdated int64
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var users []struct {
+			ID    int64
+			Batch string
+			Dept  string
+			Role  string
+		}
+		if err := tx.Raw("SELECT id, batch, dept, role FROM app_users FOR UPDATE").Scan(&users).Error; err != nil {
+			return err
+		}
+
+		for _, user := range users {
+			values := map[string]*string{"batch": &user.Batch, "dept": &user.Dept, "role": &user.Role}
+
+			changed := false
+			for _, field := range fields {
+				value := values[field]
+				normalized, ok := normalizeUserValue(field, *value)
+				if !ok {
+					entry, found := unmappable[field][*value]
+					if !found {
+						entry = &UnmappedUserValue{Value: *value, UserIDs: []int64{}}
+						unmappable[field][*value] = entry
+					}
+					entry.Count++
+					entry.UserIDs = append(entry.UserIDs, user.ID)
+					continue
+				}
+				if normalized == *value {
+					continue
+				}
+
+				key := *value + "\x00" + normalized
+				entry, found := changes[field][key]
+				if !found {
+					entry = &UserValueChange{From: *value, To: normalized}
+					changes[field][key] = entry
+				}
+				entry.Count++
+				*value = normalized
+				changed = true
+			}
+
+			if !changed {
+				continue
+			}
+			rowsUpdated++
+			if dryRun {
+				continue
+			}
+			if err := tx.Exec("UPDATE app_users SET batch = ?, dept = ?, role = ? WHERE id = ?",
+				user.Batch, user.Dept, user.Role, user.ID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return UserNormalization{}, err
+	}
+
+	result := UserNormalization{
+		RowsUpdated: rowsUpdated,
+		Changes:     map[string][]*UserValueChange{},
+		Unmappable:  map[string][]*UnmappedUserValue{},
+	}
+	for _, field := range fields {
+		result.Changes[field] = []*UserValueChange{}
+		for _, entry := range changes[field] {
+			result.Changes[field] = append(result.Changes[field], entry)
+		}
+		result.Unmappable[field] = []*UnmappedUserValue{}
+		for _, entry := range unmappable[field] {
+			result.Unmappable[field] = append(result.Unmappable[field], entry)
+		}
+	}
+	return result, nil
+}
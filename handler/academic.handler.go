@@ -2,18 +2,26 @@ package handler
 
 import (
 	"log"
+	"strings"
+	"time"
 
 	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
-// GetTopNoteSubjects handles fetching top 5 note subjects
+// Longest range a daily series can be fetched for
+const maxSubjectSeriesDays = 366
+
+// GetTopNoteSubjects handles fetching the most accessed note subjects. See topSubjects for the params.
 func GetTopNoteSubjects(db *gorm.DB) fiber.Handler {
 	log.Println("🟢 GetTopNoteSubjects handler called")
 	return func(c *fiber.Ctx) error {
-		var results []map[string]interface{}
-		if err := db.Raw("SELECT * FROM subnamedb ORDER BY count DESC LIMIT 5").Scan(&results).Error; err != nil {
+		results, badRequest, err := topSubjects(c, db, config.SubjectTypeNote)
+		if badRequest != "" {
+			return c.Status(400).JSON(fiber.Map{"status": badRequest})
+		}
+		if err != nil {
 			log.Printf("🔴 Error while retrieving top note subjects: %v", err)
 			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Academic.OperationUnsuccessful})
 		}
@@ -40,12 +48,15 @@ func GetLabSubjects(db *gorm.DB) fiber.Handler {
 	}
 }
 
-// GetTopLabSubjects handles fetching top 5 lab subjects
+// GetTopLabSubjects handles fetching the most accessed lab subjects. See topSubjects for the params.
 func GetTopLabSubjects(db *gorm.DB) fiber.Handler {
 	log.Println("🟢 GetTopLabSubjects handler called")
 	return func(c *fiber.Ctx) error {
-		var results []map[string]interface{}
-		if err := db.Raw("SELECT * FROM labsdb ORDER BY count DESC LIMIT 5").Scan(&results).Error; err != nil {
+		results, badRequest, err := topSubjects(c, db, config.SubjectTypeLab)
+		if badRequest != "" {
+			return c.Status(400).JSON(fiber.Map{"status": badRequest})
+		}
+		if err != nil {
 			log.Printf("🔴 Error while retrieving top lab subjects: %v", err)
 			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Academic.OperationUnsuccessful})
 		}
@@ -56,9 +67,73 @@ func GetTopLabSubjects(db *gorm.DB) fiber.Handler {
 	}
 }
 
-// IncrementSubjectCount is a generic handler for incrementing subject counts
-func IncrementSubjectCount(db *gorm.DB, subject string) fiber.Handler {
-	log.Println("🟢 IncrementSubjectCount handler called with subject: ", subject)
+// topSubjects returns the most accessed subjects of a type, limit (default 5) at most. Without dates
+// the lifetime counters are ranked; with startDate and/or endDate (YYYY-MM-DD) the daily counts in
+// that range are. A non-empty message means the params were invalid.
+func topSubjects(c *fiber.Ctx, db *gorm.DB, subjectType string) ([]map[string]interface{}, string, error) {
+	limit := c.QueryInt("limit", 5)
+	if limit < 1 || limit > 100 {
+		limit = 5
+	}
+	startDate := c.Query("startDate")
+	endDate := c.Query("endDate")
+	countTable := subjectCountTables[subjectType]
+
+	var query string
+	params := []interface{}{}
+	if startDate == "" && endDate == "" {
+		query = "SELECT * FROM " + countTable.table + " ORDER BY count DESC LIMIT ?"
+	} else {
+		whereClause := "type = ?"
+		params = append(params, subjectType)
+		for _, date := range []struct{ value, clause string }{
+			{startDate, " AND day >= ?"},
+			{endDate, " AND day <= ?"},
+		} {
+			if date.value == "" {
+				continue
+			}
+			if _, err := time.Parse(time.DateOnly, date.value); err != nil {
+				return nil, config.AppMessages.Academic.InvalidDateRange, nil
+			}
+			whereClause += date.clause
+			params = append(params, date.value)
+		}
+		query = "SELECT sub_name AS " + countTable.column + ", SUM(count) AS count FROM subject_daily_counts WHERE " +
+			whereClause + " GROUP BY sub_name ORDER BY count DESC LIMIT ?"
+	}
+	params = append(params, limit)
+
+	results := []map[string]interface{}{}
+	if err := db.Raw(query, params...).Scan(&results).Error; err != nil {
+		return nil, "", err
+	}
+	return results, "", nil
+}
+
+// registerPendingSubject adds an unknown code to the catalog as an inactive, pending subject so
+// admins can review it. Codes that could never be valid are ignored.
+func registerPendingSubject(db *gorm.DB, subjectType, code string) (bool, error) {
+	if !subjectCodeRegex.MatchString(code) {
+		return false, nil
+	}
+	result := db.Exec(`
+		INSERT IGNORE INTO subject_catalog (code, type, sub_name, display_name, active, pending)
+		VALUES (?, ?, ?, ?, 0, 1)`,
+		code, subjectType, code, strings.ToUpper(code))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return true, nil
+}
+
+// IncrementCatalogCount handles counting an access to the note or lab subject in the :code param.
+// The code must be an active entry of the subject catalog with a row in subnamedb or labsdb, both
+// cases answer 404 otherwise. With SUBJECT_AUTO_REGISTER set, unknown codes are added to the
+// catalog as pending subjects.
+func IncrementCatalogCount(db *gorm.DB, subjectType string) fiber.Handler {
+	log.Println("🟢 IncrementCatalogCount handler called with type: ", subjectType)
+	countTable := subjectCountTables[subjectType]
 	return func(c *fiber.Ctx) error {
 		if c.Query("adminKey") != config.GetAppConfig().ADMIN_AUTH_KEY {
 			return c.Status(401).JSON(fiber.Map{
@@ -66,10 +141,57 @@ func IncrementSubjectCount(db *gorm.DB, subject string) fiber.Handler {
 			})
 		}
 
-		if err := db.Exec("UPDATE subnamedb SET count = count + 1 WHERE sub_name = ?", subject).Error; err != nil {
-			log.Printf("🔴 Error while updating count for %s: %v", subject, err)
+		code := strings.ToLower(c.Params("code"))
+		subject, err := findSubject(db, "type = ? AND code = ?", subjectType, code)
+		if err != nil {
+			log.Printf("🔴 Error while looking up %s %s: %v", subjectType, code, err)
 			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Academic.OperationUnsuccessful})
 		}
+		if subject == nil {
+			pending := false
+			if config.GetSubjectConfig().AUTO_REGISTER {
+				if pending, err = registerPendingSubject(db, subjectType, code); err != nil {
+					log.Printf("🔴 Error while registering pending %s %s: %v", subjectType, code, err)
+				}
+			}
+			return c.Status(404).JSON(fiber.Map{
+				"status":  config.AppMessages.Academic.SubjectNotFound,
+				"pending": pending,
+			})
+		}
+		if !subject.Active {
+			return c.Status(404).JSON(fiber.Map{
+				"status":  config.AppMessages.Academic.SubjectNotFound,
+				"pending": subject.Pending,
+			})
+		}
+
+		var rowsAffected int64
+		err = db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Exec("UPDATE "+countTable.table+" SET count = count + 1 WHERE "+countTable.column+" = ?",
+				subject.SubName)
+			if result.Error != nil {
+				return result.Error
+			}
+			rowsAffected = result.RowsAffected
+			if rowsAffected == 0 {
+				return nil
+			}
+			return tx.Exec(`
+				INSERT INTO subject_daily_counts (type, sub_name, day, count) VALUES (?, ?, ?, 1)
+				ON DUPLICATE KEY UPDATE count = count + 1`,
+				subjectType, subject.SubName, time.Now().Format(time.DateOnly),
+			).Error
+		})
+		if err != nil {
+			log.Printf("🔴 Error while updating count for %s %s: %v", subjectType, subject.SubName, err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Academic.OperationUnsuccessful})
+		}
+		if rowsAffected == 0 {
+			log.Printf("⚠️ %s %s has no %s row named %q, see /catalog/subjects/report",
+				subjectType, code, countTable.table, subject.SubName)
+			return c.Status(404).JSON(fiber.Map{"status": config.AppMessages.Academic.CountRowMissing})
+		}
 
 		return c.Status(200).JSON(fiber.Map{
 			"status": config.AppMessages.Academic.OperationSuccessful,
@@ -77,85 +199,80 @@ func IncrementSubjectCount(db *gorm.DB, subject string) fiber.Handler {
 	}
 }
 
-// Subject-specific handlers using the generic IncrementSubjectCount
-func NotesMath1(db *gorm.DB) fiber.Handler  { return IncrementSubjectCount(db, "math1") }
-func NotesMath2(db *gorm.DB) fiber.Handler  { return IncrementSubjectCount(db, "math2") }
-func NotesPhy1(db *gorm.DB) fiber.Handler   { return IncrementSubjectCount(db, "phy1") }
-func NotesPhy2(db *gorm.DB) fiber.Handler   { return IncrementSubjectCount(db, "phy2") }
-func NotesChem1(db *gorm.DB) fiber.Handler  { return IncrementSubjectCount(db, "chem1") }
-func NotesChem2(db *gorm.DB) fiber.Handler  { return IncrementSubjectCount(db, "chem2") }
-func NotesPse(db *gorm.DB) fiber.Handler    { return IncrementSubjectCount(db, "pse") }
-func NotesCp(db *gorm.DB) fiber.Handler     { return IncrementSubjectCount(db, "cp") }
-func NotesNtf(db *gorm.DB) fiber.Handler    { return IncrementSubjectCount(db, "ntf") }
-func NotesEm(db *gorm.DB) fiber.Handler     { return IncrementSubjectCount(db, "em") }
-func NotesBce(db *gorm.DB) fiber.Handler    { return IncrementSubjectCount(db, "bce") }
-func NotesAm1(db *gorm.DB) fiber.Handler    { return IncrementSubjectCount(db, "am1") }
-func NotesAm2(db *gorm.DB) fiber.Handler    { return IncrementSubjectCount(db, "am2") }
-func NotesYm1(db *gorm.DB) fiber.Handler    { return IncrementSubjectCount(db, "ym1") }
-func NotesYm2(db *gorm.DB) fiber.Handler    { return IncrementSubjectCount(db, "ym2") }
-func NotesFm1(db *gorm.DB) fiber.Handler    { return IncrementSubjectCount(db, "fm1") }
-func NotesFm2(db *gorm.DB) fiber.Handler    { return IncrementSubjectCount(db, "fm2") }
-func NotesWp1(db *gorm.DB) fiber.Handler    { return IncrementSubjectCount(db, "wp1") }
-func NotesWp2(db *gorm.DB) fiber.Handler    { return IncrementSubjectCount(db, "wp2") }
-func NotesStat(db *gorm.DB) fiber.Handler   { return IncrementSubjectCount(db, "stat") }
-func NotesFeee(db *gorm.DB) fiber.Handler   { return IncrementSubjectCount(db, "feee") }
-func NotesMarket(db *gorm.DB) fiber.Handler { return IncrementSubjectCount(db, "marketing") }
-func NotesTtqc(db *gorm.DB) fiber.Handler   { return IncrementSubjectCount(db, "ttqc") }
-func NotesTp(db *gorm.DB) fiber.Handler     { return IncrementSubjectCount(db, "tp") }
-func NotesMp(db *gorm.DB) fiber.Handler     { return IncrementSubjectCount(db, "mp") }
-func NotesMmtf(db *gorm.DB) fiber.Handler   { return IncrementSubjectCount(db, "mmtf") }
-func NotesAcm(db *gorm.DB) fiber.Handler    { return IncrementSubjectCount(db, "acm") }
-func NotesTqm(db *gorm.DB) fiber.Handler    { return IncrementSubjectCount(db, "tqm") }
-func NotesFsd(db *gorm.DB) fiber.Handler    { return IncrementSubjectCount(db, "fsd") }
-func NotesAce(db *gorm.DB) fiber.Handler    { return IncrementSubjectCount(db, "ace") }
-func NotesMic(db *gorm.DB) fiber.Handler    { return IncrementSubjectCount(db, "mic") }
-func NotesSss1(db *gorm.DB) fiber.Handler   { return IncrementSubjectCount(db, "sss1") }
-func NotesSss2(db *gorm.DB) fiber.Handler   { return IncrementSubjectCount(db, "sss2") }
-func NotesWpp(db *gorm.DB) fiber.Handler    { return IncrementSubjectCount(db, "wpp") }
-func NotesEcono(db *gorm.DB) fiber.Handler  { return IncrementSubjectCount(db, "econo") }
-
-// IncrementLabCount is a generic handler for incrementing lab counts
-func IncrementLabCount(db *gorm.DB, subject string) fiber.Handler {
-	log.Println("🟢 IncrementLabCount handler called with subject: ", subject)
+// GetSubjectDailySeries handles fetching the per day access counts of the note or lab in the :code
+// param. startDate and endDate (YYYY-MM-DD) default to the last 30 days; days without access are 0.
+func GetSubjectDailySeries(db *gorm.DB, subjectType string) fiber.Handler {
+	log.Println("🟢 GetSubjectDailySeries handler called with type: ", subjectType)
 	return func(c *fiber.Ctx) error {
-		if c.Query("adminKey") != config.GetAppConfig().ADMIN_AUTH_KEY {
-			return c.Status(401).JSON(fiber.Map{
-				"error": config.AppMessages.Academic.UnauthorizedAccess,
-			})
+		end := time.Now()
+		start := end.AddDate(0, 0, -29)
+		for _, date := range []struct {
+			param string
+			value *time.Time
+		}{
+			{"startDate", &start},
+			{"endDate", &end},
+		} {
+			if value := c.Query(date.param); value != "" {
+				parsed, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+				if err != nil {
+					return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Academic.InvalidDateRange})
+				}
+				*date.value = parsed
+			}
+		}
+		startDate, endDate := start.Format(time.DateOnly), end.Format(time.DateOnly)
+		if startDate > endDate || end.Sub(start) > maxSubjectSeriesDays*24*time.Hour {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Academic.InvalidDateRange})
 		}
 
-		if err := db.Exec("UPDATE labsdb SET count = count + 1 WHERE lab_name = ?", subject).Error; err != nil {
-			log.Printf("🔴 Error while updating count for lab %s: %v", subject, err)
+		code := strings.ToLower(c.Params("code"))
+		subject, err := findSubject(db, "type = ? AND code = ?", subjectType, code)
+		if err != nil {
+			log.Printf("🔴 Error while looking up %s %s: %v", subjectType, code, err)
 			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Academic.OperationUnsuccessful})
 		}
+		if subject == nil {
+			return c.Status(404).JSON(fiber.Map{"status": config.AppMessages.Academic.SubjectNotFound})
+		}
+
+		var rows []struct {
+			Day   string
+			Count int64
+		}
+		if err := db.Raw(`
+			SELECT DATE_FORMAT(day, '%Y-%m-%d') AS day, count
+			FROM subject_daily_counts
+			WHERE type = ? AND sub_name = ? AND day BETWEEN ? AND ?`,
+			subjectType, subject.SubName, startDate, endDate,
+		).Scan(&rows).Error; err != nil {
+			log.Printf("🔴 Error while retrieving daily counts for %s %s: %v", subjectType, code, err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Academic.OperationUnsuccessful})
+		}
+
+		counts := map[string]int64{}
+		for _, row := range rows {
+			counts[row.Day] = row.Count
+		}
+
+		type dayCount struct {
+			Day   string `json:"day"`
+			Count int64  `json:"count"`
+		}
+		series := []dayCount{}
+		var total int64
+		for day := start; day.Format(time.DateOnly) <= endDate; day = day.AddDate(0, 0, 1) {
+			key := day.Format(time.DateOnly)
+			series = append(series, dayCount{Day: key, Count: counts[key]})
+			total += counts[key]
+		}
 
 		return c.Status(200).JSON(fiber.Map{
-			"status": config.AppMessages.Academic.OperationSuccessful,
+			"subject":   subject,
+			"startDate": startDate,
+			"endDate":   endDate,
+			"total":     total,
+			"series":    series,
 		})
 	}
 }
-
-// Lab-specific handlers using the generic IncrementLabCount
-func LabsPhy1(db *gorm.DB) fiber.Handler  { return IncrementLabCount(db, "phy1") }
-func LabsPhy2(db *gorm.DB) fiber.Handler  { return IncrementLabCount(db, "phy2") }
-func LabsChem1(db *gorm.DB) fiber.Handler { return IncrementLabCount(db, "chem1") }
-func LabsChem2(db *gorm.DB) fiber.Handler { return IncrementLabCount(db, "chem2") }
-func LabsCP(db *gorm.DB) fiber.Handler    { return IncrementLabCount(db, "cp") }
-func LabsBce(db *gorm.DB) fiber.Handler   { return IncrementLabCount(db, "bce") }
-func LabsMsp(db *gorm.DB) fiber.Handler   { return IncrementLabCount(db, "msp") }
-func LabsAm1(db *gorm.DB) fiber.Handler   { return IncrementLabCount(db, "am1") }
-func LabsAm2(db *gorm.DB) fiber.Handler   { return IncrementLabCount(db, "am2") }
-func LabsYm1(db *gorm.DB) fiber.Handler   { return IncrementLabCount(db, "ym1") }
-func LabsYm2(db *gorm.DB) fiber.Handler   { return IncrementLabCount(db, "ym2") }
-func LabsWp1(db *gorm.DB) fiber.Handler   { return IncrementLabCount(db, "wp1") }
-func LabsWp2(db *gorm.DB) fiber.Handler   { return IncrementLabCount(db, "wp2") }
-func LabsFm1(db *gorm.DB) fiber.Handler   { return IncrementLabCount(db, "fm1") }
-func LabsFm2(db *gorm.DB) fiber.Handler   { return IncrementLabCount(db, "fm2") }
-func LabsFeee(db *gorm.DB) fiber.Handler  { return IncrementLabCount(db, "feee") }
-func LabsFme(db *gorm.DB) fiber.Handler   { return IncrementLabCount(db, "fme") }
-func LabsTtqc(db *gorm.DB) fiber.Handler  { return IncrementLabCount(db, "ttqc") }
-func LabsAp1(db *gorm.DB) fiber.Handler   { return IncrementLabCount(db, "ap1") }
-func LabsAp2(db *gorm.DB) fiber.Handler   { return IncrementLabCount(db, "ap2") }
-func LabsMp(db *gorm.DB) fiber.Handler    { return IncrementLabCount(db, "mp") }
-func LabsFsd(db *gorm.DB) fiber.Handler   { return IncrementLabCount(db, "fsd") }
-func LabsLss(db *gorm.DB) fiber.Handler   { return IncrementLabCount(db, "lss") }
@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestIncrementCatalogCount(t *testing.T) {
+	useTestAdminKey(t)
+
+	tests := []struct {
+		name         string
+		target       string
+		subject      []driver.Value
+		autoRegister string
+		counted      int64
+		status       int
+		pending      interface{}
+		registered   []driver.Value
+	}{
+		{
+			name:    "active subject",
+			target:  "/notes/PHY1",
+			subject: []driver.Value{int64(1), "phy1", "note", "physics", "PHY1", nil, nil, true, false, time.Now(), time.Now()},
+			counted: 1,
+			status:  fiber.StatusOK,
+		},
+		{
+			name:    "active subject without a count row",
+			target:  "/notes/phy1",
+			subject: []driver.Value{int64(1), "phy1", "note", "physics", "PHY1", nil, nil, true, false, time.Now(), time.Now()},
+			status:  fiber.StatusNotFound,
+		},
+		{
+			name:    "pending subject",
+			target:  "/notes/newsub",
+			subject: []driver.Value{int64(2), "newsub", "note", "newsub", "NEWSUB", nil, nil, false, true, time.Now(), time.Now()},
+			status:  fiber.StatusNotFound,
+			pending: true,
+		},
+		{
+			name:         "unknown code",
+			target:       "/notes/newsub",
+			autoRegister: "false",
+			status:       fiber.StatusNotFound,
+			pending:      false,
+		},
+		{
+			name:         "unknown code is registered",
+			target:       "/notes/NewSub",
+			autoRegister: "true",
+			status:       fiber.StatusNotFound,
+			pending:      true,
+			registered:   []driver.Value{"newsub", "note", "newsub", "NEWSUB"},
+		},
+		{
+			name:         "invalid code is not registered",
+			target:       "/notes/new%20sub",
+			autoRegister: "true",
+			status:       fiber.StatusNotFound,
+			pending:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SUBJECT_AUTO_REGISTER", tt.autoRegister)
+			db, rec := newRecordingDB(t)
+			if tt.subject != nil {
+				rec.respond = subjectRows(tt.subject)
+			}
+			rec.affected = func(query string) int64 {
+				if strings.Contains(query, "UPDATE subnamedb") {
+					return tt.counted
+				}
+				return 1
+			}
+			app := newTestApp()
+			app.Get("/notes/:code", IncrementCatalogCount(db, "note"))
+
+			status, body := doRequest(t, app, fiber.MethodGet, tt.target+"?adminKey="+testAdminKey, "")
+			if status != tt.status {
+				t.Fatalf("status = %d, want %d (body %s)", status, tt.status, body)
+			}
+			var response map[string]interface{}
+			if err := json.Unmarshal([]byte(body), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response["pending"] != tt.pending {
+				t.Errorf("pending = %v, want %v", response["pending"], tt.pending)
+			}
+
+			registered := rec.Find("INSERT IGNORE INTO subject_catalog")
+			if tt.registered == nil && len(registered) != 0 || tt.registered != nil &&
+				(len(registered) != 1 || !reflect.DeepEqual(registered[0], tt.registered)) {
+				t.Errorf("registered = %v, want %v", registered, tt.registered)
+			}
+
+			updates := rec.Find("UPDATE subnamedb SET count = count + 1")
+			if tt.subject == nil || !tt.subject[7].(bool) {
+				if len(updates) != 0 {
+					t.Errorf("counted %v for a subject that is not active", updates)
+				}
+				return
+			}
+			if len(updates) != 1 || updates[0][0] != "physics" {
+				t.Errorf("updates = %v, want the physics row", updates)
+			}
+		})
+	}
+}
+
+func TestIncrementCatalogCountCountsPerDay(t *testing.T) {
+	useTestAdminKey(t)
+
+	tests := []struct {
+		name    string
+		counted int64
+		daily   []driver.Value
+	}{
+		{"counted", 1, []driver.Value{"note", "physics", time.Now().Format(time.DateOnly)}},
+		{"no count row", 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := newRecordingDB(t)
+			rec.respond = subjectRows([]driver.Value{int64(1), "phy1", "note", "physics", "PHY1", nil, nil, true, false, time.Now(), time.Now()})
+			rec.affected = func(string) int64 { return tt.counted }
+			app := newTestApp()
+			app.Get("/notes/:code", IncrementCatalogCount(db, "note"))
+
+			doRequest(t, app, fiber.MethodGet, "/notes/phy1?adminKey="+testAdminKey, "")
+			daily := rec.Find("INSERT INTO subject_daily_counts")
+			if tt.daily == nil && len(daily) != 0 || tt.daily != nil && (len(daily) != 1 || !reflect.DeepEqual(daily[0], tt.daily)) {
+				t.Errorf("daily counts = %v, want %v", daily, tt.daily)
+			}
+		})
+	}
+}
+
+func TestTopSubjects(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		status int
+		query  string
+		args   []driver.Value
+	}{
+		{"lifetime counts", "/top", fiber.StatusOK, "SELECT * FROM labsdb ORDER BY count DESC LIMIT ?", []driver.Value{int64(5)}},
+		{"limit out of range", "/top?limit=500", fiber.StatusOK, "FROM labsdb", []driver.Value{int64(5)}},
+		{"date range", "/top?startDate=2026-03-01&endDate=2026-03-31&limit=10", fiber.StatusOK,
+			"WHERE type = ? AND day >= ? AND day <= ? GROUP BY sub_name", []driver.Value{"lab", "2026-03-01", "2026-03-31", int64(10)}},
+		{"start date only", "/top?startDate=2026-03-01", fiber.StatusOK,
+			"WHERE type = ? AND day >= ? GROUP BY sub_name", []driver.Value{"lab", "2026-03-01", int64(5)}},
+		{"bad date", "/top?endDate=31-03-2026", fiber.StatusBadRequest, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := newRecordingDB(t)
+			app := newTestApp()
+			app.Get("/top", GetTopLabSubjects(db))
+
+			status, body := doRequest(t, app, fiber.MethodGet, tt.target, "")
+			if status != tt.status {
+				t.Fatalf("status = %d, want %d (body %s)", status, tt.status, body)
+			}
+			if tt.args == nil {
+				if statements := rec.Statements(); len(statements) != 0 {
+					t.Errorf("ran %v", statements)
+				}
+				return
+			}
+			queries := rec.Find(tt.query)
+			if len(queries) != 1 || !reflect.DeepEqual(queries[0], tt.args) {
+				t.Errorf("statements = %v, want %q with %v", rec.Statements(), tt.query, tt.args)
+			}
+			if !strings.Contains(body, `"topLabSubjects":[]`) {
+				t.Errorf("body = %s, want an empty list", body)
+			}
+		})
+	}
+}
+
+func TestGetSubjectDailySeries(t *testing.T) {
+	db, rec := newRecordingDB(t)
+	rec.respond = func(query string) ([]string, [][]driver.Value) {
+		if strings.Contains(query, "FROM subject_daily_counts") {
+			return []string{"day", "count"}, [][]driver.Value{{"2026-02-27", int64(4)}, {"2026-03-01", int64(2)}}
+		}
+		return subjectRows([]driver.Value{int64(1), "phy1", "note", "physics", "PHY1", nil, nil, true, false, time.Now(), time.Now()})(query)
+	}
+	app := newTestApp()
+	app.Get("/notes/:code/daily", GetSubjectDailySeries(db, "note"))
+
+	status, body := doRequest(t, app, fiber.MethodGet, "/notes/PHY1/daily?startDate=2026-02-27&endDate=2026-03-02", "")
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d (body %s)", status, body)
+	}
+	var response struct {
+		Total  int64 `json:"total"`
+		Series []struct {
+			Day   string `json:"day"`
+			Count int64  `json:"count"`
+		} `json:"series"`
+	}
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatal(err)
+	}
+
+	// Days without a row are filled with 0
+	var series []string
+	for _, day := range response.Series {
+		series = append(series, day.Day+"="+strconv.FormatInt(day.Count, 10))
+	}
+	want := []string{"2026-02-27=4", "2026-02-28=0", "2026-03-01=2", "2026-03-02=0"}
+	if response.Total != 6 || !reflect.DeepEqual(series, want) {
+		t.Errorf("total %d series %v, want 6 and %v", response.Total, series, want)
+	}
+	if queries := rec.Find("FROM subject_daily_counts"); len(queries) != 1 ||
+		!reflect.DeepEqual(queries[0], []driver.Value{"note", "physics", "2026-02-27", "2026-03-02"}) {
+		t.Errorf("daily count queries = %v", queries)
+	}
+}
+
+func TestGetSubjectDailySeriesRejectsBadRequests(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		status int
+	}{
+		{"bad date", "/notes/phy1/daily?startDate=yesterday", fiber.StatusBadRequest},
+		{"start after end", "/notes/phy1/daily?startDate=2026-03-02&endDate=2026-03-01", fiber.StatusBadRequest},
+		{"range too long", "/notes/phy1/daily?startDate=2025-01-01&endDate=2026-03-01", fiber.StatusBadRequest},
+		{"unknown subject", "/notes/nope/daily", fiber.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := newRecordingDB(t)
+			app := newTestApp()
+			app.Get("/notes/:code/daily", GetSubjectDailySeries(db, "note"))
+
+			if status, body := doRequest(t, app, fiber.MethodGet, tt.target, ""); status != tt.status {
+				t.Errorf("status = %d, want %d (body %s)", status, tt.status, body)
+			}
+			if counts := rec.Find("FROM subject_daily_counts"); len(counts) != 0 {
+				t.Errorf("queried daily counts %v", counts)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/utils"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type rankedSubject struct {
+	Rank        int      `json:"rank"`
+	ID          int64    `json:"id"`
+	Code        string   `json:"code"`
+	Type        string   `json:"type"`
+	DisplayName string   `json:"display_name"`
+	Dept        *string  `json:"dept"`
+	Semester    *int     `json:"semester"`
+	Count       int64    `json:"count"`
+	Enrolled    *int64   `json:"enrolled,omitempty"`
+	Score       *float64 `json:"score,omitempty"`
+}
+
+// GetSubjectRanking handles ranking notes and labs together by access count. Query params: type
+// (note or lab, both when left out), startDate and endDate (YYYY-MM-DD, lifetime counts when left
+// out), page and limit. With normalize=dept each subject is scored per 100 students enrolled in its
+// department, counted from app_users, so big departments don't dominate; batch limits the enrolled
+// count to one batch. Subjects without a department or students are ranked after the scored ones.
+func GetSubjectRanking(db *gorm.DB) fiber.Handler {
+	log.Println("🟢 GET: GetSubjectRanking handler called")
+	return func(c *fiber.Ctx) error {
+		page := c.QueryInt("page", 1)
+		limit := c.QueryInt("limit", 20)
+		if page < 1 {
+			page = 1
+		}
+		if limit < 1 || limit > 100 {
+			limit = 20
+		}
+
+		startDate := c.Query("startDate")
+		endDate := c.Query("endDate")
+		for _, date := range []string{startDate, endDate} {
+			if date == "" {
+				continue
+			}
+			if _, err := time.Parse(time.DateOnly, date); err != nil {
+				return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Academic.InvalidDateRange})
+			}
+		}
+
+		normalize := c.Query("normalize")
+		if normalize != "" && normalize != "dept" {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Academic.InvalidNormalize})
+		}
+		batch := ""
+		if value := c.Query("batch"); value != "" {
+			normalized, ok := utils.NormalizeBatch(value)
+			if !ok {
+				return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Validation.BatchOutOfRange})
+			}
+			batch = normalized
+		}
+
+		whereClause := "active = 1 AND pending = 0"
+		params := []interface{}{}
+		if subjectType := c.Query("type"); subjectType != "" {
+			if _, ok := subjectCountTables[subjectType]; !ok {
+				return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Academic.InvalidSubjectType})
+			}
+			whereClause += " AND type = ?"
+			params = append(params, subjectType)
+		}
+
+		var subjects []Subject
+		if err := db.Raw("SELECT * FROM subject_catalog WHERE "+whereClause, params...).Scan(&subjects).Error; err != nil {
+			log.Printf("🔴 Error while fetching subject catalog: %v", err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Academic.OperationUnsuccessful})
+		}
+
+		counts, err := subjectAccessCounts(db, startDate, endDate)
+		if err != nil {
+			log.Printf("🔴 Error while fetching subject access counts: %v", err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Academic.OperationUnsuccessful})
+		}
+
+		// Users are stored with catalog dept, role and batch values, see db.NormalizeUserValues
+		enrolled := map[string]int64{}
+		if normalize == "dept" {
+			query := "SELECT dept, COUNT(*) AS count FROM app_users WHERE deleted_at IS NULL AND role = ?"
+			userParams := []interface{}{"student"}
+			if batch != "" {
+				query += " AND batch = ?"
+				userParams = append(userParams, batch)
+			}
+			var rows []struct {
+				Dept  string
+				Count int64
+			}
+			if err := db.Raw(query+" GROUP BY dept", userParams...).Scan(&rows).Error; err != nil {
+				log.Printf("🔴 Error while counting enrolled students: %v", err)
+				return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Academic.OperationUnsuccessful})
+			}
+			for _, row := range rows {
+				enrolled[row.Dept] = row.Count
+			}
+		}
+
+		ranking := make([]rankedSubject, 0, len(subjects))
+		for _, subject := range subjects {
+			ranked := rankedSubject{
+				ID:          subject.ID,
+				Code:        subject.Code,
+				Type:        subject.Type,
+				DisplayName: subject.DisplayName,
+				Dept:        subject.Dept,
+				Semester:    subject.Semester,
+				Count:       counts[subjectAccessKey(subject.Type, subject.SubName)],
+			}
+			if normalize == "dept" && subject.Dept != nil {
+				students := enrolled[*subject.Dept]
+				ranked.Enrolled = &students
+				if students > 0 {
+					score := float64(ranked.Count) * 100 / float64(students)
+					ranked.Score = &score
+				}
+			}
+			ranking = append(ranking, ranked)
+		}
+
+		sort.SliceStable(ranking, func(i, j int) bool {
+			a, b := ranking[i], ranking[j]
+			if normalize == "dept" && (a.Score == nil) != (b.Score == nil) {
+				return a.Score != nil
+			}
+			if a.Score != nil && b.Score != nil && *a.Score != *b.Score {
+				return *a.Score > *b.Score
+			}
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+			if a.Type != b.Type {
+				return a.Type < b.Type
+			}
+			return a.Code < b.Code
+		})
+		for i := range ranking {
+			ranking[i].Rank = i + 1
+		}
+
+		total := len(ranking)
+		from := min((page-1)*limit, total)
+		to := min(from+limit, total)
+
+		return c.Status(200).JSON(fiber.Map{
+			"ranking":   ranking[from:to],
+			"normalize": normalize,
+			"batch":     batch,
+			"startDate": startDate,
+			"endDate":   endDate,
+			"pagination": fiber.Map{
+				"current_page": page,
+				"limit":        limit,
+				"total":        total,
+				"total_pages":  (total + limit - 1) / limit,
+			},
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// academicRows serves catalog subjects as {code, type, dept}, lifetime counts per sub_name and
+// enrolled students per dept. Subjects use their code as sub_name.
+func academicRows(subjects [][3]string, counts map[string]int64, students map[string]int64) func(string) ([]string, [][]driver.Value) {
+	return func(query string) ([]string, [][]driver.Value) {
+		switch {
+		case strings.Contains(query, "FROM subject_catalog"):
+			rows := [][]driver.Value{}
+			for i, subject := range subjects {
+				var dept driver.Value
+				if subject[2] != "" {
+					dept = subject[2]
+				}
+				rows = append(rows, []driver.Value{int64(i + 1), subject[0], subject[1], subject[0],
+					strings.ToUpper(subject[0]), dept, nil, true, false, time.Now(), time.Now()})
+			}
+			return []string{"id", "code", "type", "sub_name", "display_name", "dept", "semester", "active", "pending", "created_at", "updated_at"}, rows
+		case strings.Contains(query, "FROM subnamedb"), strings.Contains(query, "FROM labsdb"):
+			rows := [][]driver.Value{}
+			for name, count := range counts {
+				rows = append(rows, []driver.Value{name, count})
+			}
+			return []string{"name", "count"}, rows
+		case strings.Contains(query, "FROM app_users"):
+			rows := [][]driver.Value{}
+			for dept, count := range students {
+				rows = append(rows, []driver.Value{dept, count})
+			}
+			return []string{"dept", "count"}, rows
+		}
+		return nil, nil
+	}
+}
+
+func TestGetSubjectRanking(t *testing.T) {
+	subjects := [][3]string{
+		{"ttqc", "note", "TE"},
+		{"ym1", "note", "YE"},
+		{"math1", "note", ""},
+		{"fsd", "note", "FDAE"},
+	}
+	counts := map[string]int64{"ttqc": 30, "ym1": 80, "math1": 500, "fsd": 5}
+	students := map[string]int64{"TE": 15, "YE": 80}
+
+	tests := []struct {
+		name     string
+		query    string
+		order    []string
+		scores   []float64
+		students [][]driver.Value
+	}{
+		{
+			name:  "by access count",
+			query: "",
+			order: []string{"math1", "ym1", "ttqc", "fsd"},
+		},
+		{
+			name:     "per 100 students of the department",
+			query:    "normalize=dept",
+			order:    []string{"ttqc", "ym1", "math1", "fsd"},
+			scores:   []float64{200, 100},
+			students: [][]driver.Value{{"student"}},
+		},
+		{
+			name:     "students of one batch",
+			query:    "normalize=dept&batch=047",
+			order:    []string{"ttqc", "ym1", "math1", "fsd"},
+			scores:   []float64{200, 100},
+			students: [][]driver.Value{{"student", "47"}},
+		},
+		{
+			name:  "paginated",
+			query: "limit=2&page=2",
+			order: []string{"ttqc", "fsd"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := newRecordingDB(t)
+			rec.respond = academicRows(subjects, counts, students)
+			app := newTestApp()
+			app.Get("/academic/ranking", GetSubjectRanking(db))
+
+			status, body := doRequest(t, app, fiber.MethodGet, "/academic/ranking?"+tt.query, "")
+			if status != fiber.StatusOK {
+				t.Fatalf("status = %d (body %s)", status, body)
+			}
+
+			var response struct {
+				Ranking []rankedSubject `json:"ranking"`
+			}
+			if err := json.Unmarshal([]byte(body), &response); err != nil {
+				t.Fatal(err)
+			}
+			order := []string{}
+			scores := []float64{}
+			for _, ranked := range response.Ranking {
+				order = append(order, ranked.Code)
+				if ranked.Score != nil {
+					scores = append(scores, *ranked.Score)
+				}
+			}
+			if !reflect.DeepEqual(order, tt.order) {
+				t.Errorf("order = %v, want %v", order, tt.order)
+			}
+			if len(tt.scores) == 0 {
+				tt.scores = []float64{}
+			}
+			if !reflect.DeepEqual(scores, tt.scores) {
+				t.Errorf("scores = %v, want %v", scores, tt.scores)
+			}
+			if found := rec.Find("FROM app_users"); !reflect.DeepEqual(found, tt.students) {
+				t.Errorf("enrolled student queries = %v, want %v", found, tt.students)
+			}
+		})
+	}
+}
+
+func TestGetSubjectRankingRejectsBadParams(t *testing.T) {
+	for _, query := range []string{"normalize=batch", "batch=abc", "batch=100", "type=course", "startDate=2026-13-01"} {
+		db, rec := newRecordingDB(t)
+		app := newTestApp()
+		app.Get("/academic/ranking", GetSubjectRanking(db))
+
+		if status, body := doRequest(t, app, fiber.MethodGet, "/academic/ranking?"+query, ""); status != fiber.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400 (body %s)", query, status, body)
+		}
+		if statements := rec.Statements(); len(statements) != 0 {
+			t.Errorf("%s: ran %v", query, statements)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/utils"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type subjectAccess struct {
+	ID          int64   `json:"id"`
+	Code        string  `json:"code"`
+	Type        string  `json:"type"`
+	DisplayName string  `json:"display_name"`
+	Dept        string  `json:"dept"`
+	Semester    string  `json:"semester"`
+	Count       int64   `json:"count"`
+	DeptShare   float64 `json:"dept_share"`
+}
+
+type academicGroup struct {
+	Dept      string `json:"dept,omitempty"`
+	Semester  string `json:"semester,omitempty"`
+	NoteTotal int64  `json:"note_total"`
+	LabTotal  int64  `json:"lab_total"`
+	Total     int64  `json:"total"`
+	Subjects  int    `json:"subjects"`
+}
+
+func (g *academicGroup) add(subject subjectAccess) {
+	if subject.Type == config.SubjectTypeLab {
+		g.LabTotal += subject.Count
+	} else {
+		g.NoteTotal += subject.Count
+	}
+	g.Total += subject.Count
+	g.Subjects++
+}
+
+// GetAcademicStats handles note and lab access totals per department, semester and both, with each
+// subject's share of its department and the subjects nobody accessed. The period is startDate to
+// endDate (YYYY-MM-DD), the last 30 days by default. Filters: type (note or lab) and dept.
+// Subjects without a department or semester are grouped under "unknown".
+func GetAcademicStats(db *gorm.DB) fiber.Handler {
+	log.Println("🟢 GET: GetAcademicStats handler called")
+	return func(c *fiber.Ctx) error {
+		startDate := c.Query("startDate", time.Now().AddDate(0, 0, -29).Format(time.DateOnly))
+		endDate := c.Query("endDate", time.Now().Format(time.DateOnly))
+		for _, date := range []string{startDate, endDate} {
+			if _, err := time.Parse(time.DateOnly, date); err != nil {
+				return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Academic.InvalidDateRange})
+			}
+		}
+		if startDate > endDate {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Academic.InvalidDateRange})
+		}
+
+		whereClause := "active = 1 AND pending = 0"
+		params := []interface{}{}
+		if subjectType := c.Query("type"); subjectType != "" {
+			if _, ok := subjectCountTables[subjectType]; !ok {
+				return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Academic.InvalidSubjectType})
+			}
+			whereClause += " AND type = ?"
+			params = append(params, subjectType)
+		}
+		if dept := c.Query("dept"); dept != "" {
+			code, ok := utils.NormalizeDept(dept)
+			if !ok {
+				return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Validation.InvalidDept})
+			}
+			whereClause += " AND dept = ?"
+			params = append(params, code)
+		}
+
+		var subjects []Subject
+		if err := db.Raw("SELECT * FROM subject_catalog WHERE "+whereClause+" ORDER BY type, code", params...).
+			Scan(&subjects).Error; err != nil {
+			log.Printf("🔴 Error while fetching subject catalog: %v", err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Academic.OperationUnsuccessful})
+		}
+
+		counts, err := subjectAccessCounts(db, startDate, endDate)
+		if err != nil {
+			log.Printf("🔴 Error while fetching subject access counts: %v", err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Academic.OperationUnsuccessful})
+		}
+
+		accesses := make([]subjectAccess, 0, len(subjects))
+		byDept := map[string]*academicGroup{}
+		bySemester := map[string]*academicGroup{}
+		byDeptSemester := map[string]*academicGroup{}
+		for _, subject := range subjects {
+			access := subjectAccess{
+				ID:          subject.ID,
+				Code:        subject.Code,
+				Type:        subject.Type,
+				DisplayName: subject.DisplayName,
+				Dept:        "unknown",
+				Semester:    "unknown",
+				Count:       counts[subjectAccessKey(subject.Type, subject.SubName)],
+			}
+			if subject.Dept != nil && *subject.Dept != "" {
+				access.Dept = *subject.Dept
+			}
+			if subject.Semester != nil {
+				access.Semester = strconv.Itoa(*subject.Semester)
+			}
+			accesses = append(accesses, access)
+
+			if byDept[access.Dept] == nil {
+				byDept[access.Dept] = &academicGroup{Dept: access.Dept}
+			}
+			byDept[access.Dept].add(access)
+			if bySemester[access.Semester] == nil {
+				bySemester[access.Semester] = &academicGroup{Semester: access.Semester}
+			}
+			bySemester[access.Semester].add(access)
+			key := access.Dept + "\x00" + access.Semester
+			if byDeptSemester[key] == nil {
+				byDeptSemester[key] = &academicGroup{Dept: access.Dept, Semester: access.Semester}
+			}
+			byDeptSemester[key].add(access)
+		}
+
+		zeroAccess := []subjectAccess{}
+		for i := range accesses {
+			if deptTotal := byDept[accesses[i].Dept].Total; deptTotal > 0 {
+				accesses[i].DeptShare = float64(accesses[i].Count) / float64(deptTotal)
+			}
+			if accesses[i].Count == 0 {
+				zeroAccess = append(zeroAccess, accesses[i])
+			}
+		}
+		sort.SliceStable(accesses, func(i, j int) bool {
+			if accesses[i].Dept != accesses[j].Dept {
+				return accesses[i].Dept < accesses[j].Dept
+			}
+			return accesses[i].Count > accesses[j].Count
+		})
+
+		return c.Status(200).JSON(fiber.Map{
+			"startDate":        startDate,
+			"endDate":          endDate,
+			"by_dept":          sortedAcademicGroups(byDept),
+			"by_semester":      sortedAcademicGroups(bySemester),
+			"by_dept_semester": sortedAcademicGroups(byDeptSemester),
+			"subjects":         accesses,
+			"zero_access":      zeroAccess,
+		})
+	}
+}
+
+func subjectAccessKey(subjectType, subName string) string {
+	return subjectType + "\x00" + strings.ToLower(subName)
+}
+
+// subjectAccessCounts returns the access count of every note and lab, keyed by subjectAccessKey.
+// With no dates the lifetime counters in subnamedb and labsdb are used, otherwise the daily counts
+// from startDate to endDate (either may be empty for an open range).
+func subjectAccessCounts(db *gorm.DB, startDate, endDate string) (map[string]int64, error) {
+	counts := map[string]int64{}
+
+	if startDate == "" && endDate == "" {
+		for subjectType, countTable := range subjectCountTables {
+			var rows []struct {
+				Name  string
+				Count int64
+			}
+			if err := db.Raw("SELECT " + countTable.column + " AS name, count FROM " + countTable.table).
+				Scan(&rows).Error; err != nil {
+				return nil, err
+			}
+			for _, row := range rows {
+				counts[subjectAccessKey(subjectType, row.Name)] += row.Count
+			}
+		}
+		return counts, nil
+	}
+
+	whereClause := "1=1"
+	params := []interface{}{}
+	if startDate != "" {
+		whereClause += " AND day >= ?"
+		params = append(params, startDate)
+	}
+	if endDate != "" {
+		whereClause += " AND day <= ?"
+		params = append(params, endDate)
+	}
+
+	var rows []struct {
+		Type    string
+		SubName string
+		Count   int64
+	}
+	if err := db.Raw("SELECT type, sub_name, SUM(count) AS count FROM subject_daily_counts WHERE "+
+		whereClause+" GROUP BY type, sub_name", params...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[subjectAccessKey(row.Type, row.SubName)] += row.Count
+	}
+	return counts, nil
+}
+
+// sortedAcademicGroups lists groups by key, so semesters and departments come back in a stable order
+func sortedAcademicGroups(groups map[string]*academicGroup) []*academicGroup {
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	sorted := make([]*academicGroup, 0, len(keys))
+	for _, key := range keys {
+		sorted = append(sorted, groups[key])
+	}
+	return sorted
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// academicStatsRows serves a catalog of three TE notes and an undepartmented lab, biology unaccessed
+func academicStatsRows(query string) ([]string, [][]driver.Value) {
+	switch {
+	case strings.Contains(query, "FROM subject_catalog"):
+		return []string{"id", "code", "type", "sub_name", "display_name", "dept", "semester", "active", "pending"}, [][]driver.Value{
+			{int64(1), "bio1", "note", "biology", "BIO1", "TE", int64(1), true, false},
+			{int64(2), "chem1", "note", "chemistry", "CHEM1", "TE", int64(2), true, false},
+			{int64(3), "phy1", "note", "physics", "PHY1", "TE", int64(1), true, false},
+			{int64(4), "phylab", "lab", "physics lab", "PHYLAB", nil, nil, true, false},
+		}
+	case strings.Contains(query, "FROM subject_daily_counts"):
+		return []string{"type", "sub_name", "count"}, [][]driver.Value{
+			{"note", "physics", int64(6)}, {"note", "chemistry", int64(2)}, {"lab", "physics lab", int64(4)},
+		}
+	}
+	return nil, nil
+}
+
+func TestGetAcademicStats(t *testing.T) {
+	db, rec := newRecordingDB(t)
+	rec.respond = academicStatsRows
+	app := newTestApp()
+	app.Get("/stats/academic", GetAcademicStats(db))
+
+	status, body := doRequest(t, app, fiber.MethodGet, "/stats/academic?startDate=2026-03-01&endDate=2026-03-31", "")
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d (body %s)", status, body)
+	}
+	var response struct {
+		ByDept     []academicGroup `json:"by_dept"`
+		BySemester []academicGroup `json:"by_semester"`
+		Subjects   []subjectAccess `json:"subjects"`
+		ZeroAccess []subjectAccess `json:"zero_access"`
+	}
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatal(err)
+	}
+
+	wantDepts := []academicGroup{
+		{Dept: "TE", NoteTotal: 8, Total: 8, Subjects: 3},
+		{Dept: "unknown", LabTotal: 4, Total: 4, Subjects: 1},
+	}
+	if !reflect.DeepEqual(response.ByDept, wantDepts) {
+		t.Errorf("by_dept = %+v, want %+v", response.ByDept, wantDepts)
+	}
+	wantSemesters := []academicGroup{
+		{Semester: "1", NoteTotal: 6, Total: 6, Subjects: 2},
+		{Semester: "2", NoteTotal: 2, Total: 2, Subjects: 1},
+		{Semester: "unknown", LabTotal: 4, Total: 4, Subjects: 1},
+	}
+	if !reflect.DeepEqual(response.BySemester, wantSemesters) {
+		t.Errorf("by_semester = %+v, want %+v", response.BySemester, wantSemesters)
+	}
+
+	// Subjects are ranked within their department
+	var ranked []string
+	for _, subject := range response.Subjects {
+		ranked = append(ranked, subject.Code)
+	}
+	if !reflect.DeepEqual(ranked, []string{"phy1", "chem1", "bio1", "phylab"}) || response.Subjects[0].DeptShare != 0.75 {
+		t.Errorf("subjects = %+v, want phy1 first with 0.75 of TE", response.Subjects)
+	}
+	if len(response.ZeroAccess) != 1 || response.ZeroAccess[0].Code != "bio1" {
+		t.Errorf("zero_access = %+v, want bio1", response.ZeroAccess)
+	}
+	if counts := rec.Find("FROM subject_daily_counts"); len(counts) != 1 ||
+		!reflect.DeepEqual(counts[0], []driver.Value{"2026-03-01", "2026-03-31"}) {
+		t.Errorf("daily count queries = %v", counts)
+	}
+}
+
+func TestGetAcademicStatsParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		args   []driver.Value
+	}{
+		{"type and dept filters", "?type=note&dept=textile%20eng", fiber.StatusOK, []driver.Value{"note", "TE"}},
+		{"unknown type", "?type=course", fiber.StatusBadRequest, nil},
+		{"unknown dept", "?dept=astronomy", fiber.StatusBadRequest, nil},
+		{"bad date", "?startDate=March", fiber.StatusBadRequest, nil},
+		{"start after end", "?startDate=2026-03-31&endDate=2026-03-01", fiber.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := newRecordingDB(t)
+			app := newTestApp()
+			app.Get("/stats/academic", GetAcademicStats(db))
+
+			status, body := doRequest(t, app, fiber.MethodGet, "/stats/academic"+tt.query, "")
+			if status != tt.status {
+				t.Fatalf("status = %d, want %d (body %s)", status, tt.status, body)
+			}
+			catalog := rec.Find("FROM subject_catalog WHERE active = 1 AND pending = 0")
+			if tt.args == nil {
+				if statements := rec.Statements(); len(statements) != 0 {
+					t.Errorf("ran %v", statements)
+				}
+				return
+			}
+			if len(catalog) != 1 || !reflect.DeepEqual(catalog[0], tt.args) {
+				t.Errorf("catalog queries = %v, want args %v", catalog, tt.args)
+			}
+		})
+	}
+}
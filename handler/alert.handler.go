@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"log"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/alerts"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetAlertRules lists the active error alert rules
+func GetAlertRules(alertManager *alerts.Manager, appConfig config.AppConfig) fiber.Handler {
+	log.Println("🟢 GET: GetAlertRules handler called")
+	return func(c *fiber.Ctx) error {
+		if err := utils.ValidateAdminKey(c, appConfig); err != nil {
+			return err
+		}
+
+		rules := []fiber.Map{}
+		for _, rule := range alertManager.Rules() {
+			entry := fiber.Map{
+				"name":     rule.Name,
+				"kind":     rule.Kind,
+				"cooldown": rule.Cooldown.String(),
+			}
+			if rule.Kind == alerts.RuleErrorRate {
+				entry["threshold"] = rule.Threshold
+				entry["window"] = rule.Window.String()
+			}
+			rules = append(rules, entry)
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"enabled": alertManager.Enabled(),
+			"rules":   rules,
+			"status":  config.AppMessages.Alert.RulesFetchSuccess,
+		})
+	}
+}
+
+// TestAlertWebhook sends a test notification to every configured webhook
+func TestAlertWebhook(alertManager *alerts.Manager, appConfig config.AppConfig) fiber.Handler {
+	log.Println("🔵 POST: TestAlertWebhook handler called")
+	return func(c *fiber.Ctx) error {
+		if err := utils.ValidateAdminKey(c, appConfig); err != nil {
+			return err
+		}
+
+		if !alertManager.Enabled() {
+			return c.Status(400).JSON(fiber.Map{
+				"status": config.AppMessages.Alert.Disabled,
+			})
+		}
+
+		if err := alertManager.SendTest(c.Context()); err != nil {
+			log.Printf("🔴 Error while sending test alert: %v", err)
+			return c.Status(502).JSON(fiber.Map{
+				"error":  err.Error(),
+				"status": config.AppMessages.Alert.TestUnsuccessful,
+			})
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"status": config.AppMessages.Alert.TestSuccess,
+		})
+	}
+}
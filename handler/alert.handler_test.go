@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/alerts"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAlertHandlersRequireAdminKey(t *testing.T) {
+	var calls atomic.Int32
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer webhook.Close()
+
+	manager := alerts.NewManager(config.AlertConfig{WEBHOOK_URLS: []string{webhook.URL}})
+	app := newTestApp()
+	app.Get("/alerts/rules", GetAlertRules(manager, testAppConfig))
+	app.Post("/alerts/test", TestAlertWebhook(manager, testAppConfig))
+
+	assertUnauthorized(t, app, nil, fiber.MethodGet, "/alerts/rules", "")
+	assertUnauthorized(t, app, nil, fiber.MethodPost, "/alerts/test", "")
+	if calls.Load() != 0 {
+		t.Fatalf("unauthorized requests sent %d webhook requests", calls.Load())
+	}
+}
+
+func TestGetAlertRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  config.AlertConfig
+		enabled bool
+		rules   []map[string]interface{}
+	}{
+		{
+			name: "new issue and error rate",
+			config: config.AlertConfig{
+				WEBHOOK_URLS:       []string{"http://example.com/hook"},
+				NEW_ISSUE_COOLDOWN: 5 * time.Minute,
+				RATE_THRESHOLD:     50,
+				RATE_WINDOW:        10 * time.Minute,
+				RATE_COOLDOWN:      30 * time.Minute,
+			},
+			enabled: true,
+			rules: []map[string]interface{}{
+				{"name": "new-issue", "kind": string(alerts.RuleNewIssue), "cooldown": "5m0s"},
+				{"name": "error-rate", "kind": string(alerts.RuleErrorRate), "cooldown": "30m0s", "threshold": float64(50), "window": "10m0s"},
+			},
+		},
+		{
+			name:    "rate rule off and no webhooks",
+			config:  config.AlertConfig{NEW_ISSUE_COOLDOWN: time.Minute},
+			enabled: false,
+			rules: []map[string]interface{}{
+				{"name": "new-issue", "kind": string(alerts.RuleNewIssue), "cooldown": "1m0s"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp()
+			app.Get("/alerts/rules", GetAlertRules(alerts.NewManager(tt.config), testAppConfig))
+
+			status, body := doRequest(t, app, fiber.MethodGet, "/alerts/rules?adminKey="+testAdminKey, "")
+			if status != fiber.StatusOK {
+				t.Fatalf("status = %d (body %s)", status, body)
+			}
+			var response struct {
+				Enabled bool                     `json:"enabled"`
+				Rules   []map[string]interface{} `json:"rules"`
+			}
+			if err := json.Unmarshal([]byte(body), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Enabled != tt.enabled || !reflect.DeepEqual(response.Rules, tt.rules) {
+				t.Errorf("got enabled %v rules %v, want %v %v", response.Enabled, response.Rules, tt.enabled, tt.rules)
+			}
+		})
+	}
+}
+
+func TestSendTestAlert(t *testing.T) {
+	tests := []struct {
+		name     string
+		urls     int
+		response int
+		status   int
+		calls    int32
+	}{
+		{"delivered", 1, http.StatusOK, fiber.StatusOK, 1},
+		{"webhook fails", 1, http.StatusInternalServerError, fiber.StatusBadGateway, 1},
+		{"no webhooks configured", 0, http.StatusOK, fiber.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(tt.response)
+			}))
+			defer webhook.Close()
+
+			alertConfig := config.AlertConfig{}
+			if tt.urls > 0 {
+				alertConfig.WEBHOOK_URLS = []string{webhook.URL}
+			}
+			app := newTestApp()
+			app.Post("/alerts/test", TestAlertWebhook(alerts.NewManager(alertConfig), testAppConfig))
+
+			status, body := doRequest(t, app, fiber.MethodPost, "/alerts/test?adminKey="+testAdminKey, "")
+			if status != tt.status || calls.Load() != tt.calls {
+				t.Errorf("status %d with %d webhook requests, want %d with %d (body %s)", status, calls.Load(), tt.status, tt.calls, body)
+			}
+		})
+	}
+}
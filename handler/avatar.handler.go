@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/storage"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/utils"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Content types accepted for profile images, sniffed from the file itself rather than trusted from the client
+var avatarContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// UploadUserAvatar handles uploading a profile image as the multipart "image" field. The image is
+// cropped to a square PNG thumbnail, stored, and the user's img_url is pointed at the served path.
+func UploadUserAvatar(db *gorm.DB, store storage.Storage, storageConfig config.StorageConfig) fiber.Handler {
+	log.Println("🔵 POST: UploadUserAvatar handler called")
+	return func(c *fiber.Ctx) error {
+		if c.Query("adminKey") != config.GetAppConfig().ADMIN_AUTH_KEY {
+			return c.Status(401).JSON(fiber.Map{"error": config.AppMessages.User.UnauthorizedAccess})
+		}
+
+		id, err := c.ParamsInt("id")
+		if err != nil || id < 1 {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.User.BadRequest})
+		}
+
+		user, err := findUser(db, false, "id = ?", id)
+		if err != nil {
+			log.Printf("🔴 Error while fetching user %d: %v", id, err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.FetchError})
+		}
+		if user == nil {
+			return c.Status(404).JSON(fiber.Map{"status": config.AppMessages.User.NotFound})
+		}
+
+		fileHeader, err := c.FormFile("image")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.User.ImageMissing})
+		}
+		maxBytes := int64(storageConfig.AVATAR_MAX_BYTES)
+		if fileHeader.Size > maxBytes {
+			return c.Status(413).JSON(fiber.Map{
+				"status":    config.AppMessages.User.ImageTooLarge,
+				"max_bytes": maxBytes,
+			})
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			log.Printf("🔴 Error while opening uploaded image: %v", err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.OperationUnsuccessful})
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+		if err != nil {
+			log.Printf("🔴 Error while reading uploaded image: %v", err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.OperationUnsuccessful})
+		}
+		if int64(len(data)) > maxBytes {
+			return c.Status(413).JSON(fiber.Map{
+				"status":    config.AppMessages.User.ImageTooLarge,
+				"max_bytes": maxBytes,
+			})
+		}
+
+		contentType := http.DetectContentType(data)
+		if !avatarContentTypes[contentType] {
+			return c.Status(415).JSON(fiber.Map{
+				"status":       config.AppMessages.User.ImageInvalidType,
+				"content_type": contentType,
+			})
+		}
+
+		// Check the dimensions before decoding so a tiny file can't expand into a huge bitmap
+		imageConfig, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.User.ImageInvalid})
+		}
+		if imageConfig.Width*imageConfig.Height > storageConfig.AVATAR_MAX_PIXELS {
+			return c.Status(413).JSON(fiber.Map{
+				"status":     config.AppMessages.User.ImageTooLarge,
+				"max_pixels": storageConfig.AVATAR_MAX_PIXELS,
+			})
+		}
+
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.User.ImageInvalid})
+		}
+
+		thumbnail := utils.Thumbnail(img, storageConfig.AVATAR_THUMB_SIZE)
+		var encoded bytes.Buffer
+		if err := png.Encode(&encoded, thumbnail); err != nil {
+			log.Printf("🔴 Error while encoding avatar thumbnail: %v", err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.OperationUnsuccessful})
+		}
+
+		// A new name per upload keeps clients and proxies from serving a cached old avatar
+		name := fmt.Sprintf("avatars/%d-%d.png", id, time.Now().UnixNano())
+		imgUrl, err := store.Save(name, &encoded)
+		if err != nil {
+			log.Printf("🔴 Error while storing avatar for user %d: %v", id, err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.OperationUnsuccessful})
+		}
+
+		result := db.Exec("UPDATE app_users SET img_url = ? WHERE id = ? AND deleted_at IS NULL", imgUrl, id)
+		if result.Error != nil || result.RowsAffected == 0 {
+			if err := store.Delete(imgUrl); err != nil {
+				log.Printf("🔴 Error while removing unused avatar %s: %v", imgUrl, err)
+			}
+			if result.Error != nil {
+				log.Printf("🔴 Error while updating avatar for user %d: %v", id, result.Error)
+				return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.OperationUnsuccessful})
+			}
+			return c.Status(404).JSON(fiber.Map{"status": config.AppMessages.User.NotFound})
+		}
+
+		// Only remove the previous avatar if it was one of ours, external URLs are left alone
+		if previous, ok := user["img_url"].(string); ok && store.Owns(previous) {
+			if err := store.Delete(previous); err != nil {
+				log.Printf("🔴 Error while removing previous avatar %s: %v", previous, err)
+			}
+		}
+
+		bounds := thumbnail.Bounds()
+		return c.Status(200).JSON(fiber.Map{
+			"id":     id,
+			"imgUrl": imgUrl,
+			"width":  bounds.Dx(),
+			"height": bounds.Dy(),
+			"status": config.AppMessages.User.AvatarUploadSuccess,
+		})
+	}
+}
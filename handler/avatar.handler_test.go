@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/storage"
+	"github.com/gofiber/fiber/v2"
+)
+
+var testStorageConfig = config.StorageConfig{
+	AVATAR_MAX_BYTES:  64 * 1024,
+	AVATAR_MAX_PIXELS: 1000 * 1000,
+	AVATAR_THUMB_SIZE: 64,
+}
+
+func encodeTestPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+// avatarUserRows serves user 9 with the given img_url
+func avatarUserRows(imgUrl string) func(string) ([]string, [][]driver.Value) {
+	return func(query string) ([]string, [][]driver.Value) {
+		if !strings.Contains(query, "FROM app_users WHERE id = ?") {
+			return nil, nil
+		}
+		return []string{"id", "email", "img_url"}, [][]driver.Value{{int64(9), "someone@example.com", imgUrl}}
+	}
+}
+
+func TestUploadUserAvatar(t *testing.T) {
+	useTestAdminKey(t)
+	dir := t.TempDir()
+	store := storage.NewLocalStorage(dir, "/uploads")
+	previous, err := store.Save("avatars/9-1.png", bytes.NewReader([]byte("old")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, rec := newRecordingDB(t)
+	rec.respond = avatarUserRows(previous)
+	rec.affected = func(string) int64 { return 1 }
+	app := newTestApp()
+	app.Post("/users/app/:id/avatar", UploadUserAvatar(db, store, testStorageConfig))
+
+	assertUnauthorized(t, app, rec, fiber.MethodPost, "/users/app/9/avatar", "")
+
+	status, body := doUpload(t, app, "/users/app/9/avatar?adminKey="+testAdminKey, "image", "me.png", encodeTestPNG(t, 300, 200))
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d (body %s)", status, body)
+	}
+	var response struct {
+		ImgUrl string `json:"imgUrl"`
+		Width  int    `json:"width"`
+		Height int    `json:"height"`
+	}
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(response.ImgUrl, "/uploads/avatars/9-") || response.Width != 64 || response.Height != 64 {
+		t.Errorf("response = %+v, want a 64x64 avatar under /uploads/avatars", response)
+	}
+
+	updates := rec.Find("UPDATE app_users SET img_url = ?")
+	if len(updates) != 1 || updates[0][0] != response.ImgUrl || updates[0][1] != int64(9) {
+		t.Errorf("updates = %v, want user 9 pointed at %s", updates, response.ImgUrl)
+	}
+
+	// The stored file is the PNG thumbnail and the previous avatar is gone
+	data, err := os.ReadFile(filepath.Join(dir, strings.TrimPrefix(response.ImgUrl, "/uploads/")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil || stored.Width != 64 || stored.Height != 64 {
+		t.Errorf("stored %dx%d (%v), want a 64x64 PNG", stored.Width, stored.Height, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "avatars", "9-1.png")); !os.IsNotExist(err) {
+		t.Errorf("previous avatar still exists: %v", err)
+	}
+}
+
+func TestUploadUserAvatarKeepsExternalImages(t *testing.T) {
+	useTestAdminKey(t)
+	dir := t.TempDir()
+	store := storage.NewLocalStorage(dir, "/uploads")
+
+	db, rec := newRecordingDB(t)
+	rec.respond = avatarUserRows("https://lh3.googleusercontent.com/a.png")
+	rec.affected = func(string) int64 { return 1 }
+	app := newTestApp()
+	app.Post("/users/app/:id/avatar", UploadUserAvatar(db, store, testStorageConfig))
+
+	if status, body := doUpload(t, app, "/users/app/9/avatar?adminKey="+testAdminKey, "image", "me.png", encodeTestPNG(t, 10, 10)); status != fiber.StatusOK {
+		t.Fatalf("status = %d (body %s)", status, body)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(dir, "avatars")); len(entries) != 1 {
+		t.Errorf("avatars dir holds %d files, want the new avatar", len(entries))
+	}
+}
+
+func TestUploadUserAvatarRejectsBadImages(t *testing.T) {
+	useTestAdminKey(t)
+
+	tests := []struct {
+		name    string
+		target  string
+		field   string
+		content []byte
+		status  int
+	}{
+		{"not an image", "/users/app/9/avatar", "image", []byte("%PDF-1.4 not an image"), fiber.StatusUnsupportedMediaType},
+		{"too many bytes", "/users/app/9/avatar", "image", append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64*1024)...), fiber.StatusRequestEntityTooLarge},
+		{"too many pixels", "/users/app/9/avatar", "image", encodeTestPNG(t, 2000, 1000), fiber.StatusRequestEntityTooLarge},
+		{"truncated image", "/users/app/9/avatar", "image", encodeTestPNG(t, 10, 10)[:40], fiber.StatusBadRequest},
+		{"wrong field", "/users/app/9/avatar", "file", encodeTestPNG(t, 10, 10), fiber.StatusBadRequest},
+		{"unknown user", "/users/app/8/avatar", "image", encodeTestPNG(t, 10, 10), fiber.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			db, rec := newRecordingDB(t)
+			rec.respond = func(query string) ([]string, [][]driver.Value) {
+				if strings.Contains(tt.target, "/9/") {
+					return avatarUserRows("not given")(query)
+				}
+				return nil, nil
+			}
+			app := newTestApp()
+			app.Post("/users/app/:id/avatar", UploadUserAvatar(db, storage.NewLocalStorage(dir, "/uploads"), testStorageConfig))
+
+			status, body := doUpload(t, app, tt.target+"?adminKey="+testAdminKey, tt.field, "me.png", tt.content)
+			if status != tt.status {
+				t.Errorf("status = %d, want %d (body %s)", status, tt.status, body)
+			}
+			if updates := rec.Find("UPDATE app_users"); len(updates) != 0 {
+				t.Errorf("updated %v", updates)
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+				t.Errorf("stored %d files", len(entries))
+			}
+		})
+	}
+}
+
+func TestUploadUserAvatarRemovesUnusedFile(t *testing.T) {
+	useTestAdminKey(t)
+	dir := t.TempDir()
+
+	// The user was deleted between the lookup and the update
+	db, rec := newRecordingDB(t)
+	rec.respond = avatarUserRows("not given")
+	app := newTestApp()
+	app.Post("/users/app/:id/avatar", UploadUserAvatar(db, storage.NewLocalStorage(dir, "/uploads"), testStorageConfig))
+
+	if status, body := doUpload(t, app, "/users/app/9/avatar?adminKey="+testAdminKey, "image", "me.png", encodeTestPNG(t, 10, 10)); status != fiber.StatusNotFound {
+		t.Fatalf("status = %d, want 404 (body %s)", status, body)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(dir, "avatars")); len(entries) != 0 {
+		t.Errorf("avatars dir holds %d files, want none", len(entries))
+	}
+}
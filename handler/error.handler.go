@@ -1,32 +1,250 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"log"
+	"regexp"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/alerts"
 	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
+// StackFrame is a single frame of a client side stack trace
+type StackFrame struct {
+	Function string `json:"function,omitempty"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	InApp    bool   `json:"in_app,omitempty"`
+}
+
+// ErrorLog is an error reported by a client. Only date, log, os and email existed
+// in older app versions, so every other field is optional. The event ID is generated
+// by the client and lets retried uploads be recognised as duplicates.
 type ErrorLog struct {
-	Date  time.Time `json:"date"`
-	Log   string    `json:"log"`
-	OS    string    `json:"os"`
-	Email string    `json:"email"`
+	EventID     string            `json:"event_id,omitempty"`
+	Date        time.Time         `json:"date"`
+	Log         string            `json:"log"`
+	OS          string            `json:"os"`
+	Email       string            `json:"email"`
+	AppVersion  string            `json:"app_version,omitempty"`
+	AppBuild    string            `json:"app_build,omitempty"`
+	DeviceModel string            `json:"device_model,omitempty"`
+	OSVersion   string            `json:"os_version,omitempty"`
+	Screen      string            `json:"screen,omitempty"`
+	StackTrace  []StackFrame      `json:"stack_trace,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
 }
 
 type ErrorResponse struct {
 	ErrorInfo    ErrorLog                 `json:"errorInfo,omitempty"`
+	Issue        *ErrorIssue              `json:"issue,omitempty"`
 	ErrorLogs    []map[string]interface{} `json:"errorLogs,omitempty"`
 	SearchedLogs []map[string]interface{} `json:"searched_logs,omitempty"`
 	Status       string                   `json:"status"`
 }
 
+const (
+	maxStackFrames = 200
+	maxErrorTags   = 50
+	maxEventIDLen  = 64
+)
+
+// errorLogFieldLimits mirrors the VARCHAR sizes of the optional app_err_logs columns
+var errorLogFieldLimits = []struct {
+	Value func(ErrorLog) string
+	Max   int
+}{
+	{func(e ErrorLog) string { return e.AppVersion }, 64},
+	{func(e ErrorLog) string { return e.AppBuild }, 64},
+	{func(e ErrorLog) string { return e.DeviceModel }, 128},
+	{func(e ErrorLog) string { return e.OSVersion }, 64},
+	{func(e ErrorLog) string { return e.Screen }, 255},
+}
+
+var tagKeyRegex = regexp.MustCompile(`^[A-Za-z0-9_.\-]{1,64}$`)
+
+// Optional error log fields that can be used as exact match filters
+var errorLogFilterColumns = []string{"os", "app_version", "app_build", "device_model", "os_version", "screen"}
+
+// culprit describes where the error was thrown, preferring the first frame of app code
+func (e ErrorLog) culprit() string {
+	if len(e.StackTrace) == 0 {
+		return ""
+	}
+
+	frame := e.StackTrace[0]
+	for _, f := range e.StackTrace {
+		if f.InApp {
+			frame = f
+			break
+		}
+	}
+	return frame.Function + "@" + frame.File
+}
+
+// validateErrorLog checks required fields and the shape of the optional ones,
+// returning the message to send back when the log is rejected
+func validateErrorLog(errorLog ErrorLog) (string, bool) {
+	if errorLog.Email == "" || errorLog.Log == "" || errorLog.OS == "" {
+		return config.AppMessages.ErrorLog.BadRequest, false
+	}
+
+	if !utils.ValidateEmail(errorLog.Email) {
+		return config.AppMessages.ErrorLog.InvalidEmail, false
+	}
+
+	if len(errorLog.EventID) > maxEventIDLen {
+		return config.AppMessages.ErrorLog.BadRequest, false
+	}
+
+	for _, field := range errorLogFieldLimits {
+		if utf8.RuneCountInString(field.Value(errorLog)) > field.Max {
+			return config.AppMessages.ErrorLog.FieldTooLong, false
+		}
+	}
+
+	if len(errorLog.StackTrace) > maxStackFrames || len(errorLog.Tags) > maxErrorTags {
+		return config.AppMessages.ErrorLog.InvalidPayload, false
+	}
+
+	for key := range errorLog.Tags {
+		if !tagKeyRegex.MatchString(key) {
+			return config.AppMessages.ErrorLog.InvalidPayload, false
+		}
+	}
+
+	return "", true
+}
+
+// errorLogFingerprint picks the issue fingerprint for a log. Logs without a stack trace
+// keep the message-only fingerprint older clients produced. Logs with a stack trace are
+// grouped by message and culprit, unless only a message-only issue exists yet, so the
+// same error reported with and without a stack trace stays a single issue.
+func errorLogFingerprint(tx *gorm.DB, errorLog ErrorLog) (string, error) {
+	legacy := utils.ErrorFingerprint(errorLog.Log, "")
+	culprit := errorLog.culprit()
+	if culprit == "" {
+		return legacy, nil
+	}
+	withCulprit := utils.ErrorFingerprint(errorLog.Log, culprit)
+
+	var existing []string
+	if err := tx.Raw("SELECT fingerprint FROM app_err_issues WHERE fingerprint IN (?, ?)", withCulprit, legacy).
+		Scan(&existing).Error; err != nil {
+		return "", err
+	}
+	for _, fingerprint := range existing {
+		if fingerprint == withCulprit {
+			return withCulprit, nil
+		}
+	}
+	if len(existing) > 0 {
+		return legacy, nil
+	}
+	return withCulprit, nil
+}
+
+// insertErrorLog stores an error log and attaches it to its issue. It must run inside a transaction.
+func insertErrorLog(tx *gorm.DB, errorLog ErrorLog) (ErrorIssue, bool, error) {
+	fingerprint, err := errorLogFingerprint(tx, errorLog)
+	if err != nil {
+		return ErrorIssue{}, false, err
+	}
+
+	var stackTrace, tags interface{}
+	if len(errorLog.StackTrace) > 0 {
+		encoded, err := json.Marshal(errorLog.StackTrace)
+		if err != nil {
+			return ErrorIssue{}, false, err
+		}
+		stackTrace = string(encoded)
+	}
+	if len(errorLog.Tags) > 0 {
+		encoded, err := json.Marshal(errorLog.Tags)
+		if err != nil {
+			return ErrorIssue{}, false, err
+		}
+		tags = string(encoded)
+	}
+
+	if err := tx.Exec(`
+		INSERT INTO app_err_logs (event_id, date, log, os, email, fingerprint, app_version, app_build, device_model, os_version, screen, stack_trace, tags) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		utils.NullIfEmpty(errorLog.EventID), errorLog.Date, errorLog.Log, errorLog.OS, errorLog.Email, fingerprint,
+		utils.NullIfEmpty(errorLog.AppVersion), utils.NullIfEmpty(errorLog.AppBuild), utils.NullIfEmpty(errorLog.DeviceModel),
+		utils.NullIfEmpty(errorLog.OSVersion), utils.NullIfEmpty(errorLog.Screen), stackTrace, tags,
+	).Error; err != nil {
+		return ErrorIssue{}, false, err
+	}
+
+	return recordErrorIssue(tx, errorLog, fingerprint)
+}
+
+// ingestErrorLog stores an error log and updates its issue in a single transaction so
+// counts never drift. A log whose event ID is already stored is reported as a duplicate.
+func ingestErrorLog(db *gorm.DB, errorLog ErrorLog) (issue ErrorIssue, isNewIssue bool, duplicate bool, err error) {
+	if errorLog.EventID != "" {
+		var count int64
+		if err := db.Raw("SELECT COUNT(*) FROM app_err_logs WHERE event_id = ?", errorLog.EventID).
+			Scan(&count).Error; err != nil {
+			return issue, false, false, err
+		}
+		if count > 0 {
+			return issue, false, true, nil
+		}
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		issue, isNewIssue, err = insertErrorLog(tx, errorLog)
+		return err
+	})
+
+	// A concurrent retry of the same event can still slip past the check above
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrorIssue{}, false, true, nil
+	}
+	return issue, isNewIssue, false, err
+}
+
+// decodeErrorLogRows turns the JSON columns of raw error log rows back into objects
+func decodeErrorLogRows(rows []map[string]interface{}) {
+	for _, row := range rows {
+		for _, column := range []string{"stack_trace", "tags"} {
+			raw, ok := row[column].(string)
+			if !ok {
+				continue
+			}
+			var decoded interface{}
+			if err := json.Unmarshal([]byte(raw), &decoded); err == nil {
+				row[column] = decoded
+			}
+		}
+	}
+}
+
+// alertEvent describes an ingested error log for the alert rules
+func alertEvent(errorLog ErrorLog, issue ErrorIssue, isNewIssue bool) alerts.Event {
+	return alerts.Event{
+		IssueID:    issue.ID,
+		IssueTitle: issue.Title,
+		IsNewIssue: isNewIssue,
+		AppVersion: errorLog.AppVersion,
+		OS:         errorLog.OS,
+	}
+}
+
 // PostNewError handles creation of new error logs
-func PostNewError(db *gorm.DB, appConfig config.AppConfig) fiber.Handler {
+func PostNewError(db *gorm.DB, appConfig config.AppConfig, alertManager *alerts.Manager) fiber.Handler {
 	log.Println("🔵 POST: PostNewError handler called")
 	return func(c *fiber.Ctx) error {
 		if err := utils.ValidateAdminKey(c, appConfig); err != nil {
@@ -40,16 +258,10 @@ func PostNewError(db *gorm.DB, appConfig config.AppConfig) fiber.Handler {
 			})
 		}
 
-		// Validate required fields
-		if errorLog.Email == "" || errorLog.Log == "" || errorLog.OS == "" {
+		// Validate required and optional fields
+		if message, ok := validateErrorLog(errorLog); !ok {
 			return c.Status(400).JSON(fiber.Map{
-				"status": config.AppMessages.ErrorLog.BadRequest,
-			})
-		}
-
-		if !utils.ValidateEmail(errorLog.Email) {
-			return c.Status(400).JSON(fiber.Map{
-				"status": config.AppMessages.ErrorLog.InvalidEmail,
+				"status": message,
 			})
 		}
 
@@ -58,27 +270,118 @@ func PostNewError(db *gorm.DB, appConfig config.AppConfig) fiber.Handler {
 			errorLog.Date = time.Now()
 		}
 
-		// Execute raw SQL query
-		result := db.Exec(`
-			INSERT INTO app_err_logs (date, log, os, email) 
-			VALUES (?, ?, ?, ?)`,
-			errorLog.Date, errorLog.Log, errorLog.OS, errorLog.Email,
-		)
-
-		if result.Error != nil {
+		issue, isNewIssue, duplicate, err := ingestErrorLog(db, errorLog)
+		if err != nil {
+			log.Printf("🔴 Error while inserting error log: %v", err)
 			return c.Status(500).JSON(fiber.Map{
 				"status": config.AppMessages.ErrorLog.OperationUnsuccessful,
 			})
 		}
 
+		if duplicate {
+			return c.Status(200).JSON(ErrorResponse{
+				ErrorInfo: errorLog,
+				Status:    config.AppMessages.ErrorLog.DuplicateEvent,
+			})
+		}
+
+		alertManager.ErrorIngested(alertEvent(errorLog, issue, isNewIssue))
+
 		return c.Status(200).JSON(ErrorResponse{
 			ErrorInfo: errorLog,
+			Issue:     &issue,
 			Status:    config.AppMessages.ErrorLog.LogInsertSuccess,
 		})
 	}
 }
 
-// GetErrorLogs retrieves all error logs
+// PostErrorBatch handles error logs replayed in bulk by offline clients. Every entry is
+// validated and stored on its own, so one bad entry never rejects the rest of the batch.
+// Invalid entries are "rejected" and must not be resent. Entries that could not be stored
+// because of a database error are "failed" and retryable, and the whole response is then a
+// 503 so clients keep those logs queued. Event IDs make resending the batch safe.
+func PostErrorBatch(db *gorm.DB, appConfig config.AppConfig, alertManager *alerts.Manager) fiber.Handler {
+	log.Println("🔵 POST: PostErrorBatch handler called")
+	return func(c *fiber.Ctx) error {
+		if err := utils.ValidateAdminKey(c, appConfig); err != nil {
+			return err
+		}
+
+		var body struct {
+			Logs []ErrorLog `json:"logs"`
+		}
+		if err := c.BodyParser(&body); err != nil || len(body.Logs) == 0 {
+			return c.Status(400).JSON(fiber.Map{
+				"status": config.AppMessages.ErrorLog.BadRequest,
+			})
+		}
+
+		if len(body.Logs) > appConfig.ERR_LOG_BATCH_MAX {
+			return c.Status(413).JSON(fiber.Map{
+				"max_batch_size": appConfig.ERR_LOG_BATCH_MAX,
+				"status":         config.AppMessages.ErrorLog.BatchTooLarge,
+			})
+		}
+
+		results := make([]fiber.Map, 0, len(body.Logs))
+		var accepted, duplicates, rejected, failed int
+
+		for index, errorLog := range body.Logs {
+			result := fiber.Map{
+				"index":    index,
+				"event_id": errorLog.EventID,
+			}
+
+			if message, ok := validateErrorLog(errorLog); !ok {
+				rejected++
+				result["status"] = "rejected"
+				result["error"] = message
+				results = append(results, result)
+				continue
+			}
+
+			if errorLog.Date.IsZero() {
+				errorLog.Date = time.Now()
+			}
+
+			issue, isNewIssue, duplicate, err := ingestErrorLog(db, errorLog)
+			switch {
+			case err != nil:
+				log.Printf("🔴 Error while inserting batched error log %d: %v", index, err)
+				failed++
+				result["status"] = "failed"
+				result["retryable"] = true
+				result["error"] = config.AppMessages.ErrorLog.OperationUnsuccessful
+			case duplicate:
+				duplicates++
+				result["status"] = "duplicate"
+			default:
+				accepted++
+				result["status"] = "accepted"
+				result["issue_id"] = issue.ID
+				alertManager.ErrorIngested(alertEvent(errorLog, issue, isNewIssue))
+			}
+			results = append(results, result)
+		}
+
+		status, message := 200, config.AppMessages.ErrorLog.BatchProcessed
+		if failed > 0 {
+			status, message = 503, config.AppMessages.ErrorLog.BatchRetryable
+		}
+
+		return c.Status(status).JSON(fiber.Map{
+			"results":    results,
+			"accepted":   accepted,
+			"duplicates": duplicates,
+			"rejected":   rejected,
+			"failed":     failed,
+			"status":     message,
+		})
+	}
+}
+
+// GetErrorLogs retrieves error logs, optionally filtered by client metadata and tags.
+// Tags are filtered with repeated "tag=key:value" query parameters.
 func GetErrorLogs(db *gorm.DB, appConfig config.AppConfig) fiber.Handler {
 	log.Println("🟢 GET: GetErrorLogs handler called")
 	return func(c *fiber.Ctx) error {
@@ -86,12 +389,35 @@ func GetErrorLogs(db *gorm.DB, appConfig config.AppConfig) fiber.Handler {
 			return err
 		}
 
+		query := "SELECT * FROM app_err_logs WHERE 1=1"
+		params := []interface{}{}
+
+		for _, column := range errorLogFilterColumns {
+			if value := c.Query(column); value != "" {
+				query += " AND " + column + " = ?"
+				params = append(params, value)
+			}
+		}
+
+		for _, tag := range c.Context().QueryArgs().PeekMulti("tag") {
+			key, value, found := strings.Cut(string(tag), ":")
+			if !found || !tagKeyRegex.MatchString(key) {
+				return c.Status(400).JSON(fiber.Map{
+					"status": config.AppMessages.ErrorLog.BadRequest,
+				})
+			}
+			query += " AND JSON_UNQUOTE(JSON_EXTRACT(tags, ?)) = ?"
+			params = append(params, `$."`+key+`"`, value)
+		}
+
 		var results []map[string]interface{}
-		if err := db.Raw("SELECT * FROM app_err_logs").Scan(&results).Error; err != nil {
+		if err := db.Raw(query, params...).Scan(&results).Error; err != nil {
+			log.Printf("🔴 Error while fetching error logs: %v", err)
 			return c.Status(500).JSON(fiber.Map{
 				"status": config.AppMessages.ErrorLog.OperationUnsuccessful,
 			})
 		}
+		decodeErrorLogRows(results)
 
 		return c.Status(200).JSON(ErrorResponse{
 			ErrorLogs: results,
@@ -125,6 +451,7 @@ func GetErrorsByEmail(db *gorm.DB, appConfig config.AppConfig) fiber.Handler {
 				"status": config.AppMessages.ErrorLog.EmailFetchError,
 			})
 		}
+		decodeErrorLogRows(results)
 
 		return c.Status(200).JSON(ErrorResponse{
 			SearchedLogs: results,
@@ -132,3 +459,118 @@ func GetErrorsByEmail(db *gorm.DB, appConfig config.AppConfig) fiber.Handler {
 		})
 	}
 }
+
+// GetErrorStats returns error counts per day, OS and app version, plus the most frequent
+// messages and most affected users, optionally limited to a startDate/endDate range
+func GetErrorStats(db *gorm.DB, appConfig config.AppConfig) fiber.Handler {
+	log.Println("🟢 GET: GetErrorStats handler called")
+	return func(c *fiber.Ctx) error {
+		if err := utils.ValidateAdminKey(c, appConfig); err != nil {
+			return err
+		}
+
+		top := c.QueryInt("top", 10)
+		if top < 1 || top > 100 {
+			top = 10
+		}
+
+		// Get date filter parameters
+		startDate := c.Query("startDate") // Format: YYYY-MM-DD
+		endDate := c.Query("endDate")     // Format: YYYY-MM-DD
+
+		whereClause := "1=1"
+		params := []interface{}{}
+
+		if startDate != "" {
+			if _, err := time.Parse(time.DateOnly, startDate); err != nil {
+				return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.ErrorLog.InvalidDateRange})
+			}
+			whereClause += " AND l.date >= ?"
+			params = append(params, startDate)
+		}
+		if endDate != "" {
+			if _, err := time.Parse(time.DateOnly, endDate); err != nil {
+				return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.ErrorLog.InvalidDateRange})
+			}
+			whereClause += " AND l.date < DATE_ADD(?, INTERVAL 1 DAY)"
+			params = append(params, endDate)
+		}
+		if startDate != "" && endDate != "" && startDate > endDate {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.ErrorLog.InvalidDateRange})
+		}
+
+		var total int64
+		var perDay, perOS, perVersion, topMessages, topUsers []map[string]interface{}
+
+		queries := []struct {
+			name   string
+			query  string
+			dest   interface{}
+			params []interface{}
+		}{
+			{"total", "SELECT COUNT(*) FROM app_err_logs l WHERE " + whereClause, &total, params},
+			{"per day", `
+				SELECT DATE_FORMAT(l.date, '%Y-%m-%d') AS day, COUNT(*) AS count
+				FROM app_err_logs l
+				WHERE ` + whereClause + `
+				GROUP BY day
+				ORDER BY day ASC`, &perDay, params},
+			{"per os", `
+				SELECT l.os, COUNT(*) AS count
+				FROM app_err_logs l
+				WHERE ` + whereClause + `
+				GROUP BY l.os
+				ORDER BY count DESC`, &perOS, params},
+			{"per app version", `
+				SELECT COALESCE(l.app_version, 'unknown') AS app_version, COUNT(*) AS count
+				FROM app_err_logs l
+				WHERE ` + whereClause + `
+				GROUP BY COALESCE(l.app_version, 'unknown')
+				ORDER BY count DESC`, &perVersion, params},
+			// Logs stored before issue grouping have no fingerprint, so fall back to their raw text
+			{"top messages", `
+				SELECT message, COUNT(*) AS count, MAX(date) AS last_seen
+				FROM (
+					SELECT COALESCE(i.title, LEFT(l.log, 255)) AS message, l.date
+					FROM app_err_logs l
+					LEFT JOIN app_err_issues i ON i.fingerprint = l.fingerprint
+					WHERE ` + whereClause + `
+				) t
+				GROUP BY message
+				ORDER BY count DESC
+				LIMIT ?`, &topMessages, append(append([]interface{}{}, params...), top)},
+			{"top users", `
+				SELECT l.email, COUNT(*) AS count, COUNT(DISTINCT l.fingerprint) AS issues, MAX(l.date) AS last_error
+				FROM app_err_logs l
+				WHERE ` + whereClause + `
+				GROUP BY l.email
+				ORDER BY count DESC
+				LIMIT ?`, &topUsers, append(append([]interface{}{}, params...), top)},
+		}
+
+		for _, q := range queries {
+			if err := db.Raw(q.query, q.params...).Scan(q.dest).Error; err != nil {
+				log.Printf("🔴 Error while fetching error stats (%s): %v", q.name, err)
+				return c.Status(500).JSON(fiber.Map{
+					"status": config.AppMessages.ErrorLog.OperationUnsuccessful,
+				})
+			}
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"status": config.AppMessages.ErrorLog.StatsFetchSuccess,
+			"range": fiber.Map{
+				"startDate": startDate,
+				"endDate":   endDate,
+			},
+			"stats": fiber.Map{
+				"total":               total,
+				"per_day":             perDay,
+				"per_os":              perOS,
+				"per_app_version":     perVersion,
+				"top_messages":        topMessages,
+				"most_affected_users": topUsers,
+			},
+		})
+	}
+}
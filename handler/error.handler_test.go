@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+func validErrorLog() ErrorLog {
+	return ErrorLog{
+		Date:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Log:   "TypeError: x is undefined",
+		OS:    "android",
+		Email: "student@butex.edu.bd",
+	}
+}
+
+func TestValidateErrorLog(t *testing.T) {
+	messages := config.AppMessages.ErrorLog
+	tooManyTags := map[string]string{}
+	for i := 0; i <= maxErrorTags; i++ {
+		tooManyTags["tag"+strings.Repeat("x", i)] = "v"
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*ErrorLog)
+		want   string
+	}{
+		{"valid legacy log", func(e *ErrorLog) {}, ""},
+		{"valid full log", func(e *ErrorLog) {
+			e.EventID = "evt-1"
+			e.AppVersion = "1.2.3"
+			e.AppBuild = "42"
+			e.DeviceModel = "Pixel 7"
+			e.OSVersion = "14"
+			e.Screen = "NotesScreen"
+			e.StackTrace = []StackFrame{{Function: "load", File: "notes.dart", Line: 10, InApp: true}}
+			e.Tags = map[string]string{"release.channel": "beta"}
+		}, ""},
+		{"missing email", func(e *ErrorLog) { e.Email = "" }, messages.BadRequest},
+		{"missing log", func(e *ErrorLog) { e.Log = "" }, messages.BadRequest},
+		{"missing os", func(e *ErrorLog) { e.OS = "" }, messages.BadRequest},
+		{"invalid email", func(e *ErrorLog) { e.Email = "not-an-email" }, messages.InvalidEmail},
+		{"event id too long", func(e *ErrorLog) { e.EventID = strings.Repeat("e", maxEventIDLen+1) }, messages.BadRequest},
+		{"app version too long", func(e *ErrorLog) { e.AppVersion = strings.Repeat("1", 65) }, messages.FieldTooLong},
+		{"app build too long", func(e *ErrorLog) { e.AppBuild = strings.Repeat("1", 65) }, messages.FieldTooLong},
+		{"device model too long", func(e *ErrorLog) { e.DeviceModel = strings.Repeat("d", 129) }, messages.FieldTooLong},
+		{"os version too long", func(e *ErrorLog) { e.OSVersion = strings.Repeat("1", 65) }, messages.FieldTooLong},
+		{"screen too long", func(e *ErrorLog) { e.Screen = strings.Repeat("s", 256) }, messages.FieldTooLong},
+		{"screen at limit counts runes", func(e *ErrorLog) { e.Screen = strings.Repeat("স", 255) }, ""},
+		{"too many frames", func(e *ErrorLog) { e.StackTrace = make([]StackFrame, maxStackFrames+1) }, messages.InvalidPayload},
+		{"too many tags", func(e *ErrorLog) { e.Tags = tooManyTags }, messages.InvalidPayload},
+		{"invalid tag key", func(e *ErrorLog) { e.Tags = map[string]string{"bad key": "v"} }, messages.InvalidPayload},
+	}
+	for _, tt := range tests {
+		errorLog := validErrorLog()
+		tt.modify(&errorLog)
+		got, ok := validateErrorLog(errorLog)
+		if got != tt.want || ok != (tt.want == "") {
+			t.Errorf("%s: validateErrorLog = (%q, %v), want (%q, %v)", tt.name, got, ok, tt.want, tt.want == "")
+		}
+	}
+}
+
+func TestErrorLogCulprit(t *testing.T) {
+	errorLog := validErrorLog()
+	if got := errorLog.culprit(); got != "" {
+		t.Errorf("culprit without stack = %q, want empty", got)
+	}
+
+	errorLog.StackTrace = []StackFrame{
+		{Function: "throwError", File: "framework.dart"},
+		{Function: "loadNotes", File: "notes.dart", InApp: true},
+	}
+	if got := errorLog.culprit(); got != "loadNotes@notes.dart" {
+		t.Errorf("culprit = %q, want first in-app frame", got)
+	}
+
+	errorLog.StackTrace[1].InApp = false
+	if got := errorLog.culprit(); got != "throwError@framework.dart" {
+		t.Errorf("culprit = %q, want first frame when none is in app", got)
+	}
+}
+
+func TestErrorLogFingerprint(t *testing.T) {
+	withoutStack := validErrorLog()
+	withStack := validErrorLog()
+	withStack.StackTrace = []StackFrame{{Function: "loadNotes", File: "notes.dart", InApp: true}}
+
+	legacy := utils.ErrorFingerprint(withStack.Log, "")
+	withCulprit := utils.ErrorFingerprint(withStack.Log, withStack.culprit())
+
+	tests := []struct {
+		name     string
+		errorLog ErrorLog
+		existing []string
+		want     string
+	}{
+		{"no stack keeps message-only fingerprint", withoutStack, nil, legacy},
+		{"stack on a new error uses culprit", withStack, nil, withCulprit},
+		{"stack joins existing message-only issue", withStack, []string{legacy}, legacy},
+		{"stack prefers existing culprit issue", withStack, []string{legacy, withCulprit}, withCulprit},
+	}
+	for _, tt := range tests {
+		db, rec := newRecordingDB(t)
+		rec.respond = func(query string) ([]string, [][]driver.Value) {
+			var rows [][]driver.Value
+			for _, fingerprint := range tt.existing {
+				rows = append(rows, []driver.Value{fingerprint})
+			}
+			return []string{"fingerprint"}, rows
+		}
+		got, err := errorLogFingerprint(db, tt.errorLog)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: fingerprint = %s, want %s", tt.name, got, tt.want)
+		}
+		if tt.errorLog.culprit() == "" && len(rec.Statements()) != 0 {
+			t.Errorf("%s: looked up issues for a log without stack trace", tt.name)
+		}
+	}
+}
+
+func TestPostErrorBatchRequiresAdminKey(t *testing.T) {
+	db, rec := newRecordingDB(t)
+	app := newTestApp()
+	app.Post("/errors/batch", PostErrorBatch(db, testAppConfig, nil))
+
+	assertUnauthorized(t, app, rec, fiber.MethodPost, "/errors/batch",
+		`{"logs":[{"log":"boom","os":"android","email":"student@butex.edu.bd"}]}`)
+}
+
+func TestPostErrorBatch(t *testing.T) {
+	const batch = `{"logs":[
+		{"log":"boom","os":"android","email":"student@butex.edu.bd"},
+		{"log":"","os":"android","email":"student@butex.edu.bd"}
+	]}`
+
+	tests := []struct {
+		name       string
+		dbErr      error
+		wantStatus int
+		wantFirst  string
+		wantCounts [3]int
+	}{
+		{"stored", nil, fiber.StatusOK, "accepted", [3]int{1, 1, 0}},
+		{"database down", errRecorderDown, fiber.StatusServiceUnavailable, "failed", [3]int{0, 1, 1}},
+	}
+	for _, tt := range tests {
+		db, rec := newRecordingDB(t)
+		rec.err = tt.dbErr
+		app := newTestApp()
+		app.Post("/errors/batch", PostErrorBatch(db, testAppConfig, nil))
+
+		status, body := doRequest(t, app, fiber.MethodPost, "/errors/batch?adminKey="+testAdminKey, batch)
+		if status != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d (body %s)", tt.name, status, tt.wantStatus, body)
+		}
+
+		var response struct {
+			Results  []map[string]interface{} `json:"results"`
+			Accepted int                      `json:"accepted"`
+			Rejected int                      `json:"rejected"`
+			Failed   int                      `json:"failed"`
+		}
+		if err := json.Unmarshal([]byte(body), &response); err != nil {
+			t.Fatalf("%s: decode body: %v", tt.name, err)
+		}
+		if counts := [3]int{response.Accepted, response.Rejected, response.Failed}; counts != tt.wantCounts {
+			t.Errorf("%s: accepted, rejected, failed = %v, want %v", tt.name, counts, tt.wantCounts)
+		}
+		if len(response.Results) != 2 {
+			t.Fatalf("%s: got %d results, want 2", tt.name, len(response.Results))
+		}
+		if response.Results[0]["status"] != tt.wantFirst {
+			t.Errorf("%s: first result = %v, want %s", tt.name, response.Results[0]["status"], tt.wantFirst)
+		}
+		if tt.wantFirst == "failed" && response.Results[0]["retryable"] != true {
+			t.Errorf("%s: failed result is not marked retryable", tt.name)
+		}
+		if response.Results[1]["status"] != "rejected" || response.Results[1]["retryable"] != nil {
+			t.Errorf("%s: invalid entry = %v, want a non-retryable rejection", tt.name, response.Results[1])
+		}
+	}
+}
+
+func TestGetErrorStats(t *testing.T) {
+	db, rec := newRecordingDB(t)
+	app := newTestApp()
+	app.Get("/errors/stats", GetErrorStats(db, testAppConfig))
+
+	assertUnauthorized(t, app, rec, fiber.MethodGet, "/errors/stats", "")
+
+	for _, query := range []string{"&startDate=01-02-2026", "&endDate=2026-13-01", "&startDate=2026-02-01&endDate=2026-01-31"} {
+		status, body := doRequest(t, app, fiber.MethodGet, "/errors/stats?adminKey="+testAdminKey+query, "")
+		if status != fiber.StatusBadRequest || !strings.Contains(body, config.AppMessages.ErrorLog.InvalidDateRange) {
+			t.Errorf("%s: got %d %s, want 400 with the invalid date range message", query, status, body)
+		}
+	}
+	if statements := rec.Statements(); len(statements) != 0 {
+		t.Fatalf("invalid dates reached the database: %v", statements)
+	}
+
+	rec.respond = func(query string) ([]string, [][]driver.Value) {
+		switch {
+		case strings.Contains(query, "SELECT COUNT(*)"):
+			return []string{"count"}, [][]driver.Value{{int64(3)}}
+		case strings.Contains(query, "AS day"):
+			return []string{"day", "count"}, [][]driver.Value{{"2026-01-01", int64(1)}, {"2026-01-02", int64(2)}}
+		}
+		return nil, nil
+	}
+	status, body := doRequest(t, app, fiber.MethodGet, "/errors/stats?adminKey="+testAdminKey+"&startDate=2026-01-01&endDate=2026-01-01&top=5", "")
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200 (body %s)", status, body)
+	}
+	var response struct {
+		Stats struct {
+			Total  int64                    `json:"total"`
+			PerDay []map[string]interface{} `json:"per_day"`
+		} `json:"stats"`
+	}
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Stats.Total != 3 || len(response.Stats.PerDay) != 2 {
+		t.Errorf("response = %s", body)
+	}
+	for _, args := range rec.Find("l.date >= ? AND l.date < DATE_ADD(?, INTERVAL 1 DAY)") {
+		if args[0] != "2026-01-01" || args[1] != "2026-01-01" {
+			t.Errorf("date args = %v", args)
+		}
+	}
+	if top := rec.Find("LIMIT ?"); len(top) != 2 || top[0][2] != int64(5) {
+		t.Errorf("top queries = %v, want two limited to 5", top)
+	}
+}
@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/utils"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+const testAdminKey = "test-admin-key"
+
+var testAppConfig = config.AppConfig{
+	ADMIN_AUTH_KEY:    testAdminKey,
+	ENVIRONMENT:       "test",
+	ERR_LOG_BATCH_MAX: 100,
+}
+
+// recorder is a database/sql driver that records every statement it receives.
+// Queries return the rows produced by respond (none when it is nil), writes report
+// the rows affected given by affected (none when it is nil), and every statement
+// fails with err when it is set.
+type recorder struct {
+	mu         sync.Mutex
+	statements []string
+	args       [][]driver.Value
+	err        error
+	respond    func(query string) (columns []string, rows [][]driver.Value)
+	affected   func(query string) int64
+}
+
+func (r *recorder) result(query string) driver.Result {
+	if r.affected == nil {
+		return driver.RowsAffected(0)
+	}
+	return driver.RowsAffected(r.affected(query))
+}
+
+func (r *recorder) rows(query string) driver.Rows {
+	if r.respond == nil {
+		return &cannedRows{}
+	}
+	columns, rows := r.respond(query)
+	return &cannedRows{columns: columns, rows: rows}
+}
+
+func (r *recorder) record(query string, args []driver.Value) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = append(r.statements, query)
+	r.args = append(r.args, args)
+	return r.err
+}
+
+func namedValues(named []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(named))
+	for i, value := range named {
+		values[i] = value.Value
+	}
+	return values
+}
+
+// Statements returns the statements received so far
+func (r *recorder) Statements() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.statements...)
+}
+
+// Find returns the arguments of every received statement containing fragment
+func (r *recorder) Find(fragment string) [][]driver.Value {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found [][]driver.Value
+	for i, statement := range r.statements {
+		if strings.Contains(statement, fragment) {
+			found = append(found, r.args[i])
+		}
+	}
+	return found
+}
+
+func (r *recorder) Connect(context.Context) (driver.Conn, error) { return &recorderConn{r}, nil }
+func (r *recorder) Driver() driver.Driver                        { return r }
+func (r *recorder) Open(string) (driver.Conn, error)             { return &recorderConn{r}, nil }
+
+type recorderConn struct{ r *recorder }
+
+func (c *recorderConn) Prepare(query string) (driver.Stmt, error) {
+	return &recorderStmt{c.r, query}, nil
+}
+func (c *recorderConn) Close() error              { return nil }
+func (c *recorderConn) Begin() (driver.Tx, error) { return recorderTx{}, nil }
+
+func (c *recorderConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := c.r.record(query, namedValues(args)); err != nil {
+		return nil, err
+	}
+	return c.r.result(query), nil
+}
+
+func (c *recorderConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := c.r.record(query, namedValues(args)); err != nil {
+		return nil, err
+	}
+	return c.r.rows(query), nil
+}
+
+type recorderStmt struct {
+	r     *recorder
+	query string
+}
+
+func (s *recorderStmt) Close() error  { return nil }
+func (s *recorderStmt) NumInput() int { return -1 }
+
+func (s *recorderStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if err := s.r.record(s.query, args); err != nil {
+		return nil, err
+	}
+	return s.r.result(s.query), nil
+}
+
+func (s *recorderStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if err := s.r.record(s.query, args); err != nil {
+		return nil, err
+	}
+	return s.r.rows(s.query), nil
+}
+
+type recorderTx struct{}
+
+func (recorderTx) Commit() error   { return nil }
+func (recorderTx) Rollback() error { return nil }
+
+type cannedRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *cannedRows) Columns() []string { return r.columns }
+func (r *cannedRows) Close() error      { return nil }
+
+func (r *cannedRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// newRecordingDB opens a gorm DB backed by a recorder instead of MySQL
+func newRecordingDB(t *testing.T) (*gorm.DB, *recorder) {
+	t.Helper()
+	rec := &recorder{}
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      sql.OpenDB(rec),
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{TranslateError: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("open recording db: %v", err)
+	}
+	return db, rec
+}
+
+// errRecorderDown is used by tests that simulate a database outage
+var errRecorderDown = errors.New("connection refused")
+
+// newTestApp returns a Fiber app configured like main.go
+func newTestApp() *fiber.App {
+	return fiber.New(fiber.Config{ErrorHandler: utils.ErrorHandler})
+}
+
+// doRequest sends a request to app and returns the status code and body
+func doRequest(t *testing.T, app *fiber.App, method, target, body string) (int, string) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, target, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return resp.StatusCode, string(data)
+}
+
+// doUpload posts content as the multipart file field and returns the status code and body
+func doUpload(t *testing.T, app *fiber.App, target, field, filename string, content []byte) (int, string) {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile(field, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := part.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := form.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(fiber.MethodPost, target, &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("POST %s: %v", target, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return resp.StatusCode, string(data)
+}
+
+// assertUnauthorized checks that a request with a wrong admin key gets a 401
+// and never reaches the database
+func assertUnauthorized(t *testing.T, app *fiber.App, rec *recorder, method, target, body string) {
+	t.Helper()
+	separator := "?"
+	if strings.Contains(target, "?") {
+		separator = "&"
+	}
+	for _, key := range []string{"", separator + "adminKey=wrong"} {
+		status, respBody := doRequest(t, app, method, target+key, body)
+		if status != fiber.StatusUnauthorized {
+			t.Errorf("%s %s%s: status = %d, want 401 (body %s)", method, target, key, status, respBody)
+		}
+		if rec != nil {
+			if statements := rec.Statements(); len(statements) != 0 {
+				t.Errorf("%s %s%s ran %d statements without auth: %v", method, target, key, len(statements), statements)
+			}
+		}
+	}
+}
+
+// useTestAdminKey points handlers that read config.GetAppConfig at testAdminKey
+func useTestAdminKey(t *testing.T) {
+	t.Helper()
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("ADMIN_KEY", testAdminKey)
+}
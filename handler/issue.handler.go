@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	IssueStatusOpen     = "open"
+	IssueStatusResolved = "resolved"
+	IssueStatusIgnored  = "ignored"
+)
+
+type ErrorIssue struct {
+	ID                 int        `json:"id"`
+	Fingerprint        string     `json:"fingerprint"`
+	Title              string     `json:"title"`
+	Status             string     `json:"status"`
+	Occurrences        int        `json:"occurrences"`
+	FirstSeen          time.Time  `json:"first_seen"`
+	LastSeen           time.Time  `json:"last_seen"`
+	ResolvedAt         *time.Time `json:"resolved_at"`
+	ResolvedInVersion  *string    `json:"resolved_in_version"`
+	IsRegression       bool       `json:"is_regression"`
+	RegressedAt        *time.Time `json:"regressed_at"`
+	RegressedInVersion *string    `json:"regressed_in_version"`
+}
+
+// recordErrorIssue attaches an error log to its issue, creating the issue on first
+// sight and reopening it as a regression if it had been resolved. It must run inside
+// a transaction so the row lock taken here covers the whole update.
+func recordErrorIssue(tx *gorm.DB, errorLog ErrorLog, fingerprint string) (ErrorIssue, bool, error) {
+	var issue ErrorIssue
+
+	// Make sure the issue row exists, then lock it so concurrent logs for the same
+	// fingerprint are counted one after another
+	result := tx.Exec(`
+		INSERT IGNORE INTO app_err_issues (fingerprint, title, status, occurrences, first_seen, last_seen)
+		VALUES (?, ?, ?, 0, ?, ?)`,
+		fingerprint, utils.ErrorSummary(errorLog.Log), IssueStatusOpen, errorLog.Date, errorLog.Date,
+	)
+	if result.Error != nil {
+		return issue, false, result.Error
+	}
+	isNew := result.RowsAffected > 0
+
+	if err := tx.Raw("SELECT * FROM app_err_issues WHERE fingerprint = ? FOR UPDATE", fingerprint).
+		Scan(&issue).Error; err != nil {
+		return issue, false, err
+	}
+
+	issue.Occurrences++
+	if errorLog.Date.After(issue.LastSeen) {
+		issue.LastSeen = errorLog.Date
+	}
+
+	// A resolved issue that shows up again is a regression; ignored issues stay ignored
+	if issue.Status == IssueStatusResolved {
+		now := time.Now()
+		issue.Status = IssueStatusOpen
+		issue.IsRegression = true
+		issue.RegressedAt = &now
+		issue.RegressedInVersion = nil
+		if errorLog.AppVersion != "" {
+			issue.RegressedInVersion = &errorLog.AppVersion
+		}
+		log.Printf("🟠 Issue #%d regressed in version %q", issue.ID, errorLog.AppVersion)
+	}
+
+	err := tx.Exec(`
+		UPDATE app_err_issues
+		SET occurrences = ?, last_seen = ?, status = ?, is_regression = ?, regressed_at = ?, regressed_in_version = ?
+		WHERE id = ?`,
+		issue.Occurrences, issue.LastSeen, issue.Status, issue.IsRegression, issue.RegressedAt, issue.RegressedInVersion, issue.ID,
+	).Error
+
+	return issue, isNew, err
+}
+
+// GetErrorIssues retrieves grouped error issues with optional status filter
+func GetErrorIssues(db *gorm.DB, appConfig config.AppConfig) fiber.Handler {
+	log.Println("🟢 GET: GetErrorIssues handler called")
+	return func(c *fiber.Ctx) error {
+		if err := utils.ValidateAdminKey(c, appConfig); err != nil {
+			return err
+		}
+
+		page := c.QueryInt("page", 1)
+		limit := c.QueryInt("limit", 100)
+		status := c.Query("status")
+
+		if page < 1 {
+			page = 1
+		}
+		if limit < 1 || limit > 500 {
+			limit = 100
+		}
+		offset := (page - 1) * limit
+
+		whereClause := "1=1"
+		params := []interface{}{}
+
+		if status != "" {
+			if !isValidIssueStatus(status) {
+				return c.Status(400).JSON(fiber.Map{
+					"status": config.AppMessages.ErrorLog.InvalidIssueStatus,
+				})
+			}
+			whereClause = "status = ?"
+			params = append(params, status)
+		}
+
+		var total int64
+		if err := db.Raw("SELECT COUNT(*) FROM app_err_issues WHERE "+whereClause, params...).
+			Scan(&total).Error; err != nil {
+			log.Printf("🔴 Error while counting error issues: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"status": config.AppMessages.ErrorLog.OperationUnsuccessful,
+			})
+		}
+
+		var issues []ErrorIssue
+		query := `
+			SELECT * FROM app_err_issues
+			WHERE ` + whereClause + `
+			ORDER BY last_seen DESC
+			LIMIT ? OFFSET ?`
+		params = append(params, limit, offset)
+
+		if err := db.Raw(query, params...).Scan(&issues).Error; err != nil {
+			log.Printf("🔴 Error while fetching error issues: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"status": config.AppMessages.ErrorLog.OperationUnsuccessful,
+			})
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"issues":       issues,
+			"total":        total,
+			"current_page": page,
+			"limit":        limit,
+			"total_pages":  (total + int64(limit) - 1) / int64(limit),
+			"status":       config.AppMessages.ErrorLog.IssuesFetchSuccess,
+		})
+	}
+}
+
+// GetErrorIssue retrieves a single issue along with its most recent logs
+func GetErrorIssue(db *gorm.DB, appConfig config.AppConfig) fiber.Handler {
+	log.Println("🟢 GET: GetErrorIssue handler called")
+	return func(c *fiber.Ctx) error {
+		if err := utils.ValidateAdminKey(c, appConfig); err != nil {
+			return err
+		}
+
+		id, err := c.ParamsInt("id")
+		if err != nil || id < 1 {
+			return c.Status(400).JSON(fiber.Map{
+				"status": config.AppMessages.ErrorLog.BadRequest,
+			})
+		}
+
+		issue, err := findErrorIssue(db, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(404).JSON(fiber.Map{
+				"status": config.AppMessages.ErrorLog.IssueNotFound,
+			})
+		}
+		if err != nil {
+			log.Printf("🔴 Error while fetching error issue %d: %v", id, err)
+			return c.Status(500).JSON(fiber.Map{
+				"status": config.AppMessages.ErrorLog.OperationUnsuccessful,
+			})
+		}
+
+		var recentLogs []map[string]interface{}
+		if err := db.Raw("SELECT * FROM app_err_logs WHERE fingerprint = ? ORDER BY date DESC LIMIT 50", issue.Fingerprint).
+			Scan(&recentLogs).Error; err != nil {
+			log.Printf("🔴 Error while fetching logs for issue %d: %v", id, err)
+			return c.Status(500).JSON(fiber.Map{
+				"status": config.AppMessages.ErrorLog.OperationUnsuccessful,
+			})
+		}
+		decodeErrorLogRows(recentLogs)
+
+		return c.Status(200).JSON(fiber.Map{
+			"issue":       issue,
+			"recent_logs": recentLogs,
+			"status":      config.AppMessages.ErrorLog.IssuesFetchSuccess,
+		})
+	}
+}
+
+// UpdateErrorIssueStatus marks an issue as resolved, ignored or open again
+func UpdateErrorIssueStatus(db *gorm.DB, appConfig config.AppConfig) fiber.Handler {
+	log.Println("🟠 PATCH: UpdateErrorIssueStatus handler called")
+	return func(c *fiber.Ctx) error {
+		if err := utils.ValidateAdminKey(c, appConfig); err != nil {
+			return err
+		}
+
+		id, err := c.ParamsInt("id")
+		if err != nil || id < 1 {
+			return c.Status(400).JSON(fiber.Map{
+				"status": config.AppMessages.ErrorLog.BadRequest,
+			})
+		}
+
+		var body struct {
+			Status  string `json:"status"`
+			Version string `json:"version"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"status": config.AppMessages.ErrorLog.BadRequest,
+			})
+		}
+		if !isValidIssueStatus(body.Status) {
+			return c.Status(400).JSON(fiber.Map{
+				"status": config.AppMessages.ErrorLog.InvalidIssueStatus,
+			})
+		}
+
+		var query string
+		var params []interface{}
+
+		switch body.Status {
+		case IssueStatusResolved:
+			var version interface{}
+			if body.Version != "" {
+				version = body.Version
+			}
+			query = "UPDATE app_err_issues SET status = ?, resolved_at = ?, resolved_in_version = ? WHERE id = ?"
+			params = []interface{}{body.Status, time.Now(), version, id}
+		case IssueStatusIgnored:
+			query = "UPDATE app_err_issues SET status = ? WHERE id = ?"
+			params = []interface{}{body.Status, id}
+		default:
+			// Reopening by hand is not a regression, so clear the regression markers
+			query = `
+				UPDATE app_err_issues
+				SET status = ?, resolved_at = NULL, resolved_in_version = NULL,
+					is_regression = FALSE, regressed_at = NULL, regressed_in_version = NULL
+				WHERE id = ?`
+			params = []interface{}{body.Status, id}
+		}
+
+		result := db.Exec(query, params...)
+		if result.Error != nil {
+			log.Printf("🔴 Error while updating error issue %d: %v", id, result.Error)
+			return c.Status(500).JSON(fiber.Map{
+				"status": config.AppMessages.ErrorLog.OperationUnsuccessful,
+			})
+		}
+
+		issue, err := findErrorIssue(db, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(404).JSON(fiber.Map{
+				"status": config.AppMessages.ErrorLog.IssueNotFound,
+			})
+		}
+		if err != nil {
+			log.Printf("🔴 Error while fetching error issue %d: %v", id, err)
+			return c.Status(500).JSON(fiber.Map{
+				"status": config.AppMessages.ErrorLog.OperationUnsuccessful,
+			})
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"issue":  issue,
+			"status": config.AppMessages.ErrorLog.IssueUpdateSuccess,
+		})
+	}
+}
+
+func findErrorIssue(db *gorm.DB, id int) (ErrorIssue, error) {
+	var issues []ErrorIssue
+	if err := db.Raw("SELECT * FROM app_err_issues WHERE id = ?", id).Scan(&issues).Error; err != nil {
+		return ErrorIssue{}, err
+	}
+	if len(issues) == 0 {
+		return ErrorIssue{}, gorm.ErrRecordNotFound
+	}
+	return issues[0], nil
+}
+
+func isValidIssueStatus(status string) bool {
+	return status == IssueStatusOpen || status == IssueStatusResolved || status == IssueStatusIgnored
+}
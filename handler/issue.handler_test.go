@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+var issueColumns = []string{"id", "fingerprint", "title", "status", "occurrences", "first_seen", "last_seen",
+	"resolved_at", "resolved_in_version", "is_regression", "regressed_at", "regressed_in_version"}
+
+// issueRow is a stored app_err_issues row with the given status
+func issueRow(status string, lastSeen time.Time) []driver.Value {
+	return []driver.Value{int64(7), "abc123", "TypeError: x is undefined", status, int64(3),
+		lastSeen.Add(-time.Hour), lastSeen, nil, nil, false, nil, nil}
+}
+
+func TestIssueHandlersRequireAdminKey(t *testing.T) {
+	db, rec := newRecordingDB(t)
+	app := newTestApp()
+	app.Get("/errors/issues", GetErrorIssues(db, testAppConfig))
+	app.Get("/errors/issues/:id", GetErrorIssue(db, testAppConfig))
+	app.Patch("/errors/issues/:id", UpdateErrorIssueStatus(db, testAppConfig))
+
+	assertUnauthorized(t, app, rec, fiber.MethodGet, "/errors/issues", "")
+	assertUnauthorized(t, app, rec, fiber.MethodGet, "/errors/issues/1", "")
+	assertUnauthorized(t, app, rec, fiber.MethodPatch, "/errors/issues/1", `{"status":"resolved"}`)
+}
+
+func TestRecordErrorIssue(t *testing.T) {
+	lastSeen := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		inserted   int64
+		stored     string
+		logDate    time.Time
+		isNew      bool
+		status     string
+		regression bool
+		lastSeen   time.Time
+	}{
+		{"new issue", 1, IssueStatusOpen, lastSeen.Add(time.Minute), true, IssueStatusOpen, false, lastSeen.Add(time.Minute)},
+		{"open issue", 0, IssueStatusOpen, lastSeen.Add(time.Minute), false, IssueStatusOpen, false, lastSeen.Add(time.Minute)},
+		{"late log keeps last seen", 0, IssueStatusOpen, lastSeen.Add(-time.Minute), false, IssueStatusOpen, false, lastSeen},
+		{"resolved issue regresses", 0, IssueStatusResolved, lastSeen.Add(time.Minute), false, IssueStatusOpen, true, lastSeen.Add(time.Minute)},
+		{"ignored issue stays ignored", 0, IssueStatusIgnored, lastSeen.Add(time.Minute), false, IssueStatusIgnored, false, lastSeen.Add(time.Minute)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := newRecordingDB(t)
+			rec.respond = func(string) ([]string, [][]driver.Value) {
+				return issueColumns, [][]driver.Value{issueRow(tt.stored, lastSeen)}
+			}
+			rec.affected = func(query string) int64 {
+				if strings.Contains(query, "INSERT IGNORE") {
+					return tt.inserted
+				}
+				return 1
+			}
+
+			var issue ErrorIssue
+			var isNew bool
+			err := db.Transaction(func(tx *gorm.DB) error {
+				var err error
+				issue, isNew, err = recordErrorIssue(tx, ErrorLog{Date: tt.logDate, Log: "TypeError: x is undefined\n  at main.js:1", AppVersion: "2.1.0"}, "abc123")
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if isNew != tt.isNew || issue.Status != tt.status || issue.IsRegression != tt.regression {
+				t.Errorf("got new %v status %q regression %v, want %v %q %v", isNew, issue.Status, issue.IsRegression, tt.isNew, tt.status, tt.regression)
+			}
+			if issue.Occurrences != 4 || !issue.LastSeen.Equal(tt.lastSeen) {
+				t.Errorf("occurrences %d last seen %v, want 4 and %v", issue.Occurrences, issue.LastSeen, tt.lastSeen)
+			}
+			if tt.regression && (issue.RegressedInVersion == nil || *issue.RegressedInVersion != "2.1.0") {
+				t.Errorf("regressed in %v, want 2.1.0", issue.RegressedInVersion)
+			}
+			if inserts := rec.Find("INSERT IGNORE INTO app_err_issues"); len(inserts) != 1 || inserts[0][1] != "TypeError: x is undefined" {
+				t.Errorf("inserts = %v, want one titled with the first log line", inserts)
+			}
+			if updates := rec.Find("UPDATE app_err_issues"); len(updates) != 1 {
+				t.Errorf("updates = %v, want one", updates)
+			}
+		})
+	}
+}
+
+func TestGetErrorIssue(t *testing.T) {
+	lastSeen := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("decodes the recent logs", func(t *testing.T) {
+		db, rec := newRecordingDB(t)
+		rec.respond = func(query string) ([]string, [][]driver.Value) {
+			if strings.Contains(query, "FROM app_err_logs") {
+				return []string{"id", "log", "stack_trace", "tags"}, [][]driver.Value{
+					{int64(1), "TypeError", `[{"file":"main.js","line":1}]`, `{"screen":"home"}`},
+					{int64(2), "TypeError", nil, nil},
+				}
+			}
+			return issueColumns, [][]driver.Value{issueRow(IssueStatusOpen, lastSeen)}
+		}
+		app := newTestApp()
+		app.Get("/errors/issues/:id", GetErrorIssue(db, testAppConfig))
+
+		status, body := doRequest(t, app, fiber.MethodGet, "/errors/issues/7?adminKey="+testAdminKey, "")
+		if status != fiber.StatusOK {
+			t.Fatalf("status = %d (body %s)", status, body)
+		}
+		var response struct {
+			Issue      ErrorIssue               `json:"issue"`
+			RecentLogs []map[string]interface{} `json:"recent_logs"`
+		}
+		if err := json.Unmarshal([]byte(body), &response); err != nil {
+			t.Fatal(err)
+		}
+		if response.Issue.Fingerprint != "abc123" || len(response.RecentLogs) != 2 {
+			t.Fatalf("response = %s", body)
+		}
+		want := []interface{}{map[string]interface{}{"file": "main.js", "line": float64(1)}}
+		if got := response.RecentLogs[0]["stack_trace"]; !reflect.DeepEqual(got, want) {
+			t.Errorf("stack_trace = %#v, want %#v", got, want)
+		}
+		if got := response.RecentLogs[0]["tags"]; !reflect.DeepEqual(got, map[string]interface{}{"screen": "home"}) {
+			t.Errorf("tags = %#v", got)
+		}
+		if queries := rec.Find("FROM app_err_logs WHERE fingerprint = ?"); len(queries) != 1 || queries[0][0] != "abc123" {
+			t.Errorf("log queries = %v", queries)
+		}
+	})
+
+	for target, want := range map[string]int{"/errors/issues/0": fiber.StatusBadRequest, "/errors/issues/8": fiber.StatusNotFound} {
+		db, _ := newRecordingDB(t)
+		app := newTestApp()
+		app.Get("/errors/issues/:id", GetErrorIssue(db, testAppConfig))
+
+		if status, body := doRequest(t, app, fiber.MethodGet, target+"?adminKey="+testAdminKey, ""); status != want {
+			t.Errorf("%s: status = %d, want %d (body %s)", target, status, want, body)
+		}
+	}
+}
+
+func TestGetErrorIssuesFiltersByStatus(t *testing.T) {
+	tests := []struct {
+		query  string
+		status int
+		args   []driver.Value
+	}{
+		{"", fiber.StatusOK, []driver.Value{int64(100), int64(0)}},
+		{"&status=resolved&limit=20&page=3", fiber.StatusOK, []driver.Value{"resolved", int64(20), int64(40)}},
+		{"&status=closed", fiber.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		db, rec := newRecordingDB(t)
+		app := newTestApp()
+		app.Get("/errors/issues", GetErrorIssues(db, testAppConfig))
+
+		status, body := doRequest(t, app, fiber.MethodGet, "/errors/issues?adminKey="+testAdminKey+tt.query, "")
+		if status != tt.status {
+			t.Errorf("%q: status = %d, want %d (body %s)", tt.query, status, tt.status, body)
+			continue
+		}
+		queries := rec.Find("ORDER BY last_seen DESC")
+		if tt.args == nil {
+			if len(queries) != 0 {
+				t.Errorf("%q: queried %v", tt.query, queries)
+			}
+			continue
+		}
+		if len(queries) != 1 || !reflect.DeepEqual(queries[0], tt.args) {
+			t.Errorf("%q: query args = %v, want %v", tt.query, queries, tt.args)
+		}
+	}
+}
+
+func TestUpdateErrorIssueStatus(t *testing.T) {
+	tests := []struct {
+		body   string
+		status int
+		update string
+	}{
+		{`{"status":"resolved","version":"2.1.0"}`, fiber.StatusOK, "SET status = ?, resolved_at = ?, resolved_in_version = ?"},
+		{`{"status":"ignored"}`, fiber.StatusOK, "UPDATE app_err_issues SET status = ? WHERE id = ?"},
+		{`{"status":"open"}`, fiber.StatusOK, "is_regression = FALSE"},
+		{`{"status":"closed"}`, fiber.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		db, rec := newRecordingDB(t)
+		rec.respond = func(string) ([]string, [][]driver.Value) {
+			return issueColumns, [][]driver.Value{issueRow(IssueStatusOpen, time.Now())}
+		}
+		app := newTestApp()
+		app.Patch("/errors/issues/:id", UpdateErrorIssueStatus(db, testAppConfig))
+
+		status, body := doRequest(t, app, fiber.MethodPatch, "/errors/issues/7?adminKey="+testAdminKey, tt.body)
+		if status != tt.status {
+			t.Errorf("%s: status = %d, want %d (body %s)", tt.body, status, tt.status, body)
+			continue
+		}
+		updates := rec.Find("UPDATE app_err_issues")
+		if tt.update == "" {
+			if len(updates) != 0 {
+				t.Errorf("%s: updated %v", tt.body, updates)
+			}
+			continue
+		}
+		if len(updates) != 1 || len(rec.Find(tt.update)) != 1 {
+			t.Errorf("%s: statements = %v, want %q", tt.body, rec.Statements(), tt.update)
+		}
+	}
+
+	// The version is stored when given
+	db, rec := newRecordingDB(t)
+	rec.respond = func(string) ([]string, [][]driver.Value) {
+		return issueColumns, [][]driver.Value{issueRow(IssueStatusResolved, time.Now())}
+	}
+	app := newTestApp()
+	app.Patch("/errors/issues/:id", UpdateErrorIssueStatus(db, testAppConfig))
+	doRequest(t, app, fiber.MethodPatch, "/errors/issues/7?adminKey="+testAdminKey, `{"status":"resolved","version":"2.1.0"}`)
+	if updates := rec.Find("resolved_in_version = ?"); len(updates) != 1 || updates[0][2] != "2.1.0" || updates[0][3] != int64(7) {
+		t.Errorf("resolve args = %v", updates)
+	}
+}
@@ -1,9 +1,14 @@
 package handler
 
 import (
+	"fmt"
 	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/utils"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
@@ -13,7 +18,28 @@ type MissedWord struct {
 	Count int    `json:"count"`
 }
 
-// GetMissedWords handles fetching all missed words with pagination
+// Platforms a missed word can come from, the same ones the daily report tracks
+var missedWordPlatforms = map[string]bool{"bot": true, "app": true}
+
+const (
+	maxMissedWordSessionLen     = 64
+	maxMissedWordPreviousLen    = 1000
+	maxMissedWordRawLen         = 1024
+	missedWordPlatformUntracked = "unknown"
+)
+
+// Supported missed word orderings, "recent" keeps the original newest first listing
+var missedWordSorts = map[string]string{
+	"recent":     "id DESC",
+	"frequency":  "count DESC, last_seen DESC, id DESC",
+	"last_seen":  "last_seen DESC, id DESC",
+	"first_seen": "first_seen ASC, id ASC",
+}
+
+// GetMissedWords handles fetching the missed word backlog with pagination. Words that were mapped
+// to a keyword are hidden unless includeResolved=true is passed. Pass sort=frequency to list the
+// most often missed words first, and platform or session_id to only list words missed in that
+// context. Each word carries its occurrences per platform.
 func GetMissedWords(db *gorm.DB) fiber.Handler {
 	log.Println("🟢 GET: GetMissedWords handler called")
 	return func(c *fiber.Ctx) error {
@@ -21,6 +47,16 @@ func GetMissedWords(db *gorm.DB) fiber.Handler {
 		page := c.QueryInt("page", 1)
 		limit := c.QueryInt("limit", 500)
 		search := c.Query("search", "")
+		sort := c.Query("sort", "recent")
+		platform := c.Query("platform")
+		sessionID := c.Query("session_id")
+
+		orderBy, ok := missedWordSorts[sort]
+		if !ok {
+			return c.Status(400).JSON(fiber.Map{
+				"status": config.AppMessages.MissedWord.InvalidSort,
+			})
+		}
 
 		// Prevent negative values
 		if page < 1 {
@@ -36,10 +72,26 @@ func GetMissedWords(db *gorm.DB) fiber.Handler {
 		whereClause := "1=1"
 		params := []interface{}{}
 
+		if !c.QueryBool("includeResolved") {
+			whereClause = "resolved_at IS NULL"
+		}
 		if search != "" {
-			whereClause = "missed_words LIKE ?"
+			whereClause += " AND (missed_words LIKE ? OR normalized_word LIKE ?)"
 			searchPattern := "%" + search + "%"
-			params = append(params, searchPattern)
+			params = append(params, searchPattern, searchPattern)
+		}
+		if platform != "" {
+			if !missedWordPlatforms[platform] {
+				return c.Status(400).JSON(fiber.Map{
+					"status": config.AppMessages.MissedWord.InvalidPlatform,
+				})
+			}
+			whereClause += " AND id IN (SELECT missed_word_id FROM missed_word_events WHERE platform = ?)"
+			params = append(params, platform)
+		}
+		if sessionID != "" {
+			whereClause += " AND id IN (SELECT missed_word_id FROM missed_word_events WHERE session_id = ?)"
+			params = append(params, sessionID)
 		}
 
 		var missedWords []map[string]interface{}
@@ -54,11 +106,32 @@ func GetMissedWords(db *gorm.DB) fiber.Handler {
 			})
 		}
 
+		// Occurrences per platform across every matching word
+		var platformRows []struct {
+			Platform string
+			Count    int64
+		}
+		platformQuery := `
+			SELECT COALESCE(platform, '` + missedWordPlatformUntracked + `') AS platform, COUNT(*) AS count
+			FROM missed_word_events
+			WHERE missed_word_id IN (SELECT id FROM missed_words_table WHERE ` + whereClause + `)
+			GROUP BY COALESCE(platform, '` + missedWordPlatformUntracked + `')`
+		if err := db.Raw(platformQuery, params...).Scan(&platformRows).Error; err != nil {
+			log.Printf("🔴 Error while counting missed word platforms: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"status": config.AppMessages.MissedWord.FetchError,
+			})
+		}
+		platformCounts := map[string]int64{}
+		for _, row := range platformRows {
+			platformCounts[row.Platform] = row.Count
+		}
+
 		// Get paginated and filtered results
 		query := `
 			SELECT * FROM missed_words_table 
 			WHERE ` + whereClause + `
-			ORDER BY id DESC 
+			ORDER BY ` + orderBy + `
 			LIMIT ? OFFSET ?
 		`
 		// Add pagination parameters
@@ -71,25 +144,77 @@ func GetMissedWords(db *gorm.DB) fiber.Handler {
 			})
 		}
 
+		// Occurrences per platform for each word on this page
+		if len(missedWords) > 0 {
+			ids := make([]interface{}, 0, len(missedWords))
+			for _, word := range missedWords {
+				ids = append(ids, word["id"])
+			}
+
+			var wordPlatformRows []struct {
+				MissedWordID int64
+				Platform     string
+				Count        int64
+			}
+			if err := db.Raw(`
+				SELECT missed_word_id, COALESCE(platform, '`+missedWordPlatformUntracked+`') AS platform, COUNT(*) AS count
+				FROM missed_word_events
+				WHERE missed_word_id IN ?
+				GROUP BY missed_word_id, COALESCE(platform, '`+missedWordPlatformUntracked+`')`, ids,
+			).Scan(&wordPlatformRows).Error; err != nil {
+				log.Printf("🔴 Error while counting missed word platforms: %v", err)
+				return c.Status(500).JSON(fiber.Map{
+					"status": config.AppMessages.MissedWord.FetchError,
+				})
+			}
+
+			byWord := map[string]map[string]int64{}
+			for _, row := range wordPlatformRows {
+				key := fmt.Sprint(row.MissedWordID)
+				if byWord[key] == nil {
+					byWord[key] = map[string]int64{}
+				}
+				byWord[key][row.Platform] = row.Count
+			}
+			for _, word := range missedWords {
+				counts, ok := byWord[fmt.Sprint(word["id"])]
+				if !ok {
+					counts = map[string]int64{}
+				}
+				word["platforms"] = counts
+			}
+		}
+
 		return c.Status(200).JSON(fiber.Map{
-			"missed_words": missedWords,
+			"missed_words":    missedWords,
+			"platform_counts": platformCounts,
 			"pagination": fiber.Map{
 				"current_page": page,
 				"limit":        limit,
 				"total":        total,
 				"total_pages":  (total + int64(limit) - 1) / int64(limit),
 				"search":       search,
+				"sort":         sort,
+				"platform":     platform,
+				"session_id":   sessionID,
 			},
 		})
 	}
 }
 
-// CreateMissedWord handles creating or updating missed word entries
+// CreateMissedWord handles recording a missed word. Words are run through the text normalisation
+// pipeline and aggregated by their normalised form, so a repeated word bumps the count and last seen
+// time of its existing entry. The raw text is kept in missed_words. Optionally the platform (bot or
+// app), an anonymous session_id and the previous_message can be sent; each occurrence is stored with
+// that context.
 func CreateMissedWord(db *gorm.DB) fiber.Handler {
 	log.Println("🔵 POST: CreateMissedWord handler called")
 	return func(c *fiber.Ctx) error {
 		word := struct {
-			Word string `json:"word"`
+			Word            string `json:"word"`
+			Platform        string `json:"platform"`
+			SessionID       string `json:"session_id"`
+			PreviousMessage string `json:"previous_message"`
 		}{}
 
 		if err := c.BodyParser(&word); err != nil {
@@ -98,13 +223,77 @@ func CreateMissedWord(db *gorm.DB) fiber.Handler {
 			})
 		}
 
-		// Simple insert query without count column
-		query := `
-			INSERT INTO missed_words_table (missed_words) 
-			VALUES (?)
-		`
+		// The raw word is stored as sent, so it must fit missed_words and raw_word as well
+		normalized := utils.NormalizeMissedWord(word.Word)
+		if normalized == "" || utf8.RuneCountInString(normalized) > utils.MaxMissedWordLen ||
+			utf8.RuneCountInString(word.Word) > maxMissedWordRawLen {
+			return c.Status(400).JSON(fiber.Map{
+				"status": config.AppMessages.MissedWord.BadRequest,
+			})
+		}
 
-		if err := db.Exec(query, word.Word).Error; err != nil {
+		word.Platform = strings.ToLower(strings.TrimSpace(word.Platform))
+		word.SessionID = strings.TrimSpace(word.SessionID)
+		word.PreviousMessage = strings.TrimSpace(word.PreviousMessage)
+		if word.Platform != "" && !missedWordPlatforms[word.Platform] {
+			return c.Status(400).JSON(fiber.Map{
+				"status": config.AppMessages.MissedWord.InvalidPlatform,
+			})
+		}
+		// Session ids must stay anonymous, an email would tie the query to a person
+		if len(word.SessionID) > maxMissedWordSessionLen || utils.ValidateEmail(word.SessionID) {
+			return c.Status(400).JSON(fiber.Map{
+				"status": config.AppMessages.MissedWord.InvalidSession,
+			})
+		}
+		if utf8.RuneCountInString(word.PreviousMessage) > maxMissedWordPreviousLen {
+			word.PreviousMessage = string([]rune(word.PreviousMessage)[:maxMissedWordPreviousLen])
+		}
+
+		var entry struct {
+			ID        int64
+			Count     int
+			FirstSeen *time.Time
+		}
+		now := time.Now()
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// The raw word is kept as first seen, later spellings only add to the count
+			if err := tx.Exec(`
+				INSERT INTO missed_words_table (missed_words, normalized_word, count, first_seen, last_seen)
+				VALUES (?, ?, 1, ?, ?)
+				ON DUPLICATE KEY UPDATE count = count + 1, last_seen = ?`,
+				word.Word, normalized, now, now, now,
+			).Error; err != nil {
+				return err
+			}
+			// A word that was mapped before it was ever missed starts out resolved
+			if err := tx.Exec(`
+				UPDATE missed_words_table SET resolved_at = ?
+				WHERE normalized_word = ? AND resolved_at IS NULL
+				AND EXISTS (SELECT 1 FROM missed_word_mappings WHERE normalized_word = ?)`,
+				now, normalized, normalized,
+			).Error; err != nil {
+				return err
+			}
+			if err := tx.Raw("SELECT id, count, first_seen FROM missed_words_table WHERE normalized_word = ?", normalized).
+				Scan(&entry).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(`
+				INSERT INTO missed_word_daily (missed_word_id, day, count) VALUES (?, ?, 1)
+				ON DUPLICATE KEY UPDATE count = count + 1`,
+				entry.ID, now.Format(time.DateOnly),
+			).Error; err != nil {
+				return err
+			}
+			return tx.Exec(`
+				INSERT INTO missed_word_events (missed_word_id, raw_word, platform, session_id, previous_message, created_at)
+				VALUES (?, ?, ?, ?, ?, ?)`,
+				entry.ID, word.Word, utils.NullIfEmpty(word.Platform), utils.NullIfEmpty(word.SessionID),
+				utils.NullIfEmpty(word.PreviousMessage), now,
+			).Error
+		})
+		if err != nil {
 			log.Printf("🔴 Error while inserting missed word: %v", err)
 			return c.Status(500).JSON(fiber.Map{
 				"status": config.AppMessages.MissedWord.OperationUnsuccessful,
@@ -112,8 +301,14 @@ func CreateMissedWord(db *gorm.DB) fiber.Handler {
 		}
 
 		return c.Status(200).JSON(fiber.Map{
-			"word":   word.Word,
-			"status": config.AppMessages.MissedWord.InsertSuccess,
+			"id":              entry.ID,
+			"word":            word.Word,
+			"normalized_word": normalized,
+			"platform":        word.Platform,
+			"count":           entry.Count,
+			"first_seen":      entry.FirstSeen,
+			"last_seen":       now,
+			"status":          config.AppMessages.MissedWord.InsertSuccess,
 		})
 	}
 }
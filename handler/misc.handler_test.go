@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// storedMissedWordRows serves word 7 to the lookup after a missed word is recorded
+func storedMissedWordRows(query string) ([]string, [][]driver.Value) {
+	if strings.Contains(query, "SELECT id, count, first_seen FROM missed_words_table") {
+		return []string{"id", "count", "first_seen"}, [][]driver.Value{{int64(7), int64(1), nil}}
+	}
+	return nil, nil
+}
+
+func TestCreateMissedWord(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		status     int
+		stored     string
+		normalized string
+	}{
+		{"new word", `{"word":"  Physics!! "}`, fiber.StatusOK, "  Physics!! ", "physics"},
+		{"raw word at the limit", `{"word":"phy` + strings.Repeat("!", maxMissedWordRawLen-3) + `"}`, fiber.StatusOK,
+			"phy" + strings.Repeat("!", maxMissedWordRawLen-3), "phy"},
+		{"raw word over the limit", `{"word":"phy` + strings.Repeat("!", maxMissedWordRawLen-2) + `"}`, fiber.StatusBadRequest, "", ""},
+		{"nothing left after normalising", `{"word":"!!!"}`, fiber.StatusBadRequest, "", ""},
+		{"not json", `word`, fiber.StatusBadRequest, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := newRecordingDB(t)
+			rec.respond = storedMissedWordRows
+			app := newTestApp()
+			app.Post("/missed", CreateMissedWord(db))
+
+			status, body := doRequest(t, app, fiber.MethodPost, "/missed", tt.body)
+			if status != tt.status {
+				t.Fatalf("status = %d, want %d (body %s)", status, tt.status, body)
+			}
+			if tt.status != fiber.StatusOK {
+				if statements := rec.Statements(); len(statements) != 0 {
+					t.Errorf("rejected word ran %v", statements)
+				}
+				return
+			}
+
+			words := rec.Find("INSERT INTO missed_words_table")
+			if len(words) != 1 || words[0][0] != tt.stored || words[0][1] != tt.normalized {
+				t.Errorf("missed_words_table insert = %v, want raw word %q as %q", words, tt.stored, tt.normalized)
+			}
+			if !strings.Contains(body, `"normalized_word":"`+tt.normalized+`"`) {
+				t.Errorf("body = %s, want normalized_word %q", body, tt.normalized)
+			}
+		})
+	}
+}
+
+func TestGetMissedWordsSort(t *testing.T) {
+	db, rec := newRecordingDB(t)
+	app := newTestApp()
+	app.Get("/missed", GetMissedWords(db))
+
+	if status, body := doRequest(t, app, fiber.MethodGet, "/missed?sort=frequency", ""); status != fiber.StatusOK {
+		t.Fatalf("status = %d (body %s)", status, body)
+	}
+	if len(rec.Find("ORDER BY count DESC, last_seen DESC, id DESC")) != 1 {
+		t.Errorf("frequency sort did not order by count: %v", rec.Statements())
+	}
+
+	db, rec = newRecordingDB(t)
+	app = newTestApp()
+	app.Get("/missed", GetMissedWords(db))
+	if status, _ := doRequest(t, app, fiber.MethodGet, "/missed?sort=alphabetical", ""); status != fiber.StatusBadRequest {
+		t.Errorf("unknown sort status = %d, want 400", status)
+	}
+	if statements := rec.Statements(); len(statements) != 0 {
+		t.Errorf("unknown sort reached the database: %v", statements)
+	}
+}
+
+func TestCreateMissedWordCountsPerDay(t *testing.T) {
+	db, rec := newRecordingDB(t)
+	rec.respond = storedMissedWordRows
+	app := newTestApp()
+	app.Post("/missed", CreateMissedWord(db))
+
+	if status, body := doRequest(t, app, fiber.MethodPost, "/missed", `{"word":"physics"}`); status != fiber.StatusOK {
+		t.Fatalf("status = %d (body %s)", status, body)
+	}
+	daily := rec.Find("INSERT INTO missed_word_daily")
+	want := []driver.Value{int64(7), time.Now().Format(time.DateOnly)}
+	if len(daily) != 1 || !reflect.DeepEqual(daily[0], want) {
+		t.Errorf("missed_word_daily insert = %v, want %v", daily, want)
+	}
+}
+
+func TestCreateMissedWordRecordsEvent(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		event  []driver.Value
+	}{
+		{"full context", `{"word":"physics","platform":" Bot ","session_id":" abc ","previous_message":" hi "}`, fiber.StatusOK,
+			[]driver.Value{int64(7), "physics", "bot", "abc", "hi"}},
+		{"no context", `{"word":"physics"}`, fiber.StatusOK, []driver.Value{int64(7), "physics", nil, nil, nil}},
+		{"long previous message", `{"word":"physics","previous_message":"` + strings.Repeat("a", maxMissedWordPreviousLen+5) + `"}`, fiber.StatusOK,
+			[]driver.Value{int64(7), "physics", nil, nil, strings.Repeat("a", maxMissedWordPreviousLen)}},
+		{"unknown platform", `{"word":"physics","platform":"fax"}`, fiber.StatusBadRequest, nil},
+		{"email as session id", `{"word":"physics","session_id":"someone@example.com"}`, fiber.StatusBadRequest, nil},
+		{"long session id", `{"word":"physics","session_id":"` + strings.Repeat("s", maxMissedWordSessionLen+1) + `"}`, fiber.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := newRecordingDB(t)
+			rec.respond = storedMissedWordRows
+			app := newTestApp()
+			app.Post("/missed", CreateMissedWord(db))
+
+			status, body := doRequest(t, app, fiber.MethodPost, "/missed", tt.body)
+			if status != tt.status {
+				t.Fatalf("status = %d, want %d (body %s)", status, tt.status, body)
+			}
+			events := rec.Find("INSERT INTO missed_word_events")
+			if tt.event == nil {
+				if statements := rec.Statements(); len(statements) != 0 {
+					t.Errorf("rejected word ran %v", statements)
+				}
+				return
+			}
+			if len(events) != 1 || !reflect.DeepEqual(events[0][:5], tt.event) {
+				t.Errorf("missed_word_events insert = %v, want %v", events, tt.event)
+			}
+		})
+	}
+}
+
+func TestGetMissedWordsPlatforms(t *testing.T) {
+	db, rec := newRecordingDB(t)
+	rec.respond = func(query string) ([]string, [][]driver.Value) {
+		switch {
+		case strings.Contains(query, "SELECT * FROM missed_words_table"):
+			return []string{"id", "missed_words"}, [][]driver.Value{{int64(1), "physics"}, {int64(2), "chem"}}
+		case strings.Contains(query, "SELECT COUNT(*) FROM missed_words_table"):
+			return []string{"count"}, [][]driver.Value{{int64(2)}}
+		case strings.Contains(query, "SELECT missed_word_id, COALESCE"):
+			return []string{"missed_word_id", "platform", "count"}, [][]driver.Value{
+				{int64(1), "bot", int64(3)}, {int64(1), "unknown", int64(1)},
+			}
+		case strings.Contains(query, "FROM missed_word_events"):
+			return []string{"platform", "count"}, [][]driver.Value{{"bot", int64(3)}, {"unknown", int64(1)}}
+		}
+		return nil, nil
+	}
+	app := newTestApp()
+	app.Get("/missed", GetMissedWords(db))
+
+	status, body := doRequest(t, app, fiber.MethodGet, "/missed?platform=bot&session_id=abc", "")
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d (body %s)", status, body)
+	}
+	var response struct {
+		MissedWords    []map[string]interface{} `json:"missed_words"`
+		PlatformCounts map[string]int64         `json:"platform_counts"`
+	}
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(response.PlatformCounts, map[string]int64{"bot": 3, "unknown": 1}) {
+		t.Errorf("platform_counts = %v", response.PlatformCounts)
+	}
+	if len(response.MissedWords) != 2 {
+		t.Fatalf("missed_words = %v, want 2", response.MissedWords)
+	}
+	wantPlatforms := []interface{}{
+		map[string]interface{}{"bot": float64(3), "unknown": float64(1)},
+		map[string]interface{}{},
+	}
+	for i, word := range response.MissedWords {
+		if !reflect.DeepEqual(word["platforms"], wantPlatforms[i]) {
+			t.Errorf("word %v platforms = %v, want %v", word["id"], word["platforms"], wantPlatforms[i])
+		}
+	}
+	if counts := rec.Find("SELECT COUNT(*) FROM missed_words_table"); len(counts) != 1 || !reflect.DeepEqual(counts[0], []driver.Value{"bot", "abc"}) {
+		t.Errorf("count query args = %v, want the platform and session filters", counts)
+	}
+
+	if status, _ := doRequest(t, app, fiber.MethodGet, "/missed?platform=fax", ""); status != fiber.StatusBadRequest {
+		t.Errorf("unknown platform: status = %d, want 400", status)
+	}
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"log"
+	"sort"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/textnorm"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/utils"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Similarity measures that can be used for clustering
+var missedWordSimilarities = map[string]textnorm.Similarity{
+	"trigram":     textnorm.TrigramSimilarity,
+	"levenshtein": textnorm.EditSimilarity,
+}
+
+type missedWordClusterMember struct {
+	ID             int64  `json:"id"`
+	Word           string `json:"word"`
+	NormalizedWord string `json:"normalized_word"`
+	Count          int    `json:"count"`
+}
+
+type missedWordCluster struct {
+	Representative string                    `json:"representative"`
+	TotalCount     int                       `json:"total_count"`
+	Size           int                       `json:"size"`
+	Members        []missedWordClusterMember `json:"members"`
+}
+
+// GetMissedWordClusters handles grouping similar missed words, so "phy1 note", "phy 1 notes" and
+// "physics1 note" come back as one theme. Query params: metric (trigram or levenshtein),
+// threshold (0-1, higher is stricter), limit (how many of the most frequent words to cluster)
+// min_size (smallest cluster returned, 1 includes words without similar entries) and
+// includeResolved (also cluster words that were already mapped).
+func GetMissedWordClusters(db *gorm.DB) fiber.Handler {
+	log.Println("🟢 GET: GetMissedWordClusters handler called")
+	return func(c *fiber.Ctx) error {
+		metric := c.Query("metric", "trigram")
+		threshold := c.QueryFloat("threshold", 0.5)
+		limit := c.QueryInt("limit", 500)
+		minSize := c.QueryInt("min_size", 2)
+
+		similarity, ok := missedWordSimilarities[metric]
+		if !ok || threshold <= 0 || threshold > 1 {
+			return c.Status(400).JSON(fiber.Map{
+				"status": config.AppMessages.MissedWord.InvalidClusterParams,
+			})
+		}
+		if limit < 1 || limit > 2000 {
+			limit = 500
+		}
+		if minSize < 1 {
+			minSize = 1
+		}
+
+		whereClause := "resolved_at IS NULL"
+		if c.QueryBool("includeResolved") {
+			whereClause = "1=1"
+		}
+
+		var rows []storedMissedWord
+		if err := db.Raw(`
+			SELECT id, missed_words, normalized_word, count
+			FROM missed_words_table
+			WHERE `+whereClause+`
+			ORDER BY count DESC, id ASC
+			LIMIT ?`, limit).Scan(&rows).Error; err != nil {
+			log.Printf("🔴 Error while fetching missed words for clustering: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"status": config.AppMessages.MissedWord.FetchError,
+			})
+		}
+
+		// Rows stored before aggregation have no normalised form yet
+		members := make([]missedWordClusterMember, 0, len(rows))
+		texts := make([]string, 0, len(rows))
+		for _, row := range rows {
+			normalized := utils.NormalizeMissedWord(row.MissedWords)
+			if row.NormalizedWord != nil {
+				normalized = *row.NormalizedWord
+			}
+			if normalized == "" {
+				continue
+			}
+			members = append(members, missedWordClusterMember{
+				ID:             row.ID,
+				Word:           row.MissedWords,
+				NormalizedWord: normalized,
+				Count:          max(row.Count, 1),
+			})
+			texts = append(texts, normalized)
+		}
+
+		clusters := []missedWordCluster{}
+		for _, group := range textnorm.Cluster(texts, threshold, similarity) {
+			if len(group) < minSize {
+				continue
+			}
+
+			cluster := missedWordCluster{Size: len(group), Members: []missedWordClusterMember{}}
+			for _, i := range group {
+				cluster.Members = append(cluster.Members, members[i])
+				cluster.TotalCount += members[i].Count
+			}
+
+			// The most frequent member names the cluster, the shorter phrase wins a tie
+			sort.SliceStable(cluster.Members, func(i, j int) bool {
+				a, b := cluster.Members[i], cluster.Members[j]
+				if a.Count != b.Count {
+					return a.Count > b.Count
+				}
+				return len(a.NormalizedWord) < len(b.NormalizedWord)
+			})
+			cluster.Representative = cluster.Members[0].NormalizedWord
+			clusters = append(clusters, cluster)
+		}
+
+		sort.SliceStable(clusters, func(i, j int) bool {
+			return clusters[i].TotalCount > clusters[j].TotalCount
+		})
+
+		return c.Status(200).JSON(fiber.Map{
+			"clusters":      clusters,
+			"total":         len(clusters),
+			"words_scanned": len(texts),
+			"metric":        metric,
+			"threshold":     threshold,
+			"min_size":      minSize,
+		})
+	}
+}
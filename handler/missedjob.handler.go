@@ -0,0 +1,357 @@
+package handler
+
+import (
+	"log"
+	"time"
+	"unicode/utf8"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/utils"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type storedMissedWord struct {
+	ID             int64      `json:"id"`
+	MissedWords    string     `json:"missed_words"`
+	NormalizedWord *string    `json:"normalized_word"`
+	Count          int        `json:"count"`
+	FirstSeen      *time.Time `json:"first_seen"`
+	LastSeen       *time.Time `json:"last_seen"`
+}
+
+type missedWordMergePlan struct {
+	NormalizedWord string           `json:"normalized_word"`
+	PreviousWord   *string          `json:"previous_normalized_word"`
+	KeptID         int64            `json:"kept_id"`
+	RemovedIDs     []int64          `json:"removed_ids"`
+	Merged         storedMissedWord `json:"merged"`
+}
+
+// missedWordMappingRekey is a mapping moved from a previous normalised form to the current one
+type missedWordMappingRekey struct {
+	ID   int64  `json:"id"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// missedWordAggregation is what aggregateMissedWords changed, or would change on a dry run
+type missedWordAggregation struct {
+	Plans           []missedWordMergePlan
+	Skipped         []storedMissedWord
+	MappingsRekeyed []missedWordMappingRekey
+	MappingsDropped []MissedWordMapping
+}
+
+// planMissedWordMerge folds rows (sorted by id) that share a normalised word into the earliest one.
+// Counts are summed and the seen range widened; legacy rows without a count are one occurrence.
+func planMissedWordMerge(normalized string, rows []storedMissedWord) missedWordMergePlan {
+	merged := rows[0]
+	plan := missedWordMergePlan{
+		NormalizedWord: normalized,
+		PreviousWord:   merged.NormalizedWord,
+		KeptID:         merged.ID,
+		RemovedIDs:     []int64{},
+	}
+	merged.NormalizedWord = &normalized
+	merged.Count = 0
+
+	for i, row := range rows {
+		if i > 0 {
+			plan.RemovedIDs = append(plan.RemovedIDs, row.ID)
+		}
+		merged.Count += max(row.Count, 1)
+		if row.FirstSeen != nil && (merged.FirstSeen == nil || row.FirstSeen.Before(*merged.FirstSeen)) {
+			merged.FirstSeen = row.FirstSeen
+		}
+		if row.LastSeen != nil && (merged.LastSeen == nil || row.LastSeen.After(*merged.LastSeen)) {
+			merged.LastSeen = row.LastSeen
+		}
+	}
+
+	plan.Merged = merged
+	return plan
+}
+
+// aggregateMissedWords regroups every missed word under its current normalised form and merges
+// each group into one row. Mappings follow their words to the new normalised form, and kept rows
+// are resolved exactly when their word is mapped. Rows whose word normalises to nothing are left
+// alone and reported as skipped.
+func aggregateMissedWords(tx *gorm.DB, dryRun bool) (missedWordAggregation, error) {
+	result := missedWordAggregation{Plans: []missedWordMergePlan{}, Skipped: []storedMissedWord{}}
+
+	var rows []storedMissedWord
+	if err := tx.Raw(`
+		SELECT id, missed_words, normalized_word, count, first_seen, last_seen
+		FROM missed_words_table
+		ORDER BY id ASC
+		FOR UPDATE`).Scan(&rows).Error; err != nil {
+		return result, err
+	}
+
+	groups := map[string][]storedMissedWord{}
+	order := []string{}
+	for _, row := range rows {
+		normalized := utils.NormalizeMissedWord(row.MissedWords)
+		if normalized == "" || utf8.RuneCountInString(normalized) > utils.MaxMissedWordLen {
+			result.Skipped = append(result.Skipped, row)
+			continue
+		}
+		if _, ok := groups[normalized]; !ok {
+			order = append(order, normalized)
+		}
+		groups[normalized] = append(groups[normalized], row)
+	}
+
+	kept := []storedMissedWord{}
+	removedIDs := []int64{}
+	for _, normalized := range order {
+		group := groups[normalized]
+		plan := planMissedWordMerge(normalized, group)
+
+		// Rows already stored under their normalised form with nothing to merge need no change
+		if len(group) == 1 && group[0].NormalizedWord != nil && *group[0].NormalizedWord == normalized {
+			continue
+		}
+		result.Plans = append(result.Plans, plan)
+		kept = append(kept, plan.Merged)
+		removedIDs = append(removedIDs, plan.RemovedIDs...)
+	}
+	if len(result.Plans) == 0 {
+		return result, nil
+	}
+
+	var err error
+	result.MappingsRekeyed, result.MappingsDropped, err = planMissedWordMappingRekeys(tx, groups, result.Plans)
+	if err != nil || dryRun {
+		return result, err
+	}
+
+	// Move the daily counts and occurrences of merged rows onto the row that is kept
+	for _, plan := range result.Plans {
+		if len(plan.RemovedIDs) == 0 {
+			continue
+		}
+		if err := tx.Exec(`
+			INSERT INTO missed_word_daily (missed_word_id, day, count)
+			SELECT ?, day, count FROM missed_word_daily WHERE missed_word_id IN ?
+			ON DUPLICATE KEY UPDATE count = missed_word_daily.count + VALUES(count)`,
+			plan.KeptID, plan.RemovedIDs,
+		).Error; err != nil {
+			return result, err
+		}
+		if err := tx.Exec("UPDATE missed_word_events SET missed_word_id = ? WHERE missed_word_id IN ?",
+			plan.KeptID, plan.RemovedIDs).Error; err != nil {
+			return result, err
+		}
+	}
+
+	if len(removedIDs) > 0 {
+		if err := tx.Exec("DELETE FROM missed_word_daily WHERE missed_word_id IN ?", removedIDs).Error; err != nil {
+			return result, err
+		}
+		if err := tx.Exec("DELETE FROM missed_words_table WHERE id IN ?", removedIDs).Error; err != nil {
+			return result, err
+		}
+	}
+
+	// Clear the kept rows first so swapping normalised words between rows can't hit the unique index
+	keptIDs := make([]int64, 0, len(kept))
+	for _, row := range kept {
+		keptIDs = append(keptIDs, row.ID)
+	}
+	if err := tx.Exec("UPDATE missed_words_table SET normalized_word = NULL WHERE id IN ?", keptIDs).Error; err != nil {
+		return result, err
+	}
+	for _, row := range kept {
+		if err := tx.Exec(`
+			UPDATE missed_words_table
+			SET normalized_word = ?, count = ?, first_seen = ?, last_seen = ?
+			WHERE id = ?`,
+			row.NormalizedWord, row.Count, row.FirstSeen, row.LastSeen, row.ID,
+		).Error; err != nil {
+			return result, err
+		}
+	}
+
+	if err := applyMissedWordMappingRekeys(tx, result.MappingsRekeyed, result.MappingsDropped); err != nil {
+		return result, err
+	}
+
+	// A merged row may have taken over a mapped word, or lost the one it was resolved by
+	if err := tx.Exec(`
+		UPDATE missed_words_table SET resolved_at = COALESCE(resolved_at, ?)
+		WHERE id IN ? AND normalized_word IN (SELECT normalized_word FROM missed_word_mappings)`,
+		time.Now(), keptIDs,
+	).Error; err != nil {
+		return result, err
+	}
+	return result, tx.Exec(`
+		UPDATE missed_words_table SET resolved_at = NULL
+		WHERE id IN ? AND normalized_word NOT IN (SELECT normalized_word FROM missed_word_mappings)`,
+		keptIDs,
+	).Error
+}
+
+// planMissedWordMappingRekeys works out where the mappings of renamed words go. A mapping stays on
+// its word while any row still normalises to it, otherwise it follows the earliest row that was
+// stored under it. When several mappings land on one word, the one already there wins, then the
+// one of the kept row's previous word; the others are dropped.
+func planMissedWordMappingRekeys(tx *gorm.DB, groups map[string][]storedMissedWord, plans []missedWordMergePlan) ([]missedWordMappingRekey, []MissedWordMapping, error) {
+	rekeys := []missedWordMappingRekey{}
+	dropped := []MissedWordMapping{}
+
+	// Previous normalised forms in row id order, and the current form each one moved to
+	destinations := map[string]string{}
+	previousWords := []string{}
+	keptPrevious := map[string]string{}
+	for _, plan := range plans {
+		if plan.PreviousWord != nil {
+			keptPrevious[plan.NormalizedWord] = *plan.PreviousWord
+		}
+		for _, row := range groups[plan.NormalizedWord] {
+			if row.NormalizedWord == nil || *row.NormalizedWord == plan.NormalizedWord {
+				continue
+			}
+			previous := *row.NormalizedWord
+			if _, stillUsed := groups[previous]; stillUsed {
+				continue
+			}
+			if _, ok := destinations[previous]; !ok {
+				destinations[previous] = plan.NormalizedWord
+				previousWords = append(previousWords, previous)
+			}
+		}
+	}
+	if len(previousWords) == 0 {
+		return rekeys, dropped, nil
+	}
+
+	words := append([]string{}, previousWords...)
+	for _, plan := range plans {
+		words = append(words, plan.NormalizedWord)
+	}
+	var mappings []MissedWordMapping
+	if err := tx.Raw("SELECT * FROM missed_word_mappings WHERE normalized_word IN ? ORDER BY id FOR UPDATE", words).
+		Scan(&mappings).Error; err != nil {
+		return nil, nil, err
+	}
+
+	landing := map[string][]MissedWordMapping{}
+	for _, mapping := range mappings {
+		destination, moves := destinations[mapping.NormalizedWord]
+		if !moves {
+			destination = mapping.NormalizedWord
+		}
+		landing[destination] = append(landing[destination], mapping)
+	}
+
+	for _, plan := range plans {
+		candidates := landing[plan.NormalizedWord]
+		if len(candidates) == 0 {
+			continue
+		}
+		winner := 0
+		for i, mapping := range candidates {
+			if mapping.NormalizedWord == plan.NormalizedWord {
+				winner = i
+				break
+			}
+			if mapping.NormalizedWord == keptPrevious[plan.NormalizedWord] {
+				winner = i
+			}
+		}
+		for i, mapping := range candidates {
+			switch {
+			case i != winner:
+				dropped = append(dropped, mapping)
+			case mapping.NormalizedWord != plan.NormalizedWord:
+				rekeys = append(rekeys, missedWordMappingRekey{ID: mapping.ID, From: mapping.NormalizedWord, To: plan.NormalizedWord})
+			}
+		}
+	}
+	return rekeys, dropped, nil
+}
+
+// applyMissedWordMappingRekeys drops the losing mappings and moves the rest. Moved mappings get a
+// placeholder key first so two words swapping normalised forms can't hit the unique index.
+func applyMissedWordMappingRekeys(tx *gorm.DB, rekeys []missedWordMappingRekey, dropped []MissedWordMapping) error {
+	if len(dropped) > 0 {
+		ids := make([]int64, 0, len(dropped))
+		for _, mapping := range dropped {
+			ids = append(ids, mapping.ID)
+		}
+		if err := tx.Exec("DELETE FROM missed_word_mappings WHERE id IN ?", ids).Error; err != nil {
+			return err
+		}
+	}
+	if len(rekeys) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(rekeys))
+	for _, rekey := range rekeys {
+		ids = append(ids, rekey.ID)
+	}
+	if err := tx.Exec("UPDATE missed_word_mappings SET normalized_word = CONCAT('#rekey-', id) WHERE id IN ?", ids).Error; err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, rekey := range rekeys {
+		if err := tx.Exec("UPDATE missed_word_mappings SET normalized_word = ?, updated_at = ? WHERE id = ?",
+			rekey.To, now, rekey.ID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AggregateMissedWords re-runs the normalisation pipeline over every stored missed word, from the
+// raw text kept in missed_words, and merges words that share a normalised form into one counted
+// entry. It backfills rows stored before aggregation existed and renames entries whose normalised
+// form changed after the pipeline or its settings did, moving their mappings along with them.
+// Pass dryRun=true to only report the changes.
+func AggregateMissedWords(db *gorm.DB) fiber.Handler {
+	log.Println("🔵 POST: AggregateMissedWords handler called")
+	return func(c *fiber.Ctx) error {
+		if c.Query("adminKey") != config.GetAppConfig().ADMIN_AUTH_KEY {
+			return c.Status(401).JSON(fiber.Map{"error": config.AppMessages.MissedWord.UnauthorizedAccess})
+		}
+
+		dryRun := c.QueryBool("dryRun")
+
+		var result missedWordAggregation
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			result, err = aggregateMissedWords(tx, dryRun)
+			return err
+		})
+		if err != nil {
+			log.Printf("🔴 Error while aggregating missed words: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"status": config.AppMessages.MissedWord.OperationUnsuccessful,
+			})
+		}
+
+		renamed := 0
+		rowsRemoved := 0
+		for _, plan := range result.Plans {
+			if plan.PreviousWord == nil || *plan.PreviousWord != plan.NormalizedWord {
+				renamed++
+			}
+			rowsRemoved += len(plan.RemovedIDs)
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"dry_run":          dryRun,
+			"remove_stopwords": config.GetMissedWordConfig().REMOVE_STOPWORDS,
+			"merges":           result.Plans,
+			"renamed":          renamed,
+			"rows_removed":     rowsRemoved,
+			"skipped":          result.Skipped,
+			"mappings_rekeyed": result.MappingsRekeyed,
+			"mappings_dropped": result.MappingsDropped,
+			"status":           config.AppMessages.MissedWord.AggregateSuccess,
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestPlanMissedWordMerge(t *testing.T) {
+	day := func(d int) *time.Time {
+		date := time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC)
+		return &date
+	}
+	old := "Physics"
+	rows := []storedMissedWord{
+		{ID: 3, MissedWords: "Physics", NormalizedWord: &old, Count: 4, FirstSeen: day(5), LastSeen: day(9)},
+		{ID: 7, MissedWords: "physics!!", Count: 0},
+		{ID: 9, MissedWords: "PHYSICS", Count: 2, FirstSeen: day(2), LastSeen: day(12)},
+	}
+
+	plan := planMissedWordMerge("physics", rows)
+	if plan.KeptID != 3 || !reflect.DeepEqual(plan.RemovedIDs, []int64{7, 9}) {
+		t.Errorf("kept %d, removed %v, want 3 and [7 9]", plan.KeptID, plan.RemovedIDs)
+	}
+	if plan.PreviousWord == nil || *plan.PreviousWord != "Physics" {
+		t.Errorf("previous word = %v, want Physics", plan.PreviousWord)
+	}
+	merged := plan.Merged
+	if *merged.NormalizedWord != "physics" || merged.Count != 7 {
+		t.Errorf("merged = %s x%d, want physics x7 (legacy rows count once)", *merged.NormalizedWord, merged.Count)
+	}
+	if !merged.FirstSeen.Equal(*day(2)) || !merged.LastSeen.Equal(*day(12)) {
+		t.Errorf("seen range = %v..%v, want widest range", merged.FirstSeen, merged.LastSeen)
+	}
+	if *rows[0].NormalizedWord != "Physics" {
+		t.Error("planning modified the stored row")
+	}
+}
+
+// missedWordRows serves missed_words_table rows as stored before aggregation
+func missedWordRows(rows ...[]driver.Value) func(string) ([]string, [][]driver.Value) {
+	return func(query string) ([]string, [][]driver.Value) {
+		if !strings.Contains(query, "FROM missed_words_table") {
+			return nil, nil
+		}
+		return []string{"id", "missed_words", "normalized_word", "count", "first_seen", "last_seen"}, rows
+	}
+}
+
+func TestAggregateMissedWords(t *testing.T) {
+	useTestAdminKey(t)
+	t.Setenv("MISSED_WORD_REMOVE_STOPWORDS", "false")
+
+	db, rec := newRecordingDB(t)
+	rec.respond = missedWordRows(
+		[]driver.Value{int64(1), "Physics", "physics", int64(2), nil, nil},
+		[]driver.Value{int64(2), "physics!!", nil, int64(1), nil, nil},
+		[]driver.Value{int64(3), "Chem", "chem", int64(1), nil, nil},
+		[]driver.Value{int64(4), "!!!", nil, int64(1), nil, nil},
+		[]driver.Value{int64(5), "MATH", "MATH", int64(3), nil, nil},
+	)
+	app := newTestApp()
+	app.Post("/missed/aggregate", AggregateMissedWords(db))
+
+	assertUnauthorized(t, app, rec, fiber.MethodPost, "/missed/aggregate", "")
+
+	status, body := doRequest(t, app, fiber.MethodPost, "/missed/aggregate?adminKey="+testAdminKey, "")
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d (body %s)", status, body)
+	}
+
+	var response struct {
+		Merges      []missedWordMergePlan `json:"merges"`
+		Renamed     int                   `json:"renamed"`
+		RowsRemoved int                   `json:"rows_removed"`
+		Skipped     []storedMissedWord    `json:"skipped"`
+	}
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Merges) != 2 || response.Renamed != 1 || response.RowsRemoved != 1 {
+		t.Errorf("merges %d, renamed %d, rows removed %d, want 2, 1 and 1", len(response.Merges), response.Renamed, response.RowsRemoved)
+	}
+	if len(response.Skipped) != 1 || response.Skipped[0].ID != 4 {
+		t.Errorf("skipped = %+v, want the row that normalises to nothing", response.Skipped)
+	}
+
+	if deletes := rec.Find("DELETE FROM missed_words_table"); len(deletes) != 1 || !reflect.DeepEqual(deletes[0], []driver.Value{int64(2)}) {
+		t.Errorf("deleted rows = %v, want [2]", deletes)
+	}
+	for _, update := range rec.Find("SET normalized_word = ?, count = ?") {
+		if update[len(update)-1] == int64(3) {
+			t.Error("rewrote a row whose normalised form did not change")
+		}
+	}
+}
+
+func TestAggregateMissedWordsDryRun(t *testing.T) {
+	useTestAdminKey(t)
+
+	db, rec := newRecordingDB(t)
+	rec.respond = missedWordRows(
+		[]driver.Value{int64(1), "Physics", "physics", int64(2), nil, nil},
+		[]driver.Value{int64(2), " PHYSICS ", nil, int64(1), nil, nil},
+	)
+	app := newTestApp()
+	app.Post("/missed/aggregate", AggregateMissedWords(db))
+
+	status, body := doRequest(t, app, fiber.MethodPost, "/missed/aggregate?dryRun=true&adminKey="+testAdminKey, "")
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d (body %s)", status, body)
+	}
+	if !strings.Contains(body, `"rows_removed":1`) {
+		t.Errorf("body = %s, want the planned removal reported", body)
+	}
+	if len(rec.Find("DELETE FROM missed_words_table")) != 0 || len(rec.Find("UPDATE missed_words_table")) != 0 {
+		t.Error("dry run changed missed words")
+	}
+}
+
+// missedWordMappingRows serves missed_words_table rows and the mappings stored for them
+func missedWordMappingRows(words [][]driver.Value, mappings [][]driver.Value) func(string) ([]string, [][]driver.Value) {
+	rows := missedWordRows(words...)
+	return func(query string) ([]string, [][]driver.Value) {
+		if strings.Contains(query, "FROM missed_word_mappings") {
+			return []string{"id", "normalized_word", "target_type", "target"}, mappings
+		}
+		return rows(query)
+	}
+}
+
+func TestAggregateMissedWordsMappings(t *testing.T) {
+	t.Setenv("MISSED_WORD_REMOVE_STOPWORDS", "false")
+
+	tests := []struct {
+		name     string
+		words    [][]driver.Value
+		mappings [][]driver.Value
+		rekeyed  []missedWordMappingRekey
+		dropped  []int64
+	}{
+		{
+			name:     "mapping follows its renamed word",
+			words:    [][]driver.Value{{int64(1), "Physics", "Physics", int64(2), nil, nil}},
+			mappings: [][]driver.Value{{int64(10), "Physics", "subject", "phy"}},
+			rekeyed:  []missedWordMappingRekey{{ID: 10, From: "Physics", To: "physics"}},
+		},
+		{
+			name: "kept row's mapping wins a merge",
+			words: [][]driver.Value{
+				{int64(1), "Physics", "Physics", int64(2), nil, nil},
+				{int64(2), "PHYSICS", "PHYSICS", int64(1), nil, nil},
+			},
+			mappings: [][]driver.Value{
+				{int64(11), "PHYSICS", "subject", "phy"},
+				{int64(10), "Physics", "subject", "phy"},
+			},
+			rekeyed: []missedWordMappingRekey{{ID: 10, From: "Physics", To: "physics"}},
+			dropped: []int64{11},
+		},
+		{
+			name: "mapping already on the current form wins",
+			words: [][]driver.Value{
+				{int64(1), "Physics", "Physics", int64(2), nil, nil},
+				{int64(2), "physics", nil, int64(1), nil, nil},
+			},
+			mappings: [][]driver.Value{
+				{int64(10), "Physics", "subject", "phy"},
+				{int64(12), "physics", "intent", "greeting"},
+			},
+			dropped: []int64{10},
+		},
+		{
+			name: "mapping stays while a row still uses its word",
+			words: [][]driver.Value{
+				{int64(1), "chem", "chem", int64(2), nil, nil},
+				{int64(2), "Chem!", "chem", int64(1), nil, nil},
+			},
+			mappings: [][]driver.Value{{int64(13), "chem", "subject", "chem"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := newRecordingDB(t)
+			rec.respond = missedWordMappingRows(tt.words, tt.mappings)
+
+			result, err := aggregateMissedWords(db, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tt.rekeyed) == 0 {
+				tt.rekeyed = []missedWordMappingRekey{}
+			}
+			if result.MappingsRekeyed == nil {
+				result.MappingsRekeyed = []missedWordMappingRekey{}
+			}
+			if !reflect.DeepEqual(result.MappingsRekeyed, tt.rekeyed) {
+				t.Errorf("rekeyed = %+v, want %+v", result.MappingsRekeyed, tt.rekeyed)
+			}
+			var dropped []int64
+			for _, mapping := range result.MappingsDropped {
+				dropped = append(dropped, mapping.ID)
+			}
+			if !reflect.DeepEqual(dropped, tt.dropped) {
+				t.Errorf("dropped = %v, want %v", dropped, tt.dropped)
+			}
+			for _, statement := range rec.Statements() {
+				if strings.HasPrefix(strings.TrimSpace(statement), "UPDATE") || strings.HasPrefix(strings.TrimSpace(statement), "DELETE") {
+					t.Errorf("dry run wrote: %s", statement)
+				}
+			}
+		})
+	}
+}
+
+func TestAggregateMissedWordsRekeysMappings(t *testing.T) {
+	t.Setenv("MISSED_WORD_REMOVE_STOPWORDS", "false")
+
+	db, rec := newRecordingDB(t)
+	rec.respond = missedWordMappingRows(
+		[][]driver.Value{
+			{int64(1), "Physics", "Physics", int64(2), nil, nil},
+			{int64(2), "PHYSICS", "PHYSICS", int64(1), nil, nil},
+		},
+		[][]driver.Value{
+			{int64(10), "Physics", "subject", "phy"},
+			{int64(11), "PHYSICS", "subject", "phy"},
+		},
+	)
+
+	if _, err := aggregateMissedWords(db, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if deletes := rec.Find("DELETE FROM missed_word_mappings"); len(deletes) != 1 || !reflect.DeepEqual(deletes[0], []driver.Value{int64(11)}) {
+		t.Errorf("deleted mappings = %v, want [11]", deletes)
+	}
+	placeholder := rec.Find("CONCAT('#rekey-', id)")
+	if len(placeholder) != 1 || !reflect.DeepEqual(placeholder[0], []driver.Value{int64(10)}) {
+		t.Errorf("placeholder keys = %v, want [10]", placeholder)
+	}
+	moves := rec.Find("UPDATE missed_word_mappings SET normalized_word = ?, updated_at = ?")
+	if len(moves) != 1 || moves[0][0] != "physics" || moves[0][2] != int64(10) {
+		t.Errorf("moved mappings = %v, want 10 to physics", moves)
+	}
+
+	// Resolution is synced after the mappings moved, so it sees their new keys
+	statements := rec.Statements()
+	moved, resolved := -1, -1
+	for i, statement := range statements {
+		switch {
+		case strings.Contains(statement, "SET normalized_word = ?, updated_at = ?"):
+			moved = i
+		case strings.Contains(statement, "SET resolved_at = COALESCE"):
+			resolved = i
+		}
+	}
+	if moved == -1 || resolved < moved {
+		t.Errorf("resolution synced at statement %d, mappings moved at %d", resolved, moved)
+	}
+	if len(rec.Find("SET resolved_at = NULL")) != 1 {
+		t.Error("rows that lost their mapping were not unresolved")
+	}
+}
@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/utils"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Kinds of keyword a missed word can be mapped to
+const (
+	MissedWordTargetSubject = "subject"
+	MissedWordTargetLab     = "lab"
+	MissedWordTargetIntent  = "intent"
+)
+
+// Subject catalog types the subject and lab targets must exist under. Intents live in the bot and
+// are not checked.
+var missedWordTargetCatalogTypes = map[string]string{
+	MissedWordTargetSubject: config.SubjectTypeNote,
+	MissedWordTargetLab:     config.SubjectTypeLab,
+}
+
+type MissedWordMapping struct {
+	ID             int64     `json:"id"`
+	NormalizedWord string    `json:"normalized_word"`
+	TargetType     string    `json:"target_type"`
+	Target         string    `json:"target"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func isValidMissedWordTarget(targetType string) bool {
+	_, ok := missedWordTargetCatalogTypes[targetType]
+	return ok || targetType == MissedWordTargetIntent
+}
+
+// CreateMissedWordMappings handles mapping missed words to a subject, lab or bot intent. Words are
+// given as text in "words" and/or as missed word ids in "ids", so a whole cluster can be mapped at
+// once. Mapped words are marked resolved and drop out of the missed word backlog.
+func CreateMissedWordMappings(db *gorm.DB) fiber.Handler {
+	log.Println("🔵 POST: CreateMissedWordMappings handler called")
+	return func(c *fiber.Ctx) error {
+		if c.Query("adminKey") != config.GetAppConfig().ADMIN_AUTH_KEY {
+			return c.Status(401).JSON(fiber.Map{"error": config.AppMessages.MissedWord.UnauthorizedAccess})
+		}
+
+		var body struct {
+			Words      []string `json:"words"`
+			IDs        []int64  `json:"ids"`
+			TargetType string   `json:"target_type"`
+			Target     string   `json:"target"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.MissedWord.BadRequest})
+		}
+
+		body.TargetType = strings.ToLower(strings.TrimSpace(body.TargetType))
+		body.Target = strings.TrimSpace(body.Target)
+		if !isValidMissedWordTarget(body.TargetType) || body.Target == "" {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.MissedWord.InvalidMapping})
+		}
+
+		// Subjects and labs must be active in the catalog, so words can't map to retired or pending ones
+		if subjectType, ok := missedWordTargetCatalogTypes[body.TargetType]; ok {
+			var matches int64
+			if err := db.Raw(`
+				SELECT COUNT(*) FROM subject_catalog
+				WHERE type = ? AND sub_name = ? AND active = TRUE AND pending = FALSE`,
+				subjectType, body.Target,
+			).Scan(&matches).Error; err != nil {
+				log.Printf("🔴 Error while checking mapping target: %v", err)
+				return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.MissedWord.OperationUnsuccessful})
+			}
+			if matches == 0 {
+				return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.MissedWord.MappingTargetNotFound})
+			}
+		}
+
+		words := map[string]bool{}
+		order := []string{}
+		addWord := func(word string) {
+			normalized := utils.NormalizeMissedWord(word)
+			if normalized == "" || utf8.RuneCountInString(normalized) > utils.MaxMissedWordLen || words[normalized] {
+				return
+			}
+			words[normalized] = true
+			order = append(order, normalized)
+		}
+		for _, word := range body.Words {
+			addWord(word)
+		}
+		if len(body.IDs) > 0 {
+			var rows []storedMissedWord
+			if err := db.Raw("SELECT id, missed_words, normalized_word FROM missed_words_table WHERE id IN ?", body.IDs).
+				Scan(&rows).Error; err != nil {
+				log.Printf("🔴 Error while fetching missed words to map: %v", err)
+				return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.MissedWord.OperationUnsuccessful})
+			}
+			for _, row := range rows {
+				if row.NormalizedWord != nil {
+					addWord(*row.NormalizedWord)
+				} else {
+					addWord(row.MissedWords)
+				}
+			}
+		}
+		if len(order) == 0 {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.MissedWord.InvalidMapping})
+		}
+
+		now := time.Now()
+		var rowsResolved int64
+		mappings := []MissedWordMapping{}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, word := range order {
+				if err := tx.Exec(`
+					INSERT INTO missed_word_mappings (normalized_word, target_type, target, created_at, updated_at)
+					VALUES (?, ?, ?, ?, ?)
+					ON DUPLICATE KEY UPDATE target_type = ?, target = ?, updated_at = ?`,
+					word, body.TargetType, body.Target, now, now, body.TargetType, body.Target, now,
+				).Error; err != nil {
+					return err
+				}
+			}
+
+			resolved := tx.Exec("UPDATE missed_words_table SET resolved_at = ? WHERE normalized_word IN ? AND resolved_at IS NULL",
+				now, order)
+			if resolved.Error != nil {
+				return resolved.Error
+			}
+			rowsResolved = resolved.RowsAffected
+
+			return tx.Raw("SELECT * FROM missed_word_mappings WHERE normalized_word IN ? ORDER BY normalized_word", order).
+				Scan(&mappings).Error
+		})
+		if err != nil {
+			log.Printf("🔴 Error while mapping missed words: %v", err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.MissedWord.OperationUnsuccessful})
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"mappings":      mappings,
+			"rows_resolved": rowsResolved,
+			"status":        config.AppMessages.MissedWord.MappingSuccess,
+		})
+	}
+}
+
+// GetMissedWordMappings handles listing the missed word mappings for the bot.
+// Pass target_type to only list subject, lab or intent mappings.
+func GetMissedWordMappings(db *gorm.DB) fiber.Handler {
+	log.Println("🟢 GET: GetMissedWordMappings handler called")
+	return func(c *fiber.Ctx) error {
+		query := "SELECT * FROM missed_word_mappings"
+		params := []interface{}{}
+		if targetType := c.Query("target_type"); targetType != "" {
+			if !isValidMissedWordTarget(targetType) {
+				return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.MissedWord.InvalidMapping})
+			}
+			query += " WHERE target_type = ?"
+			params = append(params, targetType)
+		}
+		query += " ORDER BY normalized_word"
+
+		mappings := []MissedWordMapping{}
+		if err := db.Raw(query, params...).Scan(&mappings).Error; err != nil {
+			log.Printf("🔴 Error while fetching missed word mappings: %v", err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.MissedWord.FetchError})
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"mappings": mappings,
+			"total":    len(mappings),
+			"status":   config.AppMessages.MissedWord.MappingsFetchSuccess,
+		})
+	}
+}
+
+// DeleteMissedWordMapping handles removing a mapping. The word goes back into the missed word backlog.
+func DeleteMissedWordMapping(db *gorm.DB) fiber.Handler {
+	log.Println("🟣 DELETE: DeleteMissedWordMapping handler called")
+	return func(c *fiber.Ctx) error {
+		if c.Query("adminKey") != config.GetAppConfig().ADMIN_AUTH_KEY {
+			return c.Status(401).JSON(fiber.Map{"error": config.AppMessages.MissedWord.UnauthorizedAccess})
+		}
+
+		id, err := c.ParamsInt("id")
+		if err != nil || id < 1 {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.MissedWord.BadRequest})
+		}
+
+		var mapping MissedWordMapping
+		err = db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Raw("SELECT * FROM missed_word_mappings WHERE id = ? FOR UPDATE", id).Scan(&mapping)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+			if err := tx.Exec("DELETE FROM missed_word_mappings WHERE id = ?", id).Error; err != nil {
+				return err
+			}
+			return tx.Exec("UPDATE missed_words_table SET resolved_at = NULL WHERE normalized_word = ?",
+				mapping.NormalizedWord).Error
+		})
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(404).JSON(fiber.Map{"status": config.AppMessages.MissedWord.MappingNotFound})
+		}
+		if err != nil {
+			log.Printf("🔴 Error while deleting missed word mapping %d: %v", id, err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.MissedWord.OperationUnsuccessful})
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"mapping": mapping,
+			"status":  config.AppMessages.MissedWord.MappingDeleteSuccess,
+		})
+	}
+}
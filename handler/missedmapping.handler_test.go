@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestCreateMissedWordMappingsTarget(t *testing.T) {
+	useTestAdminKey(t)
+
+	tests := []struct {
+		name      string
+		body      string
+		inCatalog int64
+		status    int
+		checked   []driver.Value
+	}{
+		{"subject in catalog", `{"words":["phy"],"target_type":"subject","target":"physics"}`, 1, fiber.StatusOK, []driver.Value{config.SubjectTypeNote, "physics"}},
+		{"lab in catalog", `{"words":["phy lab"],"target_type":"lab","target":"phylab"}`, 1, fiber.StatusOK, []driver.Value{config.SubjectTypeLab, "phylab"}},
+		{"subject missing from catalog", `{"words":["phy"],"target_type":"subject","target":"retired"}`, 0, fiber.StatusBadRequest, []driver.Value{config.SubjectTypeNote, "retired"}},
+		{"intent is not checked", `{"words":["hi"],"target_type":"intent","target":"greeting"}`, 0, fiber.StatusOK, nil},
+		{"unknown target type", `{"words":["hi"],"target_type":"course","target":"greeting"}`, 1, fiber.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := newRecordingDB(t)
+			rec.respond = func(query string) ([]string, [][]driver.Value) {
+				if strings.Contains(query, "FROM subject_catalog") {
+					return []string{"COUNT(*)"}, [][]driver.Value{{tt.inCatalog}}
+				}
+				return nil, nil
+			}
+			app := newTestApp()
+			app.Post("/missed/mappings", CreateMissedWordMappings(db))
+
+			status, body := doRequest(t, app, fiber.MethodPost, "/missed/mappings?adminKey="+testAdminKey, tt.body)
+			if status != tt.status {
+				t.Fatalf("status = %d, want %d (body %s)", status, tt.status, body)
+			}
+			checks := rec.Find("FROM subject_catalog")
+			if tt.checked == nil {
+				if len(checks) != 0 {
+					t.Errorf("checked the catalog for %v", checks)
+				}
+				return
+			}
+			if len(checks) != 1 || !reflect.DeepEqual(checks[0], tt.checked) {
+				t.Errorf("catalog checks = %v, want %v", checks, tt.checked)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const maxTrendWindowDays = 90
+
+type trendingMissedWord struct {
+	ID             int64    `json:"id"`
+	Word           string   `json:"word"`
+	NormalizedWord string   `json:"normalized_word"`
+	CurrentCount   int      `json:"current_count"`
+	PreviousCount  int      `json:"previous_count"`
+	Growth         int      `json:"growth"`
+	GrowthRate     *float64 `json:"growth_rate"`
+	IsNew          bool     `json:"is_new"`
+}
+
+// parseWindowDays reads a window such as "7d" or "7" as a number of days
+func parseWindowDays(window string) (int, bool) {
+	days, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(window)), "d"))
+	if err != nil || days < 1 || days > maxTrendWindowDays {
+		return 0, false
+	}
+	return days, true
+}
+
+// GetTrendingMissedWords handles listing the missed words that grew the most in the last window
+// compared with the window before it. Query params: window (days, e.g. 7d), limit, min_count
+// (fewest occurrences in the current window) and includeResolved. growth_rate is null for words
+// that were not missed at all in the previous window, those are flagged is_new instead.
+func GetTrendingMissedWords(db *gorm.DB) fiber.Handler {
+	log.Println("🟢 GET: GetTrendingMissedWords handler called")
+	return func(c *fiber.Ctx) error {
+		days, ok := parseWindowDays(c.Query("window", "7d"))
+		if !ok {
+			return c.Status(400).JSON(fiber.Map{
+				"status": config.AppMessages.MissedWord.InvalidWindow,
+			})
+		}
+
+		limit := c.QueryInt("limit", 20)
+		if limit < 1 || limit > 100 {
+			limit = 20
+		}
+		minCount := c.QueryInt("min_count", 2)
+		if minCount < 1 {
+			minCount = 1
+		}
+
+		// The current window ends today, the previous one is the same number of days before it
+		currentFrom := time.Now().AddDate(0, 0, -(days - 1)).Format(time.DateOnly)
+		previousFrom := time.Now().AddDate(0, 0, -(2*days - 1)).Format(time.DateOnly)
+
+		resolvedFilter := "AND m.resolved_at IS NULL"
+		if c.QueryBool("includeResolved") {
+			resolvedFilter = ""
+		}
+
+		var rows []trendingMissedWord
+		if err := db.Raw(`
+			SELECT m.id, m.missed_words AS word, m.normalized_word,
+				SUM(CASE WHEN d.day >= ? THEN d.count ELSE 0 END) AS current_count,
+				SUM(CASE WHEN d.day < ? THEN d.count ELSE 0 END) AS previous_count
+			FROM missed_word_daily d
+			JOIN missed_words_table m ON m.id = d.missed_word_id
+			WHERE d.day >= ? `+resolvedFilter+`
+			GROUP BY m.id, m.missed_words, m.normalized_word
+			HAVING current_count >= ? AND current_count > previous_count
+			ORDER BY current_count - previous_count DESC, current_count DESC
+			LIMIT ?`,
+			currentFrom, currentFrom, previousFrom, minCount, limit,
+		).Scan(&rows).Error; err != nil {
+			log.Printf("🔴 Error while fetching trending missed words: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"status": config.AppMessages.MissedWord.FetchError,
+			})
+		}
+
+		trending := make([]trendingMissedWord, 0, len(rows))
+		for _, row := range rows {
+			row.Growth = row.CurrentCount - row.PreviousCount
+			if row.PreviousCount > 0 {
+				rate := float64(row.Growth) / float64(row.PreviousCount)
+				row.GrowthRate = &rate
+			} else {
+				row.IsNew = true
+			}
+			trending = append(trending, row)
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"trending":      trending,
+			"window_days":   days,
+			"current_from":  currentFrom,
+			"previous_from": previousFrom,
+			"min_count":     minCount,
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestParseWindowDays(t *testing.T) {
+	tests := []struct {
+		window string
+		days   int
+		ok     bool
+	}{
+		{"7d", 7, true},
+		{"7", 7, true},
+		{" 30D ", 30, true},
+		{"90d", 90, true},
+		{"91d", 0, false},
+		{"0d", 0, false},
+		{"-3d", 0, false},
+		{"week", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		if days, ok := parseWindowDays(tt.window); days != tt.days || ok != tt.ok {
+			t.Errorf("parseWindowDays(%q) = %d, %v, want %d, %v", tt.window, days, ok, tt.days, tt.ok)
+		}
+	}
+}
+
+func TestGetTrendingMissedWords(t *testing.T) {
+	db, rec := newRecordingDB(t)
+	rec.respond = func(query string) ([]string, [][]driver.Value) {
+		if !strings.Contains(query, "FROM missed_word_daily") {
+			return nil, nil
+		}
+		return []string{"id", "word", "normalized_word", "current_count", "previous_count"}, [][]driver.Value{
+			{int64(1), "Physics", "physics", int64(12), int64(4)},
+			{int64(2), "chem lab", "chem lab", int64(5), int64(0)},
+		}
+	}
+	app := newTestApp()
+	app.Get("/missed/trending", GetTrendingMissedWords(db))
+
+	status, body := doRequest(t, app, fiber.MethodGet, "/missed/trending?window=3d&limit=5&min_count=4", "")
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d (body %s)", status, body)
+	}
+	var response struct {
+		Trending   []trendingMissedWord `json:"trending"`
+		WindowDays int                  `json:"window_days"`
+	}
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatal(err)
+	}
+	rate := 2.0
+	want := []trendingMissedWord{
+		{ID: 1, Word: "Physics", NormalizedWord: "physics", CurrentCount: 12, PreviousCount: 4, Growth: 8, GrowthRate: &rate},
+		{ID: 2, Word: "chem lab", NormalizedWord: "chem lab", CurrentCount: 5, Growth: 5, IsNew: true},
+	}
+	if response.WindowDays != 3 || !reflect.DeepEqual(response.Trending, want) {
+		t.Errorf("got %d days %+v, want 3 days %+v", response.WindowDays, response.Trending, want)
+	}
+
+	// The current window is the last 3 days including today, the previous one the 3 before
+	today := time.Now()
+	currentFrom := today.AddDate(0, 0, -2).Format(time.DateOnly)
+	previousFrom := today.AddDate(0, 0, -5).Format(time.DateOnly)
+	queries := rec.Find("FROM missed_word_daily")
+	if len(queries) != 1 || !reflect.DeepEqual(queries[0], []driver.Value{currentFrom, currentFrom, previousFrom, int64(4), int64(5)}) {
+		t.Errorf("trending query args = %v", queries)
+	}
+	if len(rec.Find("m.resolved_at IS NULL")) != 1 {
+		t.Error("resolved words are not hidden by default")
+	}
+}
+
+func TestGetTrendingMissedWordsOptions(t *testing.T) {
+	tests := []struct {
+		query    string
+		status   int
+		resolved bool
+		minCount int
+	}{
+		{"?window=100d", fiber.StatusBadRequest, false, 0},
+		{"?window=soon", fiber.StatusBadRequest, false, 0},
+		{"?includeResolved=true", fiber.StatusOK, true, 2},
+		{"?limit=1000&min_count=0", fiber.StatusOK, false, 1},
+	}
+	for _, tt := range tests {
+		db, rec := newRecordingDB(t)
+		app := newTestApp()
+		app.Get("/missed/trending", GetTrendingMissedWords(db))
+
+		status, body := doRequest(t, app, fiber.MethodGet, "/missed/trending"+tt.query, "")
+		if status != tt.status {
+			t.Errorf("%s: status = %d, want %d (body %s)", tt.query, status, tt.status, body)
+			continue
+		}
+		if status != fiber.StatusOK {
+			continue
+		}
+		if !strings.Contains(body, `"trending":[]`) {
+			t.Errorf("%s: body = %s, want an empty list", tt.query, body)
+		}
+		if includesResolved := len(rec.Find("m.resolved_at IS NULL")) == 0; includesResolved != tt.resolved {
+			t.Errorf("%s: includes resolved = %v, want %v", tt.query, includesResolved, tt.resolved)
+		}
+		// Out of range limits and counts fall back to the defaults
+		if queries := rec.Find("FROM missed_word_daily"); len(queries) != 1 || !reflect.DeepEqual(queries[0][3:], []driver.Value{int64(tt.minCount), int64(20)}) {
+			t.Errorf("%s: trending query args = %v", tt.query, queries)
+		}
+	}
+}
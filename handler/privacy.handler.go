@@ -0,0 +1,320 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/jobs"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/storage"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	ErasureModeDelete    = "delete"
+	ErasureModeAnonymise = "anonymise"
+)
+
+// personalDataTables lists every table holding rows tied to a user's email
+var personalDataTables = []string{"app_users", "app_err_logs", "game_hof", "game_hof_noteDino"}
+
+// maxErasureSessions caps how many missed word session ids one erasure request may clear
+const maxErasureSessions = 100
+
+// emailHash identifies an erased email in the audit log without storing the email itself.
+// It is keyed with a server secret so the hash cannot be reversed by hashing known emails.
+func emailHash(secret, email string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(email))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// scrubIssueTitles takes the email out of issue titles quoting it. Issues are shared by every user
+// hitting the same error, so the rest of the title is kept.
+func scrubIssueTitles(tx *gorm.DB, email string) (int64, error) {
+	var issues []struct {
+		ID    int
+		Title string
+	}
+	if err := tx.Raw("SELECT id, title FROM app_err_issues WHERE title LIKE ? FOR UPDATE", "%"+email+"%").
+		Scan(&issues).Error; err != nil {
+		return 0, err
+	}
+
+	pattern := regexp.MustCompile("(?i)" + regexp.QuoteMeta(email))
+	var scrubbed int64
+	for _, issue := range issues {
+		title := pattern.ReplaceAllString(issue.Title, "[erased]")
+		if title == issue.Title {
+			continue
+		}
+		if err := tx.Exec("UPDATE app_err_issues SET title = ? WHERE id = ?", title, issue.ID).Error; err != nil {
+			return 0, err
+		}
+		scrubbed++
+	}
+	return scrubbed, nil
+}
+
+// ExportPersonalData handles exporting every row tied to an email as a single JSON bundle
+func ExportPersonalData(db *gorm.DB, appConfig config.AppConfig) fiber.Handler {
+	log.Println("🟢 GET: ExportPersonalData handler called")
+	return func(c *fiber.Ctx) error {
+		if err := utils.ValidateAdminKey(c, appConfig); err != nil {
+			return err
+		}
+
+		email := strings.ToLower(strings.TrimSpace(c.Query("email")))
+		if !utils.ValidateEmail(email) {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Privacy.InvalidEmail})
+		}
+
+		bundle := fiber.Map{
+			"email":       email,
+			"exported_at": time.Now(),
+		}
+		for _, table := range personalDataTables {
+			var rows []map[string]interface{}
+			if err := db.Raw("SELECT * FROM "+table+" WHERE email = ?", email).Scan(&rows).Error; err != nil {
+				log.Printf("🔴 Error while exporting %s rows: %v", table, err)
+				return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Privacy.OperationUnsuccessful})
+			}
+			if table == "app_err_logs" {
+				decodeErrorLogRows(rows)
+			}
+			if rows == nil {
+				rows = []map[string]interface{}{}
+			}
+			bundle[table] = rows
+		}
+
+		if c.QueryBool("download") {
+			c.Set(fiber.HeaderContentDisposition, `attachment; filename="personal-data.json"`)
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"data":   bundle,
+			"status": config.AppMessages.Privacy.ExportSuccess,
+		})
+	}
+}
+
+// ErasePersonalData handles erasing an email from every table in one transaction. In delete
+// mode the rows are removed; in anonymise mode the rows stay for aggregate stats but lose
+// anything identifying. Issue titles quoting the email lose it in both modes. Every erasure
+// leaves an audit record keyed by the email's hash, and archived error logs of the email are
+// removed from the retention archives afterwards.
+// Missed word events carry no email, so the messages a user sent are only erased for the
+// anonymous session_ids passed in the request; the response lists what was not covered.
+func ErasePersonalData(db *gorm.DB, store storage.Storage, retention *jobs.ErrorLogRetention,
+	privacyConfig config.PrivacyConfig, appConfig config.AppConfig) fiber.Handler {
+	log.Println("🔵 POST: ErasePersonalData handler called")
+	return func(c *fiber.Ctx) error {
+		if err := utils.ValidateAdminKey(c, appConfig); err != nil {
+			return err
+		}
+
+		var body struct {
+			Email      string   `json:"email"`
+			Mode       string   `json:"mode"`
+			Reason     string   `json:"reason"`
+			SessionIDs []string `json:"session_ids"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Privacy.BadRequest})
+		}
+
+		email := strings.ToLower(strings.TrimSpace(body.Email))
+		if !utils.ValidateEmail(email) {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Privacy.InvalidEmail})
+		}
+		if body.Mode == "" {
+			body.Mode = ErasureModeAnonymise
+		}
+		if body.Mode != ErasureModeDelete && body.Mode != ErasureModeAnonymise {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Privacy.InvalidMode})
+		}
+
+		sessionIDs := []string{}
+		for _, sessionID := range body.SessionIDs {
+			sessionID = strings.TrimSpace(sessionID)
+			if sessionID == "" {
+				continue
+			}
+			if len(sessionID) > maxMissedWordSessionLen {
+				return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Privacy.InvalidSessions})
+			}
+			sessionIDs = append(sessionIDs, sessionID)
+		}
+		if len(sessionIDs) > maxErasureSessions {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Privacy.InvalidSessions})
+		}
+
+		hash := emailHash(privacyConfig.HASH_SECRET, email)
+		anonymousEmail := "erased-" + hash[:16] + "@erased.invalid"
+		now := time.Now()
+
+		// Anonymised rows keep the non-identifying columns that feed the stats endpoints. Error logs
+		// keep their date, os, versions and fingerprint, but the message and stack trace can quote
+		// user input and the device details help single a user out.
+		anonymise := map[string]struct {
+			query  string
+			params []interface{}
+		}{
+			"app_users": {
+				"UPDATE app_users SET email = ?, uni_id = 'erased', img_url = ?, deleted_at = COALESCE(deleted_at, ?) WHERE email = ?",
+				[]interface{}{anonymousEmail, defaultUserImgUrl, now, email},
+			},
+			"app_err_logs": {
+				`UPDATE app_err_logs
+				SET email = ?, log = 'erased', stack_trace = NULL, tags = NULL, device_model = NULL, screen = NULL, app_build = NULL
+				WHERE email = ?`,
+				[]interface{}{anonymousEmail, email},
+			},
+			"game_hof": {
+				"UPDATE game_hof SET email = ?, user_name = 'erased' WHERE email = ?",
+				[]interface{}{anonymousEmail, email},
+			},
+			"game_hof_noteDino": {
+				"UPDATE game_hof_noteDino SET email = ?, user_name = 'erased' WHERE email = ?",
+				[]interface{}{anonymousEmail, email},
+			},
+		}
+
+		rowsAffected := map[string]int64{}
+		var avatars []string
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Raw("SELECT img_url FROM app_users WHERE email = ?", email).Scan(&avatars).Error; err != nil {
+				return err
+			}
+
+			for _, table := range personalDataTables {
+				var result *gorm.DB
+				if body.Mode == ErasureModeDelete {
+					result = tx.Exec("DELETE FROM "+table+" WHERE email = ?", email)
+				} else {
+					result = tx.Exec(anonymise[table].query, anonymise[table].params...)
+				}
+				if result.Error != nil {
+					return result.Error
+				}
+				rowsAffected[table] = result.RowsAffected
+			}
+
+			// Issues are never deleted, their titles lose the email in both modes
+			scrubbed, err := scrubIssueTitles(tx, email)
+			if err != nil {
+				return err
+			}
+			rowsAffected["app_err_issues"] = scrubbed
+
+			if len(sessionIDs) > 0 {
+				var result *gorm.DB
+				if body.Mode == ErasureModeDelete {
+					result = tx.Exec("DELETE FROM missed_word_events WHERE session_id IN ?", sessionIDs)
+				} else {
+					result = tx.Exec("UPDATE missed_word_events SET previous_message = NULL, session_id = NULL WHERE session_id IN ?", sessionIDs)
+				}
+				if result.Error != nil {
+					return result.Error
+				}
+				rowsAffected["missed_word_events"] = result.RowsAffected
+			}
+
+			affected, err := json.Marshal(rowsAffected)
+			if err != nil {
+				return err
+			}
+			return tx.Exec(`
+				INSERT INTO data_erasure_audit (email_hash, mode, rows_affected, reason, requested_ip, created_at)
+				VALUES (?, ?, ?, ?, ?, ?)`,
+				hash, body.Mode, string(affected), utils.NullIfEmpty(body.Reason), c.IP(), now,
+			).Error
+		})
+		if err != nil {
+			log.Printf("🔴 Error while erasing personal data: %v", err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Privacy.OperationUnsuccessful})
+		}
+
+		// Retention may have archived the email's error logs before they were erased
+		archives, err := retention.ScrubArchives(email)
+		if err != nil {
+			log.Printf("🔴 Error while scrubbing error log archives: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"email_hash":    hash,
+				"rows_affected": rowsAffected,
+				"status":        config.AppMessages.Privacy.ArchiveScrubFailed,
+			})
+		}
+
+		// Uploaded profile images are personal data too, remove them once the rows are gone
+		for _, avatar := range avatars {
+			if !store.Owns(avatar) {
+				continue
+			}
+			if err := store.Delete(avatar); err != nil {
+				log.Printf("🔴 Error while removing avatar %s: %v", avatar, err)
+			}
+		}
+
+		notCovered := []string{}
+		if len(sessionIDs) == 0 {
+			notCovered = append(notCovered, config.AppMessages.Privacy.SessionsNotErased)
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"mode":          body.Mode,
+			"email_hash":    hash,
+			"rows_affected": rowsAffected,
+			"archives":      archives,
+			"not_covered":   notCovered,
+			"status":        config.AppMessages.Privacy.EraseSuccess,
+		})
+	}
+}
+
+// GetErasureAudit handles listing erasure audit records, optionally for one email
+func GetErasureAudit(db *gorm.DB, privacyConfig config.PrivacyConfig, appConfig config.AppConfig) fiber.Handler {
+	log.Println("🟢 GET: GetErasureAudit handler called")
+	return func(c *fiber.Ctx) error {
+		if err := utils.ValidateAdminKey(c, appConfig); err != nil {
+			return err
+		}
+
+		query := "SELECT * FROM data_erasure_audit"
+		params := []interface{}{}
+		if email := strings.ToLower(strings.TrimSpace(c.Query("email"))); email != "" {
+			query += " WHERE email_hash = ?"
+			params = append(params, emailHash(privacyConfig.HASH_SECRET, email))
+		}
+		query += " ORDER BY created_at DESC LIMIT 500"
+
+		var records []map[string]interface{}
+		if err := db.Raw(query, params...).Scan(&records).Error; err != nil {
+			log.Printf("🔴 Error while fetching erasure audit: %v", err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Privacy.OperationUnsuccessful})
+		}
+		for _, record := range records {
+			if raw, ok := record["rows_affected"].(string); ok {
+				var decoded map[string]int64
+				if err := json.Unmarshal([]byte(raw), &decoded); err == nil {
+					record["rows_affected"] = decoded
+				}
+			}
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"audit":  records,
+			"status": config.AppMessages.Privacy.AuditFetchSuccess,
+		})
+	}
+}
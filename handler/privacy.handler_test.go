@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"compress/gzip"
+	"database/sql/driver"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/jobs"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/storage"
+	"github.com/gofiber/fiber/v2"
+)
+
+var testPrivacyConfig = config.PrivacyConfig{HASH_SECRET: "test-secret"}
+
+// newPrivacyApp registers the privacy routes with an archive dir holding one archived log of email
+func newPrivacyApp(t *testing.T, email string) (*fiber.App, *recorder, string) {
+	t.Helper()
+	db, rec := newRecordingDB(t)
+	archiveDir := t.TempDir()
+
+	archivePath := filepath.Join(archiveDir, "app_err_logs-20260101T000000.jsonl.gz")
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(file)
+	json.NewEncoder(gz).Encode(map[string]interface{}{"id": 1, "email": email, "log": "boom"})
+	gz.Close()
+	file.Close()
+
+	retention := jobs.NewErrorLogRetention(db, config.RetentionConfig{ARCHIVE_DIR: archiveDir})
+	store := storage.NewLocalStorage(t.TempDir(), "/uploads")
+
+	app := newTestApp()
+	app.Get("/privacy/export", ExportPersonalData(db, testAppConfig))
+	app.Post("/privacy/erase", ErasePersonalData(db, store, retention, testPrivacyConfig, testAppConfig))
+	app.Get("/privacy/audit", GetErasureAudit(db, testPrivacyConfig, testAppConfig))
+	return app, rec, archivePath
+}
+
+func TestPrivacyHandlersRequireAdminKey(t *testing.T) {
+	const email = "student@butex.edu.bd"
+	app, rec, archivePath := newPrivacyApp(t, email)
+	before, err := os.ReadFile(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertUnauthorized(t, app, rec, fiber.MethodGet, "/privacy/export?email="+email, "")
+	assertUnauthorized(t, app, rec, fiber.MethodPost, "/privacy/erase", `{"email":"`+email+`","mode":"delete"}`)
+	assertUnauthorized(t, app, rec, fiber.MethodGet, "/privacy/audit", "")
+
+	after, err := os.ReadFile(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(before) != string(after) {
+		t.Fatal("unauthorized erase rewrote the archive")
+	}
+}
+
+func TestEmailHash(t *testing.T) {
+	const email = "student@butex.edu.bd"
+	hash := emailHash("secret", email)
+	if len(hash) != 64 {
+		t.Errorf("hash %q does not fit CHAR(64)", hash)
+	}
+	if hash != emailHash("secret", email) {
+		t.Error("hash is not deterministic")
+	}
+	if hash == emailHash("other-secret", email) {
+		t.Error("hash does not depend on the secret")
+	}
+}
+
+func TestErasePersonalDataAnonymise(t *testing.T) {
+	const email = "student@butex.edu.bd"
+	app, rec, archivePath := newPrivacyApp(t, email)
+
+	status, body := doRequest(t, app, fiber.MethodPost, "/privacy/erase?adminKey="+testAdminKey,
+		`{"email":"Student@butex.edu.bd","session_ids":["session-1"," "]}`)
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d (body %s)", status, body)
+	}
+
+	var response struct {
+		EmailHash  string   `json:"email_hash"`
+		NotCovered []string `json:"not_covered"`
+		Archives   struct {
+			RowsRemoved int64 `json:"rows_removed"`
+		} `json:"archives"`
+	}
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.EmailHash != emailHash(testPrivacyConfig.HASH_SECRET, email) {
+		t.Errorf("email_hash = %s, want the keyed hash", response.EmailHash)
+	}
+	if response.Archives.RowsRemoved != 1 {
+		t.Errorf("archives rows_removed = %d, want 1", response.Archives.RowsRemoved)
+	}
+	if len(response.NotCovered) != 0 {
+		t.Errorf("not_covered = %v, want nothing when session ids are given", response.NotCovered)
+	}
+
+	var errLogUpdate string
+	for _, statement := range rec.Statements() {
+		if strings.Contains(statement, "UPDATE app_err_logs") {
+			errLogUpdate = statement
+		}
+	}
+	for _, column := range []string{"log = 'erased'", "stack_trace = NULL", "tags = NULL", "device_model = NULL", "screen = NULL", "app_build = NULL"} {
+		if !strings.Contains(errLogUpdate, column) {
+			t.Errorf("error logs are not anonymised with %s: %q", column, errLogUpdate)
+		}
+	}
+
+	events := rec.Find("UPDATE missed_word_events SET previous_message = NULL")
+	if len(events) != 1 || len(events[0]) != 1 || events[0][0] != "session-1" {
+		t.Errorf("missed word event updates = %v, want session-1 only", events)
+	}
+
+	audits := rec.Find("INSERT INTO data_erasure_audit")
+	if len(audits) != 1 || audits[0][0] != response.EmailHash {
+		t.Errorf("audit inserts = %v, want one keyed by the hash", audits)
+	}
+
+	if data, _ := os.ReadFile(archivePath); strings.Contains(string(data), email) {
+		t.Error("archive still holds the erased email")
+	}
+}
+
+func TestErasePersonalDataScrubsIssueTitles(t *testing.T) {
+	for _, mode := range []string{ErasureModeAnonymise, ErasureModeDelete} {
+		t.Run(mode, func(t *testing.T) {
+			app, rec, _ := newPrivacyApp(t, "student@butex.edu.bd")
+			rec.respond = func(query string) ([]string, [][]driver.Value) {
+				if !strings.Contains(query, "FROM app_err_issues") {
+					return nil, nil
+				}
+				return []string{"id", "title"}, [][]driver.Value{
+					{int64(4), "No account for Student@butex.edu.bd in cache"},
+					{int64(5), "student@butex_edu.bd is not the same email"},
+				}
+			}
+
+			status, body := doRequest(t, app, fiber.MethodPost, "/privacy/erase?adminKey="+testAdminKey,
+				`{"email":"student@butex.edu.bd","mode":"`+mode+`"}`)
+			if status != fiber.StatusOK {
+				t.Fatalf("status = %d (body %s)", status, body)
+			}
+
+			lookups := rec.Find("FROM app_err_issues WHERE title LIKE ?")
+			if len(lookups) != 1 || lookups[0][0] != "%student@butex.edu.bd%" {
+				t.Errorf("issue lookups = %v", lookups)
+			}
+			updates := rec.Find("UPDATE app_err_issues SET title = ?")
+			want := []driver.Value{"No account for [erased] in cache", int64(4)}
+			if len(updates) != 1 || !reflect.DeepEqual(updates[0], want) {
+				t.Errorf("issue title updates = %v, want %v", updates, want)
+			}
+			if !strings.Contains(body, `"app_err_issues":1`) {
+				t.Errorf("rows_affected does not report the scrubbed issue: %s", body)
+			}
+		})
+	}
+}
+
+func TestErasePersonalDataDelete(t *testing.T) {
+	app, rec, _ := newPrivacyApp(t, "other@butex.edu.bd")
+
+	status, body := doRequest(t, app, fiber.MethodPost, "/privacy/erase?adminKey="+testAdminKey,
+		`{"email":"student@butex.edu.bd","mode":"delete"}`)
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d (body %s)", status, body)
+	}
+	for _, table := range personalDataTables {
+		deletes := rec.Find("DELETE FROM " + table + " WHERE email = ?")
+		if len(deletes) != 1 || deletes[0][0] != "student@butex.edu.bd" {
+			t.Errorf("%s deletes = %v, want one for the email", table, deletes)
+		}
+	}
+	if len(rec.Find("UPDATE app_users")) != 0 {
+		t.Error("delete mode anonymised rows instead of deleting them")
+	}
+}
+
+func TestErasePersonalDataReportsUncoveredEvents(t *testing.T) {
+	app, rec, _ := newPrivacyApp(t, "other@butex.edu.bd")
+
+	status, body := doRequest(t, app, fiber.MethodPost, "/privacy/erase?adminKey="+testAdminKey,
+		`{"email":"student@butex.edu.bd","mode":"delete"}`)
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d (body %s)", status, body)
+	}
+	if !strings.Contains(body, config.AppMessages.Privacy.SessionsNotErased) {
+		t.Errorf("response does not report the missed word event gap: %s", body)
+	}
+	if len(rec.Find("missed_word_events")) != 0 {
+		t.Error("touched missed word events without session ids")
+	}
+	if len(rec.Find("DELETE FROM app_err_logs")) != 1 {
+		t.Error("delete mode did not delete error logs")
+	}
+}
+
+func TestErasePersonalDataValidation(t *testing.T) {
+	app, rec, _ := newPrivacyApp(t, "student@butex.edu.bd")
+
+	tests := []string{
+		`{"email":"not-an-email"}`,
+		`{"email":"student@butex.edu.bd","mode":"shred"}`,
+		`{"email":"student@butex.edu.bd","session_ids":["` + strings.Repeat("s", maxMissedWordSessionLen+1) + `"]}`,
+	}
+	for _, body := range tests {
+		if status, _ := doRequest(t, app, fiber.MethodPost, "/privacy/erase?adminKey="+testAdminKey, body); status != fiber.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, status)
+		}
+	}
+	if statements := rec.Statements(); len(statements) != 0 {
+		t.Errorf("invalid requests reached the database: %v", statements)
+	}
+}
+
+func TestErasePersonalDataRemovesAvatars(t *testing.T) {
+	db, rec := newRecordingDB(t)
+	store := storage.NewLocalStorage(t.TempDir(), "/uploads")
+	avatar, err := store.Save("avatars/1.png", strings.NewReader("png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.respond = func(query string) ([]string, [][]driver.Value) {
+		if !strings.Contains(query, "SELECT img_url FROM app_users") {
+			return nil, nil
+		}
+		return []string{"img_url"}, [][]driver.Value{{avatar}, {"https://example.com/me.png"}}
+	}
+
+	retention := jobs.NewErrorLogRetention(db, config.RetentionConfig{})
+	app := newTestApp()
+	app.Post("/privacy/erase", ErasePersonalData(db, store, retention, testPrivacyConfig, testAppConfig))
+
+	status, body := doRequest(t, app, fiber.MethodPost, "/privacy/erase?adminKey="+testAdminKey,
+		`{"email":"student@butex.edu.bd","mode":"delete"}`)
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d (body %s)", status, body)
+	}
+	if _, err := os.Stat(filepath.Join(store.Dir, "avatars", "1.png")); !os.IsNotExist(err) {
+		t.Errorf("uploaded avatar was not removed: %v", err)
+	}
+}
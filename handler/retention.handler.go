@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"log"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/jobs"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PreviewErrorLogRetention shows what the retention job would remove, without removing anything
+func PreviewErrorLogRetention(retention *jobs.ErrorLogRetention, appConfig config.AppConfig) fiber.Handler {
+	log.Println("🟢 GET: PreviewErrorLogRetention handler called")
+	return func(c *fiber.Ctx) error {
+		if err := utils.ValidateAdminKey(c, appConfig); err != nil {
+			return err
+		}
+
+		sampleSize := c.QueryInt("sample", 20)
+		if sampleSize < 0 || sampleSize > 500 {
+			sampleSize = 20
+		}
+
+		preview, err := retention.Preview(sampleSize)
+		if err != nil {
+			log.Printf("🔴 Error while previewing error log retention: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"status": config.AppMessages.ErrorLog.OperationUnsuccessful,
+			})
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"dry_run": true,
+			"enabled": retention.Enabled(),
+			"preview": preview,
+			"status":  config.AppMessages.ErrorLog.RetentionPreviewSuccess,
+		})
+	}
+}
+
+// RunErrorLogRetention runs the retention job immediately instead of waiting for the schedule
+func RunErrorLogRetention(retention *jobs.ErrorLogRetention, appConfig config.AppConfig) fiber.Handler {
+	log.Println("🔵 POST: RunErrorLogRetention handler called")
+	return func(c *fiber.Ctx) error {
+		if err := utils.ValidateAdminKey(c, appConfig); err != nil {
+			return err
+		}
+
+		if !retention.Enabled() {
+			return c.Status(400).JSON(fiber.Map{
+				"status": config.AppMessages.ErrorLog.RetentionDisabled,
+			})
+		}
+
+		result, err := retention.Run()
+		if err != nil {
+			log.Printf("🔴 Error while running error log retention: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"result": result,
+				"status": config.AppMessages.ErrorLog.OperationUnsuccessful,
+			})
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"result": result,
+			"status": config.AppMessages.ErrorLog.RetentionRunSuccess,
+		})
+	}
+}
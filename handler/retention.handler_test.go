@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"compress/gzip"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/jobs"
+	"github.com/gofiber/fiber/v2"
+)
+
+func newTestRetention(t *testing.T) (*jobs.ErrorLogRetention, *recorder, string) {
+	t.Helper()
+	db, rec := newRecordingDB(t)
+	archiveDir := t.TempDir()
+
+	// The first batch holds one expired log, every later batch is empty
+	served := false
+	rec.respond = func(query string) ([]string, [][]driver.Value) {
+		if served || !strings.HasPrefix(query, "SELECT * FROM app_err_logs") {
+			return nil, nil
+		}
+		served = true
+		return []string{"id", "date", "log"}, [][]driver.Value{{int64(1), time.Now().AddDate(-1, 0, 0), "old"}}
+	}
+
+	retention := jobs.NewErrorLogRetention(db, config.RetentionConfig{
+		MAX_AGE:     24 * time.Hour,
+		MODE:        config.RetentionModeArchive,
+		ARCHIVE_DIR: archiveDir,
+		BATCH_SIZE:  100,
+	})
+	return retention, rec, archiveDir
+}
+
+func TestRetentionHandlersRequireAdminKey(t *testing.T) {
+	retention, rec, archiveDir := newTestRetention(t)
+	app := newTestApp()
+	app.Get("/errors/retention/preview", PreviewErrorLogRetention(retention, testAppConfig))
+	app.Post("/errors/retention/run", RunErrorLogRetention(retention, testAppConfig))
+
+	assertUnauthorized(t, app, rec, fiber.MethodGet, "/errors/retention/preview", "")
+	assertUnauthorized(t, app, rec, fiber.MethodPost, "/errors/retention/run", "")
+
+	entries, err := os.ReadDir(archiveDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("unauthorized run wrote %d archive files", len(entries))
+	}
+}
+
+func TestRunErrorLogRetention(t *testing.T) {
+	retention, rec, archiveDir := newTestRetention(t)
+	rec.affected = func(string) int64 { return 1 }
+	app := newTestApp()
+	app.Post("/errors/retention/run", RunErrorLogRetention(retention, testAppConfig))
+
+	status, body := doRequest(t, app, fiber.MethodPost, "/errors/retention/run?adminKey="+testAdminKey, "")
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200 (body %s)", status, body)
+	}
+	var response struct {
+		Result jobs.RetentionResult `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Result.RowsRemoved != 1 || response.Result.Mode != config.RetentionModeArchive {
+		t.Errorf("result = %+v, want one archived row", response.Result)
+	}
+
+	deletes := rec.Find("DELETE FROM app_err_logs WHERE id IN")
+	if len(deletes) != 1 || !reflect.DeepEqual(deletes[0], []driver.Value{int64(1)}) {
+		t.Errorf("deletes = %v, want the expired log", deletes)
+	}
+
+	// The archive holds the deleted row
+	entries, _ := os.ReadDir(archiveDir)
+	if len(entries) != 1 || filepath.Join(archiveDir, entries[0].Name()) != response.Result.ArchiveFile {
+		t.Fatalf("archive files = %v, want %s", entries, response.Result.ArchiveFile)
+	}
+	file, err := os.Open(response.Result.ArchiveFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archived, _ := io.ReadAll(gz)
+	if !strings.Contains(string(archived), `"log":"old"`) {
+		t.Errorf("archive = %s, want the expired log", archived)
+	}
+}
+
+func TestPreviewErrorLogRetention(t *testing.T) {
+	retention, rec, archiveDir := newTestRetention(t)
+	rec.respond = func(query string) ([]string, [][]driver.Value) {
+		if strings.Contains(query, "COUNT(*) AS total") {
+			return []string{"total", "oldest", "newest"}, [][]driver.Value{{int64(12), time.Now().AddDate(-1, 0, 0), time.Now().AddDate(0, 0, -2)}}
+		}
+		return nil, nil
+	}
+	app := newTestApp()
+	app.Get("/errors/retention/preview", PreviewErrorLogRetention(retention, testAppConfig))
+
+	status, body := doRequest(t, app, fiber.MethodGet, "/errors/retention/preview?adminKey="+testAdminKey+"&sample=5", "")
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200 (body %s)", status, body)
+	}
+	var response struct {
+		Preview jobs.RetentionPreview `json:"preview"`
+	}
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Preview.RowsToRemove != 12 || response.Preview.OldestLogDate == nil {
+		t.Errorf("preview = %+v, want 12 rows to remove", response.Preview)
+	}
+	if samples := rec.Find("ORDER BY date ASC LIMIT ?"); len(samples) != 1 || samples[0][1] != int64(5) {
+		t.Errorf("sample queries = %v, want a sample of 5", samples)
+	}
+	for _, statement := range rec.Statements() {
+		if strings.HasPrefix(statement, "DELETE") {
+			t.Errorf("preview ran %s", statement)
+		}
+	}
+	if entries, _ := os.ReadDir(archiveDir); len(entries) != 0 {
+		t.Errorf("preview wrote %d archive files", len(entries))
+	}
+}
+
+func TestRunErrorLogRetentionDisabled(t *testing.T) {
+	db, rec := newRecordingDB(t)
+	retention := jobs.NewErrorLogRetention(db, config.RetentionConfig{MODE: config.RetentionModeDelete})
+	app := newTestApp()
+	app.Post("/errors/retention/run", RunErrorLogRetention(retention, testAppConfig))
+
+	if status, body := doRequest(t, app, fiber.MethodPost, "/errors/retention/run?adminKey="+testAdminKey, ""); status != fiber.StatusBadRequest {
+		t.Errorf("status = %d, want 400 (body %s)", status, body)
+	}
+	if statements := rec.Statements(); len(statements) != 0 {
+		t.Errorf("disabled retention ran %v", statements)
+	}
+}
@@ -0,0 +1,504 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/utils"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Subject is an entry of the subject catalog. Code is the URL segment under /notes or /labs,
+// SubName the row in subnamedb or labsdb that keeps its access count.
+type Subject struct {
+	ID          int64     `json:"id"`
+	Code        string    `json:"code"`
+	Type        string    `json:"type"`
+	SubName     string    `json:"sub_name"`
+	DisplayName string    `json:"display_name"`
+	Dept        *string   `json:"dept"`
+	Semester    *int      `json:"semester"`
+	Active      bool      `json:"active"`
+	Pending     bool      `json:"pending"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// subjectInput is a create or update request; fields left out are not changed
+type subjectInput struct {
+	Code        *string `json:"code"`
+	Type        *string `json:"type"`
+	SubName     *string `json:"sub_name"`
+	DisplayName *string `json:"display_name"`
+	Dept        *string `json:"dept"`
+	Semester    *int    `json:"semester"`
+	Active      *bool   `json:"active"`
+}
+
+// Tables keeping the access counts of each subject type
+var subjectCountTables = map[string]struct{ table, column string }{
+	config.SubjectTypeNote: {"subnamedb", "sub_name"},
+	config.SubjectTypeLab:  {"labsdb", "lab_name"},
+}
+
+var subjectCodeRegex = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+const (
+	maxSubjectNameLen        = 64
+	maxSubjectDisplayNameLen = 128
+)
+
+type subjectColumn struct {
+	column string
+	value  interface{}
+}
+
+// validateSubject normalises the given fields and returns the columns to write and the errors per
+// invalid field. An empty dept or a zero semester clears the value.
+func validateSubject(input subjectInput) ([]subjectColumn, map[string]string) {
+	columns := []subjectColumn{}
+	fieldErrors := map[string]string{}
+
+	if input.Code != nil {
+		code := strings.ToLower(strings.TrimSpace(*input.Code))
+		if !subjectCodeRegex.MatchString(code) {
+			fieldErrors["code"] = config.AppMessages.Validation.InvalidFormat
+		}
+		columns = append(columns, subjectColumn{"code", code})
+	}
+	if input.Type != nil {
+		subjectType := strings.ToLower(strings.TrimSpace(*input.Type))
+		if _, ok := subjectCountTables[subjectType]; !ok {
+			fieldErrors["type"] = config.AppMessages.Academic.InvalidSubjectType
+		}
+		columns = append(columns, subjectColumn{"type", subjectType})
+	}
+	if input.SubName != nil {
+		subName := strings.TrimSpace(*input.SubName)
+		if subName == "" {
+			fieldErrors["sub_name"] = config.AppMessages.Validation.Required
+		} else if utf8.RuneCountInString(subName) > maxSubjectNameLen {
+			fieldErrors["sub_name"] = config.AppMessages.Validation.TooLong
+		}
+		columns = append(columns, subjectColumn{"sub_name", subName})
+	}
+	if input.DisplayName != nil {
+		displayName := strings.TrimSpace(*input.DisplayName)
+		if displayName == "" {
+			fieldErrors["display_name"] = config.AppMessages.Validation.Required
+		} else if utf8.RuneCountInString(displayName) > maxSubjectDisplayNameLen {
+			fieldErrors["display_name"] = config.AppMessages.Validation.TooLong
+		}
+		columns = append(columns, subjectColumn{"display_name", displayName})
+	}
+	if input.Dept != nil {
+		var dept interface{}
+		if value := strings.TrimSpace(*input.Dept); value != "" {
+			code, ok := utils.NormalizeDept(value)
+			if !ok {
+				fieldErrors["dept"] = config.AppMessages.Validation.InvalidDept
+			}
+			dept = code
+		}
+		columns = append(columns, subjectColumn{"dept", dept})
+	}
+	if input.Semester != nil {
+		var semester interface{}
+		if *input.Semester != 0 {
+			if *input.Semester < config.SubjectSemesterMin || *input.Semester > config.SubjectSemesterMax {
+				fieldErrors["semester"] = config.AppMessages.Validation.SemesterOutOfRange
+			}
+			semester = *input.Semester
+		}
+		columns = append(columns, subjectColumn{"semester", semester})
+	}
+	if input.Active != nil {
+		columns = append(columns, subjectColumn{"active", *input.Active})
+		// Activating a pending subject is how an admin accepts it
+		if *input.Active {
+			columns = append(columns, subjectColumn{"pending", false})
+		}
+	}
+
+	return columns, fieldErrors
+}
+
+// subjectColumnValue returns the value validateSubject gave the column, or fallback when the
+// column is not being written
+func subjectColumnValue(columns []subjectColumn, column string, fallback interface{}) interface{} {
+	for _, c := range columns {
+		if c.column == column {
+			return c.value
+		}
+	}
+	return fallback
+}
+
+// ensureSubjectCountRow adds the subnamedb or labsdb row that counts the accesses of a subject,
+// unless it has one already
+func ensureSubjectCountRow(tx *gorm.DB, subjectType, subName string) error {
+	countTable := subjectCountTables[subjectType]
+	return tx.Exec("INSERT INTO "+countTable.table+" ("+countTable.column+", count) SELECT ?, 0 FROM DUAL "+
+		"WHERE NOT EXISTS (SELECT 1 FROM "+countTable.table+" WHERE "+countTable.column+" = ?)",
+		subName, subName).Error
+}
+
+// findSubject returns the catalog entry matching the condition, or nil if there is none
+func findSubject(db *gorm.DB, condition string, args ...interface{}) (*Subject, error) {
+	var subjects []Subject
+	if err := db.Raw("SELECT * FROM subject_catalog WHERE "+condition+" LIMIT 1", args...).
+		Scan(&subjects).Error; err != nil {
+		return nil, err
+	}
+	if len(subjects) == 0 {
+		return nil, nil
+	}
+	return &subjects[0], nil
+}
+
+// GetSubjectCatalog handles listing the subject catalog. Filters: type (note or lab), dept,
+// semester, active and pending (true or false, both are listed when left out).
+func GetSubjectCatalog(db *gorm.DB) fiber.Handler {
+	log.Println("🟢 GET: GetSubjectCatalog handler called")
+	return func(c *fiber.Ctx) error {
+		clauses := []string{"1=1"}
+		params := []interface{}{}
+
+		if subjectType := c.Query("type"); subjectType != "" {
+			if _, ok := subjectCountTables[subjectType]; !ok {
+				return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Academic.InvalidSubjectType})
+			}
+			clauses = append(clauses, "type = ?")
+			params = append(params, subjectType)
+		}
+		if dept := c.Query("dept"); dept != "" {
+			code, ok := utils.NormalizeDept(dept)
+			if !ok {
+				return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Validation.InvalidDept})
+			}
+			clauses = append(clauses, "dept = ?")
+			params = append(params, code)
+		}
+		if semester := c.QueryInt("semester", 0); semester != 0 {
+			clauses = append(clauses, "semester = ?")
+			params = append(params, semester)
+		}
+		if active := c.Query("active"); active != "" {
+			clauses = append(clauses, "active = ?")
+			params = append(params, c.QueryBool("active"))
+		}
+		if pending := c.Query("pending"); pending != "" {
+			clauses = append(clauses, "pending = ?")
+			params = append(params, c.QueryBool("pending"))
+		}
+
+		subjects := []Subject{}
+		if err := db.Raw("SELECT * FROM subject_catalog WHERE "+strings.Join(clauses, " AND ")+" ORDER BY type, code",
+			params...).Scan(&subjects).Error; err != nil {
+			log.Printf("🔴 Error while fetching subject catalog: %v", err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Academic.OperationUnsuccessful})
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"subjects": subjects,
+			"total":    len(subjects),
+		})
+	}
+}
+
+// GetSubjectCatalogEntry handles fetching one catalog entry by id
+func GetSubjectCatalogEntry(db *gorm.DB) fiber.Handler {
+	log.Println("🟢 GET: GetSubjectCatalogEntry handler called")
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil || id < 1 {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Academic.BadRequest})
+		}
+
+		subject, err := findSubject(db, "id = ?", id)
+		if err != nil {
+			log.Printf("🔴 Error while fetching subject %d: %v", id, err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Academic.OperationUnsuccessful})
+		}
+		if subject == nil {
+			return c.Status(404).JSON(fiber.Map{"status": config.AppMessages.Academic.SubjectNotFound})
+		}
+
+		return c.Status(200).JSON(fiber.Map{"subject": subject})
+	}
+}
+
+// CreateSubjectCatalogEntry handles adding a subject to the catalog. code and type are required,
+// sub_name defaults to the code and display_name to the upper cased code. The subnamedb or labsdb
+// row counting the subject is added with it.
+func CreateSubjectCatalogEntry(db *gorm.DB) fiber.Handler {
+	log.Println("🔵 POST: CreateSubjectCatalogEntry handler called")
+	return func(c *fiber.Ctx) error {
+		if c.Query("adminKey") != config.GetAppConfig().ADMIN_AUTH_KEY {
+			return c.Status(401).JSON(fiber.Map{"error": config.AppMessages.Academic.UnauthorizedAccess})
+		}
+
+		var input subjectInput
+		if err := c.BodyParser(&input); err != nil {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Academic.BadRequest})
+		}
+
+		required := map[string]bool{}
+		if input.Code == nil {
+			required["code"] = true
+		}
+		if input.Type == nil {
+			required["type"] = true
+		}
+		if input.Code != nil {
+			code := strings.ToLower(strings.TrimSpace(*input.Code))
+			if input.SubName == nil {
+				input.SubName = &code
+			}
+			if input.DisplayName == nil {
+				displayName := strings.ToUpper(code)
+				input.DisplayName = &displayName
+			}
+		}
+
+		columns, fieldErrors := validateSubject(input)
+		for field := range required {
+			fieldErrors[field] = config.AppMessages.Validation.Required
+		}
+		if len(fieldErrors) > 0 {
+			return c.Status(400).JSON(fiber.Map{
+				"status": config.AppMessages.Academic.ValidationFailed,
+				"errors": fieldErrors,
+			})
+		}
+
+		names := []string{}
+		placeholders := []string{}
+		params := []interface{}{}
+		for _, column := range columns {
+			names = append(names, column.column)
+			placeholders = append(placeholders, "?")
+			params = append(params, column.value)
+		}
+
+		var id int64
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("INSERT INTO subject_catalog ("+strings.Join(names, ", ")+") VALUES ("+
+				strings.Join(placeholders, ", ")+")", params...).Error; err != nil {
+				return err
+			}
+			if err := tx.Raw("SELECT LAST_INSERT_ID()").Scan(&id).Error; err != nil {
+				return err
+			}
+			return ensureSubjectCountRow(tx, subjectColumnValue(columns, "type", "").(string),
+				subjectColumnValue(columns, "sub_name", "").(string))
+		})
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return c.Status(409).JSON(fiber.Map{"status": config.AppMessages.Academic.SubjectExists})
+		}
+		if err != nil {
+			log.Printf("🔴 Error while creating subject: %v", err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Academic.OperationUnsuccessful})
+		}
+
+		subject, err := findSubject(db, "id = ?", id)
+		if err != nil {
+			log.Printf("🔴 Error while fetching subject %d: %v", id, err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Academic.OperationUnsuccessful})
+		}
+
+		return c.Status(201).JSON(fiber.Map{
+			"subject": subject,
+			"status":  config.AppMessages.Academic.SubjectSaveSuccess,
+		})
+	}
+}
+
+// UpdateSubjectCatalogEntry handles changing the given fields of a catalog entry. An entry that is
+// active afterwards, such as an accepted pending subject, gets its subnamedb or labsdb row if it
+// has none yet.
+func UpdateSubjectCatalogEntry(db *gorm.DB) fiber.Handler {
+	log.Println("🟠 PATCH: UpdateSubjectCatalogEntry handler called")
+	return func(c *fiber.Ctx) error {
+		if c.Query("adminKey") != config.GetAppConfig().ADMIN_AUTH_KEY {
+			return c.Status(401).JSON(fiber.Map{"error": config.AppMessages.Academic.UnauthorizedAccess})
+		}
+
+		id, err := c.ParamsInt("id")
+		if err != nil || id < 1 {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Academic.BadRequest})
+		}
+
+		var input subjectInput
+		if err := c.BodyParser(&input); err != nil {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Academic.BadRequest})
+		}
+
+		columns, fieldErrors := validateSubject(input)
+		if len(fieldErrors) > 0 {
+			return c.Status(400).JSON(fiber.Map{
+				"status": config.AppMessages.Academic.ValidationFailed,
+				"errors": fieldErrors,
+			})
+		}
+		if len(columns) == 0 {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Academic.BadRequest})
+		}
+
+		existing, err := findSubject(db, "id = ?", id)
+		if err != nil {
+			log.Printf("🔴 Error while fetching subject %d: %v", id, err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Academic.OperationUnsuccessful})
+		}
+		if existing == nil {
+			return c.Status(404).JSON(fiber.Map{"status": config.AppMessages.Academic.SubjectNotFound})
+		}
+
+		setClauses := []string{"updated_at = ?"}
+		params := []interface{}{time.Now()}
+		for _, column := range columns {
+			setClauses = append(setClauses, column.column+" = ?")
+			params = append(params, column.value)
+		}
+		params = append(params, id)
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("UPDATE subject_catalog SET "+strings.Join(setClauses, ", ")+" WHERE id = ?",
+				params...).Error; err != nil {
+				return err
+			}
+			if !subjectColumnValue(columns, "active", existing.Active).(bool) {
+				return nil
+			}
+			return ensureSubjectCountRow(tx, subjectColumnValue(columns, "type", existing.Type).(string),
+				subjectColumnValue(columns, "sub_name", existing.SubName).(string))
+		})
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return c.Status(409).JSON(fiber.Map{"status": config.AppMessages.Academic.SubjectExists})
+		}
+		if err != nil {
+			log.Printf("🔴 Error while updating subject %d: %v", id, err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Academic.OperationUnsuccessful})
+		}
+
+		subject, err := findSubject(db, "id = ?", id)
+		if err != nil {
+			log.Printf("🔴 Error while fetching subject %d: %v", id, err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Academic.OperationUnsuccessful})
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"subject": subject,
+			"status":  config.AppMessages.Academic.SubjectSaveSuccess,
+		})
+	}
+}
+
+// DeleteSubjectCatalogEntry handles removing a subject from the catalog. Its access counts in
+// subnamedb or labsdb are kept; set active=false instead to only stop counting it.
+func DeleteSubjectCatalogEntry(db *gorm.DB) fiber.Handler {
+	log.Println("🟣 DELETE: DeleteSubjectCatalogEntry handler called")
+	return func(c *fiber.Ctx) error {
+		if c.Query("adminKey") != config.GetAppConfig().ADMIN_AUTH_KEY {
+			return c.Status(401).JSON(fiber.Map{"error": config.AppMessages.Academic.UnauthorizedAccess})
+		}
+
+		id, err := c.ParamsInt("id")
+		if err != nil || id < 1 {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.Academic.BadRequest})
+		}
+
+		result := db.Exec("DELETE FROM subject_catalog WHERE id = ?", id)
+		if result.Error != nil {
+			log.Printf("🔴 Error while deleting subject %d: %v", id, result.Error)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Academic.OperationUnsuccessful})
+		}
+		if result.RowsAffected == 0 {
+			return c.Status(404).JSON(fiber.Map{"status": config.AppMessages.Academic.SubjectNotFound})
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"id":     id,
+			"status": config.AppMessages.Academic.SubjectDeleteSuccess,
+		})
+	}
+}
+
+// GetSubjectCatalogReport handles reporting catalog problems: routes whose subject has no row in
+// subnamedb or labsdb (their increments answer 404), rows in those tables that no route counts,
+// and pending subjects waiting for review
+func GetSubjectCatalogReport(db *gorm.DB) fiber.Handler {
+	log.Println("🟢 GET: GetSubjectCatalogReport handler called")
+	return func(c *fiber.Ctx) error {
+		if c.Query("adminKey") != config.GetAppConfig().ADMIN_AUTH_KEY {
+			return c.Status(401).JSON(fiber.Map{"error": config.AppMessages.Academic.UnauthorizedAccess})
+		}
+
+		var subjects []Subject
+		if err := db.Raw("SELECT * FROM subject_catalog ORDER BY type, code").Scan(&subjects).Error; err != nil {
+			log.Printf("🔴 Error while fetching subject catalog: %v", err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Academic.OperationUnsuccessful})
+		}
+
+		type missingRow struct {
+			Route   string  `json:"route"`
+			Subject Subject `json:"subject"`
+		}
+		missing := []missingRow{}
+		uncatalogued := map[string][]string{}
+		pending := []Subject{}
+
+		for _, subjectType := range []string{config.SubjectTypeNote, config.SubjectTypeLab} {
+			countTable := subjectCountTables[subjectType]
+			var names []string
+			if err := db.Raw("SELECT " + countTable.column + " FROM " + countTable.table).Scan(&names).Error; err != nil {
+				log.Printf("🔴 Error while fetching %s: %v", countTable.table, err)
+				return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.Academic.OperationUnsuccessful})
+			}
+
+			// MySQL matches the names case insensitively, so the report does too
+			counted := map[string]bool{}
+			for _, name := range names {
+				counted[strings.ToLower(name)] = true
+			}
+			routed := map[string]bool{}
+			for _, subject := range subjects {
+				if subject.Type != subjectType {
+					continue
+				}
+				routed[strings.ToLower(subject.SubName)] = true
+				if subject.Pending {
+					continue
+				}
+				if !counted[strings.ToLower(subject.SubName)] {
+					missing = append(missing, missingRow{Route: "/" + subjectType + "s/" + subject.Code, Subject: subject})
+				}
+			}
+
+			uncatalogued[subjectType] = []string{}
+			for _, name := range names {
+				if !routed[strings.ToLower(name)] {
+					uncatalogued[subjectType] = append(uncatalogued[subjectType], name)
+				}
+			}
+		}
+
+		for _, subject := range subjects {
+			if subject.Pending {
+				pending = append(pending, subject)
+			}
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"missing_count_rows": missing,
+			"uncatalogued":       uncatalogued,
+			"pending":            pending,
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestValidateSubject(t *testing.T) {
+	text := func(value string) *string { return &value }
+	number := func(value int) *int { return &value }
+	flag := func(value bool) *bool { return &value }
+
+	tests := []struct {
+		name    string
+		input   subjectInput
+		columns []subjectColumn
+		errors  map[string]string
+	}{
+		{
+			name:    "nothing given",
+			columns: []subjectColumn{},
+			errors:  map[string]string{},
+		},
+		{
+			name: "values are normalised",
+			input: subjectInput{
+				Code: text(" Phy1 "), Type: text("NOTE"), SubName: text(" phy1 "), DisplayName: text(" Physics I "),
+				Dept: text("textile eng"), Semester: number(2),
+			},
+			columns: []subjectColumn{
+				{"code", "phy1"}, {"type", config.SubjectTypeNote}, {"sub_name", "phy1"},
+				{"display_name", "Physics I"}, {"dept", "TE"}, {"semester", 2},
+			},
+			errors: map[string]string{},
+		},
+		{
+			name:    "empty dept and zero semester clear the value",
+			input:   subjectInput{Dept: text(" "), Semester: number(0)},
+			columns: []subjectColumn{{"dept", nil}, {"semester", nil}},
+			errors:  map[string]string{},
+		},
+		{
+			name:    "activating accepts a pending subject",
+			input:   subjectInput{Active: flag(true)},
+			columns: []subjectColumn{{"active", true}, {"pending", false}},
+			errors:  map[string]string{},
+		},
+		{
+			name:    "deactivating leaves pending alone",
+			input:   subjectInput{Active: flag(false)},
+			columns: []subjectColumn{{"active", false}},
+			errors:  map[string]string{},
+		},
+		{
+			name: "invalid fields",
+			input: subjectInput{
+				Code: text("phy 1"), Type: text("course"), SubName: text(""),
+				DisplayName: text(strings.Repeat("x", maxSubjectDisplayNameLen+1)), Dept: text("astronomy"), Semester: number(9),
+			},
+			columns: []subjectColumn{
+				{"code", "phy 1"}, {"type", "course"}, {"sub_name", ""},
+				{"display_name", strings.Repeat("x", maxSubjectDisplayNameLen+1)}, {"dept", ""}, {"semester", 9},
+			},
+			errors: map[string]string{
+				"code":         config.AppMessages.Validation.InvalidFormat,
+				"type":         config.AppMessages.Academic.InvalidSubjectType,
+				"sub_name":     config.AppMessages.Validation.Required,
+				"display_name": config.AppMessages.Validation.TooLong,
+				"dept":         config.AppMessages.Validation.InvalidDept,
+				"semester":     config.AppMessages.Validation.SemesterOutOfRange,
+			},
+		},
+		{
+			name:    "sub_name too long",
+			input:   subjectInput{SubName: text(strings.Repeat("x", maxSubjectNameLen+1))},
+			columns: []subjectColumn{{"sub_name", strings.Repeat("x", maxSubjectNameLen+1)}},
+			errors:  map[string]string{"sub_name": config.AppMessages.Validation.TooLong},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, errors := validateSubject(tt.input)
+			if !reflect.DeepEqual(columns, tt.columns) {
+				t.Errorf("columns = %v, want %v", columns, tt.columns)
+			}
+			if !reflect.DeepEqual(errors, tt.errors) {
+				t.Errorf("errors = %v, want %v", errors, tt.errors)
+			}
+		})
+	}
+}
+
+// subjectRows serves the catalog entry subject for every subject_catalog lookup
+func subjectRows(subject []driver.Value) func(string) ([]string, [][]driver.Value) {
+	return func(query string) ([]string, [][]driver.Value) {
+		if !strings.Contains(query, "FROM subject_catalog") {
+			return nil, nil
+		}
+		return []string{"id", "code", "type", "sub_name", "display_name", "dept", "semester", "active", "pending", "created_at", "updated_at"},
+			[][]driver.Value{subject}
+	}
+}
+
+func TestCreateSubjectCatalogEntryAddsCountRow(t *testing.T) {
+	useTestAdminKey(t)
+
+	db, rec := newRecordingDB(t)
+	rec.respond = subjectRows([]driver.Value{int64(1), "phylab", "lab", "physics", "PHYLAB", nil, nil, true, false, time.Now(), time.Now()})
+	app := newTestApp()
+	app.Post("/catalog/subjects", CreateSubjectCatalogEntry(db))
+
+	assertUnauthorized(t, app, rec, fiber.MethodPost, "/catalog/subjects", `{"code":"phylab","type":"lab"}`)
+
+	status, body := doRequest(t, app, fiber.MethodPost, "/catalog/subjects?adminKey="+testAdminKey,
+		`{"code":"phylab","type":"lab","sub_name":"physics"}`)
+	if status != fiber.StatusCreated {
+		t.Fatalf("status = %d (body %s)", status, body)
+	}
+
+	rows := rec.Find("INSERT INTO labsdb (lab_name, count)")
+	if len(rows) != 1 || !reflect.DeepEqual(rows[0], []driver.Value{"physics", "physics"}) {
+		t.Errorf("count rows added = %v, want one for physics", rows)
+	}
+}
+
+func TestUpdateSubjectCatalogEntryCountRow(t *testing.T) {
+	useTestAdminKey(t)
+
+	tests := []struct {
+		name    string
+		active  bool
+		body    string
+		counted []driver.Value
+	}{
+		{"accepting a pending subject", false, `{"active":true}`, []driver.Value{"pendingsub", "pendingsub"}},
+		{"renaming an active subject", true, `{"sub_name":"renamed"}`, []driver.Value{"renamed", "renamed"}},
+		{"editing an inactive subject", false, `{"display_name":"Later"}`, nil},
+		{"deactivating a subject", true, `{"active":false}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := newRecordingDB(t)
+			rec.respond = subjectRows([]driver.Value{int64(4), "pendingsub", "note", "pendingsub", "PENDINGSUB", nil, nil,
+				tt.active, !tt.active, time.Now(), time.Now()})
+			app := newTestApp()
+			app.Patch("/catalog/subjects/:id", UpdateSubjectCatalogEntry(db))
+
+			status, body := doRequest(t, app, fiber.MethodPatch, "/catalog/subjects/4?adminKey="+testAdminKey, tt.body)
+			if status != fiber.StatusOK {
+				t.Fatalf("status = %d (body %s)", status, body)
+			}
+
+			rows := rec.Find("INSERT INTO subnamedb (sub_name, count)")
+			if tt.counted == nil {
+				if len(rows) != 0 {
+					t.Errorf("added count rows %v for an inactive subject", rows)
+				}
+				return
+			}
+			if len(rows) != 1 || !reflect.DeepEqual(rows[0], tt.counted) {
+				t.Errorf("count rows added = %v, want %v", rows, tt.counted)
+			}
+		})
+	}
+}
+
+func TestGetSubjectCatalogReport(t *testing.T) {
+	useTestAdminKey(t)
+
+	db, rec := newRecordingDB(t)
+	rec.respond = func(query string) ([]string, [][]driver.Value) {
+		switch {
+		case strings.Contains(query, "FROM subject_catalog"):
+			return []string{"id", "code", "type", "sub_name", "active", "pending"}, [][]driver.Value{
+				{int64(1), "chem1", "note", "chemistry", true, false},
+				{int64(2), "newsub", "note", "newsub", false, true},
+				{int64(3), "phy1", "note", "physics", true, false},
+				{int64(4), "phylab", "lab", "Physics Lab", true, false},
+			}
+		case strings.Contains(query, "FROM subnamedb"):
+			return []string{"sub_name"}, [][]driver.Value{{"Physics"}, {"biology"}}
+		case strings.Contains(query, "FROM labsdb"):
+			return []string{"lab_name"}, [][]driver.Value{{"physics lab"}}
+		}
+		return nil, nil
+	}
+	app := newTestApp()
+	app.Get("/catalog/subjects/report", GetSubjectCatalogReport(db))
+
+	assertUnauthorized(t, app, rec, fiber.MethodGet, "/catalog/subjects/report", "")
+
+	status, body := doRequest(t, app, fiber.MethodGet, "/catalog/subjects/report?adminKey="+testAdminKey, "")
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d (body %s)", status, body)
+	}
+	var response struct {
+		Missing []struct {
+			Route   string  `json:"route"`
+			Subject Subject `json:"subject"`
+		} `json:"missing_count_rows"`
+		Uncatalogued map[string][]string `json:"uncatalogued"`
+		Pending      []Subject           `json:"pending"`
+	}
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatal(err)
+	}
+
+	// Names match case insensitively and pending subjects are not reported as missing
+	if len(response.Missing) != 1 || response.Missing[0].Route != "/notes/chem1" {
+		t.Errorf("missing_count_rows = %+v, want only /notes/chem1", response.Missing)
+	}
+	wantUncatalogued := map[string][]string{"note": {"biology"}, "lab": {}}
+	if !reflect.DeepEqual(response.Uncatalogued, wantUncatalogued) {
+		t.Errorf("uncatalogued = %v, want %v", response.Uncatalogued, wantUncatalogued)
+	}
+	if len(response.Pending) != 1 || response.Pending[0].Code != "newsub" {
+		t.Errorf("pending = %+v, want newsub", response.Pending)
+	}
+}
@@ -1,9 +1,13 @@
 package handler
 
 import (
+	"errors"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/utils"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
@@ -17,7 +21,156 @@ type User struct {
 	ImgUrl string `json:"imgUrl"`
 }
 
-// CreateUser handles creating new users
+const (
+	UserCreated   = "created"
+	UserUpdated   = "updated"
+	UserUnchanged = "unchanged"
+	UserDeleted   = "deleted"
+)
+
+const (
+	defaultUserImgUrl = "not given"
+	maxUniIDLen       = 64
+)
+
+// normalizeUserField validates one user field, keyed by its JSON name, and returns
+// its canonical value or the reason it was rejected
+func normalizeUserField(field, value string) (string, string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", config.AppMessages.Validation.Required
+	}
+
+	switch field {
+	case "email":
+		value = strings.ToLower(value)
+		if !utils.ValidateEmail(value) {
+			return "", config.AppMessages.Validation.InvalidFormat
+		}
+	case "uni_id":
+		if len(value) > maxUniIDLen {
+			return "", config.AppMessages.Validation.TooLong
+		}
+	case "batch":
+		batch, ok := utils.NormalizeBatch(value)
+		if !ok {
+			return "", config.AppMessages.Validation.BatchOutOfRange
+		}
+		value = batch
+	case "dept":
+		dept, ok := utils.NormalizeDept(value)
+		if !ok {
+			return "", config.AppMessages.Validation.InvalidDept
+		}
+		value = dept
+	case "role":
+		role, ok := utils.NormalizeRole(value)
+		if !ok {
+			return "", config.AppMessages.Validation.InvalidRole
+		}
+		value = role
+	}
+	return value, ""
+}
+
+// validateUser normalises every field of a user in place and returns the errors per invalid field
+func validateUser(user *User) map[string]string {
+	fieldErrors := map[string]string{}
+	fields := []struct {
+		name  string
+		value *string
+	}{
+		{"email", &user.Email},
+		{"uni_id", &user.UniID},
+		{"batch", &user.Batch},
+		{"dept", &user.Dept},
+		{"role", &user.Role},
+	}
+
+	for _, field := range fields {
+		value, fieldError := normalizeUserField(field.name, *field.value)
+		if fieldError != "" {
+			fieldErrors[field.name] = fieldError
+			continue
+		}
+		*field.value = value
+	}
+
+	user.ImgUrl = strings.TrimSpace(user.ImgUrl)
+	return fieldErrors
+}
+
+// upsertUser stores a user keyed by email: a new email is inserted and an existing one has its
+// profile fields updated. A soft deleted user is left as it is, only RestoreUser brings it back.
+// It must run inside a transaction so the lookup and the write use the same connection.
+func upsertUser(tx *gorm.DB, user User) (int64, string, error) {
+	var existing []struct {
+		ID        int64
+		UniID     string
+		Batch     string
+		Dept      string
+		Role      string
+		ImgUrl    string
+		DeletedAt *time.Time
+	}
+	if err := tx.Raw(`
+		SELECT id, uni_id, batch, dept, role, img_url, deleted_at
+		FROM app_users
+		WHERE email = ?
+		ORDER BY id ASC
+		LIMIT 1
+		FOR UPDATE`, user.Email).Scan(&existing).Error; err != nil {
+		return 0, "", err
+	}
+
+	if len(existing) == 0 {
+		imgUrl := user.ImgUrl
+		if imgUrl == "" {
+			imgUrl = defaultUserImgUrl
+		}
+
+		query := `INSERT INTO app_users (email, uni_id, batch, dept, role, img_url) VALUES (?, ?, ?, ?, ?, ?)`
+		if err := tx.Exec(query, user.Email, user.UniID, user.Batch, user.Dept, user.Role, imgUrl).Error; err != nil {
+			return 0, "", err
+		}
+
+		var id int64
+		if err := tx.Raw("SELECT LAST_INSERT_ID()").Scan(&id).Error; err != nil {
+			return 0, "", err
+		}
+		return id, UserCreated, nil
+	}
+
+	current := existing[0]
+	if current.DeletedAt != nil {
+		return current.ID, UserDeleted, nil
+	}
+
+	// Keep the stored avatar when the client does not send one
+	imgUrl := user.ImgUrl
+	if imgUrl == "" {
+		imgUrl = current.ImgUrl
+	}
+
+	if current.UniID == user.UniID && current.Batch == user.Batch &&
+		current.Dept == user.Dept && current.Role == user.Role && current.ImgUrl == imgUrl {
+		return current.ID, UserUnchanged, nil
+	}
+
+	if err := tx.Exec(`
+		UPDATE app_users
+		SET uni_id = ?, batch = ?, dept = ?, role = ?, img_url = ?
+		WHERE id = ?`,
+		user.UniID, user.Batch, user.Dept, user.Role, imgUrl, current.ID,
+	).Error; err != nil {
+		return 0, "", err
+	}
+	return current.ID, UserUpdated, nil
+}
+
+// CreateUser handles registering users. Registration is idempotent per email: re-registering
+// returns the existing user with its profile updated, and "result" tells the client which happened.
+// Registering the email of a soft deleted user answers 409 with result "deleted".
 func CreateUser(db *gorm.DB) fiber.Handler {
 	log.Println("🔵 POST: CreateUser handler called")
 	return func(c *fiber.Ctx) error {
@@ -32,26 +185,53 @@ func CreateUser(db *gorm.DB) fiber.Handler {
 			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.User.BadRequest})
 		}
 
-		// Validate required fields
-		if user.Email == "" || user.UniID == "" || user.Batch == "" || user.Dept == "" || user.Role == "" {
-			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.User.BadRequest})
+		// Validate and normalise every field, reporting all invalid ones at once
+		if fieldErrors := validateUser(user); len(fieldErrors) > 0 {
+			return c.Status(400).JSON(fiber.Map{
+				"status": config.AppMessages.User.ValidationFailed,
+				"errors": fieldErrors,
+			})
 		}
 
-		// Set default image URL if not provided
-		if user.ImgUrl == "" {
-			user.ImgUrl = "not given"
+		var id int64
+		var result string
+		upsert := func(tx *gorm.DB) error {
+			var err error
+			id, result, err = upsertUser(tx, *user)
+			return err
 		}
 
-		// Raw SQL query
-		query := `INSERT INTO app_users (email, uni_id, batch, dept, role, img_url) VALUES (?, ?, ?, ?, ?, ?)`
-		if err := db.Exec(query, user.Email, user.UniID, user.Batch, user.Dept, user.Role, user.ImgUrl).Error; err != nil {
+		err := db.Transaction(upsert)
+		// Two first registrations for the same email can race; the loser sees the winner's row on retry
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			err = db.Transaction(upsert)
+		}
+		if err != nil {
 			log.Printf("🔴 Error while inserting new user info: %v", err)
 			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.OperationUnsuccessful})
 		}
+		if result == UserDeleted {
+			return c.Status(409).JSON(fiber.Map{
+				"result": result,
+				"status": config.AppMessages.User.AccountDeleted,
+			})
+		}
+
+		stored, err := findUser(db, false, "id = ?", id)
+		if err != nil {
+			log.Printf("🔴 Error while fetching user %d: %v", id, err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.FetchError})
+		}
+
+		status := config.AppMessages.User.InsertSuccess
+		if result != UserCreated {
+			status = config.AppMessages.User.AlreadyRegistered
+		}
 
 		return c.Status(200).JSON(fiber.Map{
-			"user":   user,
-			"status": config.AppMessages.User.InsertSuccess,
+			"user":   stored,
+			"result": result,
+			"status": status,
 		})
 	}
 }
@@ -64,38 +244,16 @@ func GetAllUsers(db *gorm.DB) fiber.Handler {
 		page := c.QueryInt("page", 1)
 		limit := c.QueryInt("limit", 500)
 		search := c.Query("search", "")
-		offset := (page - 1) * limit
-
-		// Build the WHERE clause for search
-		whereClause := "1=1"
-		params := []interface{}{}
 
-		if search != "" {
-			whereClause = "(email LIKE ? OR dept LIKE ?)"
-			searchPattern := "%" + search + "%"
-			params = append(params, searchPattern, searchPattern)
+		if page < 1 {
+			page = 1
 		}
-
-		// Get total count with search filter
-		var total int64
-		countQuery := "SELECT COUNT(*) FROM app_users WHERE " + whereClause
-		if err := db.Raw(countQuery, params...).Scan(&total).Error; err != nil {
-			log.Printf("🔴 Error while counting users: %v", err)
-			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.CountError})
+		if limit < 1 {
+			limit = 500
 		}
 
-		// Get paginated and filtered users
-		var users []map[string]interface{}
-		query := `
-			SELECT * FROM app_users 
-			WHERE ` + whereClause + `
-			ORDER BY id DESC 
-			LIMIT ? OFFSET ?`
-
-		// Add pagination parameters
-		params = append(params, limit, offset)
-
-		if err := db.Raw(query, params...).Scan(&users).Error; err != nil {
+		users, total, err := UserQuery{Search: search, Page: page, Limit: limit}.Run(db)
+		if err != nil {
 			log.Printf("🔴 Error while fetching all users: %v", err)
 			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.FetchError})
 		}
@@ -116,7 +274,7 @@ func GetUserCount(db *gorm.DB) fiber.Handler {
 	log.Println("🟢 GET: GetUserCount handler called")
 	return func(c *fiber.Ctx) error {
 		var count int64
-		if err := db.Raw("SELECT COUNT(*) FROM app_users").Scan(&count).Error; err != nil {
+		if err := db.Raw("SELECT COUNT(*) FROM app_users WHERE deleted_at IS NULL").Scan(&count).Error; err != nil {
 			log.Printf("🔴 Error while fetching app user count: %v", err)
 			return c.Status(500).JSON(fiber.Map{"status": "🔴 Error while fetching app user count"})
 		}
@@ -142,7 +300,14 @@ func GetUsersByEmail(db *gorm.DB) fiber.Handler {
 		}
 
 		var users []map[string]interface{}
-		if err := db.Raw("SELECT * FROM app_users WHERE email LIKE ?", email.Email).Scan(&users).Error; err != nil {
+		var err error
+		if email.Email == "" {
+			// UserQuery skips empty filters, but this route has always matched LIKE ''
+			err = db.Raw("SELECT * FROM app_users WHERE email LIKE '' AND deleted_at IS NULL").Scan(&users).Error
+		} else {
+			users, _, err = UserQuery{EmailLike: email.Email}.Run(db)
+		}
+		if err != nil {
 			log.Printf("🔴 Error while fetching users by email: %v", err)
 			return c.Status(500).JSON(fiber.Map{"status": "🔴 Error while fetching app users"})
 		}
@@ -169,8 +334,24 @@ func GetUsersByDeptAndBatch(db *gorm.DB) fiber.Handler {
 		}
 
 		var users []map[string]interface{}
-		if err := db.Raw("SELECT * FROM app_users WHERE batch = ? AND dept LIKE ? ORDER BY batch DESC",
-			filter.Batch, filter.Dept).Scan(&users).Error; err != nil {
+		var err error
+		if filter.Dept == "" || filter.Batch == "" {
+			// UserQuery skips empty filters, but this route has always matched them literally
+			err = db.Raw("SELECT * FROM app_users WHERE batch = ? AND dept LIKE ? AND deleted_at IS NULL ORDER BY batch DESC",
+				filter.Batch, filter.Dept).Scan(&users).Error
+		} else {
+			// Known spellings of a department or batch match the normalised stored values,
+			// anything else is still matched the way it was sent
+			q := UserQuery{Batch: filter.Batch, DeptLike: filter.Dept, Sort: "batch", Order: "desc"}
+			if dept, ok := utils.NormalizeDept(filter.Dept); ok {
+				q.Dept, q.DeptLike = dept, ""
+			}
+			if batch, ok := utils.NormalizeBatch(filter.Batch); ok {
+				q.Batch = batch
+			}
+			users, _, err = q.Run(db)
+		}
+		if err != nil {
 			log.Printf("🔴 Error while fetching filtered users: %v", err)
 			return c.Status(500).JSON(fiber.Map{"status": "🔴 Error while fetching app users"})
 		}
@@ -179,6 +360,267 @@ func GetUsersByDeptAndBatch(db *gorm.DB) fiber.Handler {
 	}
 }
 
+// findUser returns the first app user matching the condition, or nil when there is none
+func findUser(db *gorm.DB, includeDeleted bool, condition string, args ...interface{}) (map[string]interface{}, error) {
+	query := "SELECT * FROM app_users WHERE " + condition
+	if !includeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
+
+	var users []map[string]interface{}
+	if err := db.Raw(query+" LIMIT 1", args...).Scan(&users).Error; err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+	return users[0], nil
+}
+
+// GetUserCatalog handles fetching the accepted departments, roles and batch range
+func GetUserCatalog(c *fiber.Ctx) error {
+	log.Println("🟢 GET: GetUserCatalog handler called")
+	return c.Status(200).JSON(fiber.Map{
+		"departments": config.Departments,
+		"roles":       config.UserRoles,
+		"batch": fiber.Map{
+			"min": config.UserBatchMin,
+			"max": config.UserBatchMax,
+		},
+	})
+}
+
+// GetUserByID handles fetching a single user by id
+func GetUserByID(db *gorm.DB) fiber.Handler {
+	log.Println("🟢 GET: GetUserByID handler called")
+	return func(c *fiber.Ctx) error {
+		if c.Query("adminKey") != config.GetAppConfig().ADMIN_AUTH_KEY {
+			return c.Status(401).JSON(fiber.Map{"error": config.AppMessages.User.UnauthorizedAccess})
+		}
+
+		id, err := c.ParamsInt("id")
+		if err != nil || id < 1 {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.User.BadRequest})
+		}
+
+		user, err := findUser(db, c.QueryBool("includeDeleted"), "id = ?", id)
+		if err != nil {
+			log.Printf("🔴 Error while fetching user %d: %v", id, err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.FetchError})
+		}
+		if user == nil {
+			return c.Status(404).JSON(fiber.Map{"status": config.AppMessages.User.NotFound})
+		}
+
+		return c.Status(200).JSON(fiber.Map{"user": user})
+	}
+}
+
+// GetUserByEmail handles fetching a single user by exact email
+func GetUserByEmail(db *gorm.DB) fiber.Handler {
+	log.Println("🟢 GET: GetUserByEmail handler called")
+	return func(c *fiber.Ctx) error {
+		if c.Query("adminKey") != config.GetAppConfig().ADMIN_AUTH_KEY {
+			return c.Status(401).JSON(fiber.Map{"error": config.AppMessages.User.UnauthorizedAccess})
+		}
+
+		email := strings.TrimSpace(c.Query("email"))
+		if email == "" {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.User.BadRequest})
+		}
+
+		user, err := findUser(db, c.QueryBool("includeDeleted"), "email = ?", email)
+		if err != nil {
+			log.Printf("🔴 Error while fetching user by email: %v", err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.FetchError})
+		}
+		if user == nil {
+			return c.Status(404).JSON(fiber.Map{"status": config.AppMessages.User.NotFound})
+		}
+
+		return c.Status(200).JSON(fiber.Map{"user": user})
+	}
+}
+
+// UpdateUser handles partial updates of a user's fields. Only fields present in the body are changed.
+func UpdateUser(db *gorm.DB) fiber.Handler {
+	log.Println("🟠 PATCH: UpdateUser handler called")
+	return func(c *fiber.Ctx) error {
+		if c.Query("adminKey") != config.GetAppConfig().ADMIN_AUTH_KEY {
+			return c.Status(401).JSON(fiber.Map{"error": config.AppMessages.User.UnauthorizedAccess})
+		}
+
+		id, err := c.ParamsInt("id")
+		if err != nil || id < 1 {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.User.BadRequest})
+		}
+
+		patch := struct {
+			Email  *string `json:"email"`
+			UniID  *string `json:"uni_id"`
+			Batch  *string `json:"batch"`
+			Dept   *string `json:"dept"`
+			Role   *string `json:"role"`
+			ImgUrl *string `json:"imgUrl"`
+		}{}
+		if err := c.BodyParser(&patch); err != nil {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.User.BadRequest})
+		}
+
+		fields := []struct {
+			name   string
+			column string
+			value  *string
+		}{
+			{"email", "email", patch.Email},
+			{"uni_id", "uni_id", patch.UniID},
+			{"batch", "batch", patch.Batch},
+			{"dept", "dept", patch.Dept},
+			{"role", "role", patch.Role},
+			{"imgUrl", "img_url", patch.ImgUrl},
+		}
+
+		setClauses := []string{}
+		params := []interface{}{}
+		fieldErrors := map[string]string{}
+		for _, field := range fields {
+			if field.value == nil {
+				continue
+			}
+			value, fieldError := normalizeUserField(field.name, *field.value)
+			if fieldError != "" {
+				fieldErrors[field.name] = fieldError
+				continue
+			}
+			*field.value = value
+			setClauses = append(setClauses, field.column+" = ?")
+			params = append(params, value)
+		}
+
+		if len(fieldErrors) > 0 {
+			return c.Status(400).JSON(fiber.Map{
+				"status": config.AppMessages.User.ValidationFailed,
+				"errors": fieldErrors,
+			})
+		}
+		if len(setClauses) == 0 {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.User.BadRequest})
+		}
+
+		existing, err := findUser(db, false, "id = ?", id)
+		if err != nil {
+			log.Printf("🔴 Error while fetching user %d: %v", id, err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.FetchError})
+		}
+		if existing == nil {
+			return c.Status(404).JSON(fiber.Map{"status": config.AppMessages.User.NotFound})
+		}
+
+		if patch.Email != nil {
+			// Soft deleted users keep their email, so they still count as taken
+			other, err := findUser(db, true, "email = ? AND id <> ?", *patch.Email, id)
+			if err != nil {
+				log.Printf("🔴 Error while checking user email: %v", err)
+				return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.FetchError})
+			}
+			if other != nil {
+				return c.Status(409).JSON(fiber.Map{"status": config.AppMessages.User.EmailTaken})
+			}
+		}
+
+		params = append(params, id)
+		query := "UPDATE app_users SET " + strings.Join(setClauses, ", ") + " WHERE id = ? AND deleted_at IS NULL"
+		if err := db.Exec(query, params...).Error; err != nil {
+			log.Printf("🔴 Error while updating user %d: %v", id, err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.OperationUnsuccessful})
+		}
+
+		user, err := findUser(db, false, "id = ?", id)
+		if err != nil {
+			log.Printf("🔴 Error while fetching user %d: %v", id, err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.FetchError})
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"user":   user,
+			"status": config.AppMessages.User.UpdateSuccess,
+		})
+	}
+}
+
+// DeleteUser handles deleting a user. Users are soft deleted unless hard=true is passed.
+func DeleteUser(db *gorm.DB) fiber.Handler {
+	log.Println("🟣 DELETE: DeleteUser handler called")
+	return func(c *fiber.Ctx) error {
+		if c.Query("adminKey") != config.GetAppConfig().ADMIN_AUTH_KEY {
+			return c.Status(401).JSON(fiber.Map{"error": config.AppMessages.User.UnauthorizedAccess})
+		}
+
+		id, err := c.ParamsInt("id")
+		if err != nil || id < 1 {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.User.BadRequest})
+		}
+
+		hard := c.QueryBool("hard")
+
+		var result *gorm.DB
+		if hard {
+			result = db.Exec("DELETE FROM app_users WHERE id = ?", id)
+		} else {
+			result = db.Exec("UPDATE app_users SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL", time.Now(), id)
+		}
+
+		if result.Error != nil {
+			log.Printf("🔴 Error while deleting user %d: %v", id, result.Error)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.OperationUnsuccessful})
+		}
+		if result.RowsAffected == 0 {
+			return c.Status(404).JSON(fiber.Map{"status": config.AppMessages.User.NotFound})
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"id":          id,
+			"hard_delete": hard,
+			"status":      config.AppMessages.User.DeleteSuccess,
+		})
+	}
+}
+
+// RestoreUser handles bringing back a soft deleted user
+func RestoreUser(db *gorm.DB) fiber.Handler {
+	log.Println("🔵 POST: RestoreUser handler called")
+	return func(c *fiber.Ctx) error {
+		if c.Query("adminKey") != config.GetAppConfig().ADMIN_AUTH_KEY {
+			return c.Status(401).JSON(fiber.Map{"error": config.AppMessages.User.UnauthorizedAccess})
+		}
+
+		id, err := c.ParamsInt("id")
+		if err != nil || id < 1 {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.User.BadRequest})
+		}
+
+		result := db.Exec("UPDATE app_users SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL", id)
+		if result.Error != nil {
+			log.Printf("🔴 Error while restoring user %d: %v", id, result.Error)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.OperationUnsuccessful})
+		}
+		if result.RowsAffected == 0 {
+			return c.Status(404).JSON(fiber.Map{"status": config.AppMessages.User.NotFound})
+		}
+
+		user, err := findUser(db, false, "id = ?", id)
+		if err != nil {
+			log.Printf("🔴 Error while fetching user %d: %v", id, err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.FetchError})
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"user":   user,
+			"status": config.AppMessages.User.RestoreSuccess,
+		})
+	}
+}
+
 // IncrementUserCount handles incrementing user count
 func IncrementUserCount(db *gorm.DB) fiber.Handler {
 	log.Println("🔵 POST: IncrementUserCount handler called")
@@ -0,0 +1,391 @@
+package handler
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func TestGetUsersByDeptAndBatchNormalisesInput(t *testing.T) {
+	useTestAdminKey(t)
+
+	tests := []struct {
+		body string
+		want []driver.Value
+	}{
+		{`{"dept":"Textile","batch":"045"}`, []driver.Value{"TE", "45"}},
+		{`{"dept":"te","batch":"45"}`, []driver.Value{"TE", "45"}},
+		{`{"dept":"%tex%","batch":"45"}`, []driver.Value{"TE", "45"}},
+		{`{"dept":"Computer%","batch":"45"}`, []driver.Value{"Computer%", "45"}},
+	}
+	for _, tt := range tests {
+		db, rec := newRecordingDB(t)
+		app := newTestApp()
+		app.Post("/users/app/batch_dept", GetUsersByDeptAndBatch(db))
+
+		status, body := doRequest(t, app, fiber.MethodPost, "/users/app/batch_dept?adminKey="+testAdminKey, tt.body)
+		if status != fiber.StatusOK {
+			t.Fatalf("%s: status = %d (body %s)", tt.body, status, body)
+		}
+		queries := rec.Find("SELECT * FROM app_users")
+		if len(queries) != 1 || !reflect.DeepEqual(queries[0], tt.want) {
+			t.Errorf("%s: query args = %v, want %v", tt.body, queries, tt.want)
+		}
+	}
+}
+
+func TestLegacyUserSearchesAcceptEmptyFields(t *testing.T) {
+	useTestAdminKey(t)
+
+	tests := []struct {
+		target  string
+		handler func(*gorm.DB) fiber.Handler
+		body    string
+		query   string
+	}{
+		{"/users/app/email", GetUsersByEmail, `{}`, "email LIKE ''"},
+		{"/users/app/email", GetUsersByEmail, `{"email":""}`, "email LIKE ''"},
+		{"/users/app/batch_dept", GetUsersByDeptAndBatch, `{"batch":"45"}`, "batch = ? AND dept LIKE ?"},
+		{"/users/app/batch_dept", GetUsersByDeptAndBatch, `{}`, "batch = ? AND dept LIKE ?"},
+	}
+	for _, tt := range tests {
+		db, rec := newRecordingDB(t)
+		app := newTestApp()
+		app.Post(tt.target, tt.handler(db))
+
+		status, body := doRequest(t, app, fiber.MethodPost, tt.target+"?adminKey="+testAdminKey, tt.body)
+		if status != fiber.StatusOK || body != `{"searched_users":null}` {
+			t.Errorf("%s %s: got %d %s, want 200 with no users", tt.target, tt.body, status, body)
+		}
+		if len(rec.Find(tt.query)) != 1 {
+			t.Errorf("%s %s: statements = %v, want the legacy %q match", tt.target, tt.body, rec.Statements(), tt.query)
+		}
+	}
+}
+
+func TestGetUsersByEmailUsesPattern(t *testing.T) {
+	useTestAdminKey(t)
+	db, rec := newRecordingDB(t)
+	app := newTestApp()
+	app.Post("/users/app/email", GetUsersByEmail(db))
+
+	if status, body := doRequest(t, app, fiber.MethodPost, "/users/app/email?adminKey="+testAdminKey, `{"email":"%@butex.edu.bd"}`); status != fiber.StatusOK {
+		t.Fatalf("status = %d (body %s)", status, body)
+	}
+	queries := rec.Find("SELECT * FROM app_users")
+	if len(queries) != 1 || !reflect.DeepEqual(queries[0], []driver.Value{"%@butex.edu.bd"}) {
+		t.Errorf("query args = %v, want the pattern as sent", queries)
+	}
+}
+
+// storedUserRows serves stored as the app_users row an upsert finds for its email
+func storedUserRows(stored []driver.Value) func(string) ([]string, [][]driver.Value) {
+	return func(query string) ([]string, [][]driver.Value) {
+		switch {
+		case strings.Contains(query, "SELECT LAST_INSERT_ID()"):
+			return []string{"id"}, [][]driver.Value{{int64(9)}}
+		case strings.Contains(query, "SELECT id, uni_id, batch, dept, role, img_url, deleted_at"):
+			if stored == nil {
+				return nil, nil
+			}
+			return []string{"id", "uni_id", "batch", "dept", "role", "img_url", "deleted_at"}, [][]driver.Value{stored}
+		case strings.Contains(query, "FROM app_users"):
+			return []string{"id", "email"}, [][]driver.Value{{int64(9), "someone@example.com"}}
+		}
+		return nil, nil
+	}
+}
+
+func TestUpsertUser(t *testing.T) {
+	user := User{Email: "someone@example.com", UniID: "2019-1-60-001", Batch: "45", Dept: "TE", Role: "student"}
+	deletedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		stored []driver.Value
+		user   User
+		result string
+		write  string
+		args   []driver.Value
+	}{
+		{
+			name:   "new email is inserted",
+			user:   user,
+			result: UserCreated,
+			write:  "INSERT INTO app_users",
+			args:   []driver.Value{user.Email, user.UniID, "45", "TE", "student", defaultUserImgUrl},
+		},
+		{
+			name:   "same profile is left alone",
+			stored: []driver.Value{int64(3), user.UniID, "45", "TE", "student", "a.png", nil},
+			user:   user,
+			result: UserUnchanged,
+		},
+		{
+			name:   "changed profile is updated and keeps its avatar",
+			stored: []driver.Value{int64(3), user.UniID, "44", "TE", "student", "a.png", nil},
+			user:   user,
+			result: UserUpdated,
+			write:  "UPDATE app_users",
+			args:   []driver.Value{user.UniID, "45", "TE", "student", "a.png", int64(3)},
+		},
+		{
+			name:   "soft deleted user stays deleted",
+			stored: []driver.Value{int64(3), user.UniID, "44", "TE", "student", "a.png", deletedAt},
+			user:   user,
+			result: UserDeleted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := newRecordingDB(t)
+			rec.respond = storedUserRows(tt.stored)
+
+			var result string
+			err := db.Transaction(func(tx *gorm.DB) error {
+				var err error
+				_, result, err = upsertUser(tx, tt.user)
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != tt.result {
+				t.Errorf("result = %s, want %s", result, tt.result)
+			}
+
+			var writes []string
+			for _, statement := range rec.Statements() {
+				if strings.Contains(statement, "INSERT") || strings.Contains(statement, "UPDATE app_users") {
+					writes = append(writes, statement)
+				}
+			}
+			if tt.write == "" {
+				if len(writes) != 0 {
+					t.Errorf("wrote %v, want no write", writes)
+				}
+				return
+			}
+			if found := rec.Find(tt.write); len(found) != 1 || !reflect.DeepEqual(found[0], tt.args) {
+				t.Errorf("%s args = %v, want %v", tt.write, found, tt.args)
+			}
+		})
+	}
+}
+
+func TestCreateUserKeepsDeletedUsersDeleted(t *testing.T) {
+	useTestAdminKey(t)
+
+	db, rec := newRecordingDB(t)
+	rec.respond = storedUserRows([]driver.Value{int64(3), "2019-1-60-001", "45", "TE", "student", "a.png", time.Now()})
+	app := newTestApp()
+	app.Post("/users/app", CreateUser(db))
+
+	status, body := doRequest(t, app, fiber.MethodPost, "/users/app?adminKey="+testAdminKey,
+		`{"email":"someone@example.com","uni_id":"2019-1-60-001","batch":"45","dept":"TE","role":"student"}`)
+	if status != fiber.StatusConflict || !strings.Contains(body, `"result":"deleted"`) {
+		t.Errorf("status = %d, body %s, want 409 with result deleted", status, body)
+	}
+	if writes := rec.Find("UPDATE app_users"); len(writes) != 0 {
+		t.Errorf("re-registration restored the user: %v", writes)
+	}
+}
+
+func TestRestoreUser(t *testing.T) {
+	useTestAdminKey(t)
+
+	for _, tt := range []struct {
+		name     string
+		affected int64
+		status   int
+	}{
+		{"deleted user is restored", 1, fiber.StatusOK},
+		{"user that isn't deleted", 0, fiber.StatusNotFound},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := newRecordingDB(t)
+			rec.respond = storedUserRows(nil)
+			rec.affected = func(string) int64 { return tt.affected }
+			app := newTestApp()
+			app.Post("/users/app/:id/restore", RestoreUser(db))
+
+			assertUnauthorized(t, app, rec, fiber.MethodPost, "/users/app/3/restore", "")
+
+			status, body := doRequest(t, app, fiber.MethodPost, "/users/app/3/restore?adminKey="+testAdminKey, "")
+			if status != tt.status {
+				t.Fatalf("status = %d, want %d (body %s)", status, tt.status, body)
+			}
+			restores := rec.Find("SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL")
+			if len(restores) != 1 || !reflect.DeepEqual(restores[0], []driver.Value{int64(3)}) {
+				t.Errorf("restores = %v, want user 3", restores)
+			}
+		})
+	}
+}
+
+// adminUserRows serves user 9 to lookups by id, and taken as the owner of any other email
+func adminUserRows(taken bool) func(string) ([]string, [][]driver.Value) {
+	return func(query string) ([]string, [][]driver.Value) {
+		columns := []string{"id", "email", "uni_id", "batch", "dept", "role", "img_url", "deleted_at"}
+		switch {
+		case strings.Contains(query, "email = ? AND id <> ?"):
+			if !taken {
+				return nil, nil
+			}
+			return columns, [][]driver.Value{{int64(4), "taken@example.com", "2019-1-60-004", "45", "TE", "student", nil, nil}}
+		case strings.Contains(query, "WHERE id = ?"):
+			return columns, [][]driver.Value{{int64(9), "someone@example.com", "2019-1-60-001", "45", "TE", "student", nil, nil}}
+		}
+		return nil, nil
+	}
+}
+
+func TestGetUserByID(t *testing.T) {
+	useTestAdminKey(t)
+
+	tests := []struct {
+		target  string
+		respond func(string) ([]string, [][]driver.Value)
+		status  int
+		deleted bool
+	}{
+		{"/users/app/9", adminUserRows(false), fiber.StatusOK, false},
+		{"/users/app/9?includeDeleted=true", adminUserRows(false), fiber.StatusOK, true},
+		{"/users/app/8", nil, fiber.StatusNotFound, false},
+		{"/users/app/abc", nil, fiber.StatusBadRequest, false},
+	}
+	for _, tt := range tests {
+		db, rec := newRecordingDB(t)
+		rec.respond = tt.respond
+		app := newTestApp()
+		app.Get("/users/app/:id", GetUserByID(db))
+
+		separator := "?"
+		if strings.Contains(tt.target, "?") {
+			separator = "&"
+		}
+		status, body := doRequest(t, app, fiber.MethodGet, tt.target+separator+"adminKey="+testAdminKey, "")
+		if status != tt.status {
+			t.Errorf("%s: status = %d, want %d (body %s)", tt.target, status, tt.status, body)
+			continue
+		}
+		if status == fiber.StatusOK && !strings.Contains(body, `"email":"someone@example.com"`) {
+			t.Errorf("%s: body = %s", tt.target, body)
+		}
+		if status != fiber.StatusBadRequest {
+			if skipsDeleted := len(rec.Find("deleted_at IS NULL")) == 1; skipsDeleted == tt.deleted {
+				t.Errorf("%s: statements = %v, include deleted %v", tt.target, rec.Statements(), tt.deleted)
+			}
+		}
+	}
+}
+
+func TestGetUserByEmail(t *testing.T) {
+	useTestAdminKey(t)
+
+	db, rec := newRecordingDB(t)
+	app := newTestApp()
+	app.Get("/users/app/lookup", GetUserByEmail(db))
+
+	if status, _ := doRequest(t, app, fiber.MethodGet, "/users/app/lookup?adminKey="+testAdminKey, ""); status != fiber.StatusBadRequest {
+		t.Errorf("missing email: status = %d, want 400", status)
+	}
+	if status, _ := doRequest(t, app, fiber.MethodGet, "/users/app/lookup?adminKey="+testAdminKey+"&email=+someone@example.com+", ""); status != fiber.StatusNotFound {
+		t.Errorf("unknown email: status = %d, want 404", status)
+	}
+	if lookups := rec.Find("WHERE email = ?"); len(lookups) != 1 || lookups[0][0] != "someone@example.com" {
+		t.Errorf("lookups = %v, want the trimmed email", lookups)
+	}
+}
+
+func TestUpdateUser(t *testing.T) {
+	useTestAdminKey(t)
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		taken  bool
+		status int
+		update []driver.Value
+	}{
+		{"only given fields change", "/users/app/9", `{"batch":"46","imgUrl":" b.png "}`, false, fiber.StatusOK, []driver.Value{"46", "b.png", int64(9)}},
+		{"new email", "/users/app/9", `{"email":"new@example.com"}`, false, fiber.StatusOK, []driver.Value{"new@example.com", int64(9)}},
+		{"email of another user", "/users/app/9", `{"email":"taken@example.com"}`, true, fiber.StatusConflict, nil},
+		{"invalid email", "/users/app/9", `{"email":"not-an-email"}`, false, fiber.StatusBadRequest, nil},
+		{"empty field", "/users/app/9", `{"uni_id":" "}`, false, fiber.StatusBadRequest, nil},
+		{"nothing to change", "/users/app/9", `{}`, false, fiber.StatusBadRequest, nil},
+		{"invalid id", "/users/app/0", `{"batch":"46"}`, false, fiber.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := newRecordingDB(t)
+			rec.respond = adminUserRows(tt.taken)
+			app := newTestApp()
+			app.Patch("/users/app/:id", UpdateUser(db))
+
+			status, body := doRequest(t, app, fiber.MethodPatch, tt.target+"?adminKey="+testAdminKey, tt.body)
+			if status != tt.status {
+				t.Fatalf("status = %d, want %d (body %s)", status, tt.status, body)
+			}
+			updates := rec.Find("UPDATE app_users SET")
+			if tt.update == nil {
+				if len(updates) != 0 {
+					t.Errorf("updated %v", updates)
+				}
+				return
+			}
+			if len(updates) != 1 || !reflect.DeepEqual(updates[0], tt.update) {
+				t.Errorf("updates = %v, want %v", updates, tt.update)
+			}
+		})
+	}
+
+	db, rec := newRecordingDB(t)
+	app := newTestApp()
+	app.Patch("/users/app/:id", UpdateUser(db))
+	if status, _ := doRequest(t, app, fiber.MethodPatch, "/users/app/8?adminKey="+testAdminKey, `{"batch":"46"}`); status != fiber.StatusNotFound {
+		t.Errorf("missing user: status = %d, want 404", status)
+	}
+	if updates := rec.Find("UPDATE app_users SET"); len(updates) != 0 {
+		t.Errorf("missing user updated %v", updates)
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	useTestAdminKey(t)
+
+	tests := []struct {
+		query     string
+		affected  int64
+		status    int
+		statement string
+	}{
+		{"", 1, fiber.StatusOK, "UPDATE app_users SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL"},
+		{"&hard=true", 1, fiber.StatusOK, "DELETE FROM app_users WHERE id = ?"},
+		{"", 0, fiber.StatusNotFound, "UPDATE app_users SET deleted_at = ?"},
+		{"&hard=true", 0, fiber.StatusNotFound, "DELETE FROM app_users WHERE id = ?"},
+	}
+	for _, tt := range tests {
+		db, rec := newRecordingDB(t)
+		rec.affected = func(string) int64 { return tt.affected }
+		app := newTestApp()
+		app.Delete("/users/app/:id", DeleteUser(db))
+
+		assertUnauthorized(t, app, rec, fiber.MethodDelete, "/users/app/9", "")
+
+		status, body := doRequest(t, app, fiber.MethodDelete, "/users/app/9?adminKey="+testAdminKey+tt.query, "")
+		if status != tt.status {
+			t.Errorf("%q affecting %d: status = %d, want %d (body %s)", tt.query, tt.affected, status, tt.status, body)
+		}
+		if statements := rec.Statements(); len(statements) != 1 || len(rec.Find(tt.statement)) != 1 {
+			t.Errorf("%q: statements = %v, want %q", tt.query, statements, tt.statement)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const maxImportRows = 5000
+
+// Columns written by the export; the import accepts the same header
+var userCSVColumns = []string{"id", "email", "uni_id", "batch", "dept", "role", "img_url", "created_at"}
+
+// errDryRun rolls back a dry-run import after every row has been applied
+var errDryRun = errors.New("dry run")
+
+type userImportRow struct {
+	Row    int               `json:"row"`
+	Email  string            `json:"email"`
+	Result string            `json:"result,omitempty"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// Spreadsheets evaluate a cell starting with one of these as a formula
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// csvCell quotes a value that a spreadsheet would run as a formula with a leading
+// apostrophe, so exported emails and image URLs are always shown as text.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// readUserCSV parses an uploaded CSV of users. The header row names the columns, in any
+// order; id and created_at are ignored so an export can be edited and imported again.
+func readUserCSV(reader io.Reader) ([]User, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 2 {
+		return nil, errors.New("the CSV needs a header row and at least one user")
+	}
+	if len(records)-1 > maxImportRows {
+		return nil, fmt.Errorf("the CSV has more than %d users", maxImportRows)
+	}
+
+	columns := map[string]int{}
+	for index, name := range records[0] {
+		// Spreadsheet exports often start with a UTF-8 byte order mark
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF")))
+		if name == "imgurl" {
+			name = "img_url"
+		}
+		columns[name] = index
+	}
+	for _, required := range []string{"email", "uni_id", "batch", "dept", "role"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("the CSV header is missing the %q column", required)
+		}
+	}
+
+	cell := func(record []string, column string) string {
+		if index, ok := columns[column]; ok && index < len(record) {
+			// Undo csvCell so an exported file imports unchanged
+			value := record[index]
+			if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes, rune(value[1])) {
+				return value[1:]
+			}
+			return value
+		}
+		return ""
+	}
+
+	users := make([]User, 0, len(records)-1)
+	for _, record := range records[1:] {
+		users = append(users, User{
+			Email:  cell(record, "email"),
+			UniID:  cell(record, "uni_id"),
+			Batch:  cell(record, "batch"),
+			Dept:   cell(record, "dept"),
+			Role:   cell(record, "role"),
+			ImgUrl: cell(record, "img_url"),
+		})
+	}
+	return users, nil
+}
+
+// ImportUsers handles bulk user import from a CSV upload (multipart field "file").
+// Rows are validated like CreateUser and applied with the same upsert semantics in one
+// transaction; if any row is invalid nothing is written. Pass dryRun=true to only report.
+func ImportUsers(db *gorm.DB) fiber.Handler {
+	log.Println("🔵 POST: ImportUsers handler called")
+	return func(c *fiber.Ctx) error {
+		if c.Query("adminKey") != config.GetAppConfig().ADMIN_AUTH_KEY {
+			return c.Status(401).JSON(fiber.Map{"error": config.AppMessages.User.UnauthorizedAccess})
+		}
+
+		dryRun := c.QueryBool("dryRun")
+
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.User.BadRequest})
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			log.Printf("🔴 Error while opening uploaded CSV: %v", err)
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.User.BadRequest})
+		}
+		defer file.Close()
+
+		users, err := readUserCSV(file)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"status": config.AppMessages.User.InvalidCSV,
+				"error":  err.Error(),
+			})
+		}
+
+		// Validate every row first; the header is row 1 so users start at row 2
+		rows := make([]userImportRow, len(users))
+		seen := map[string]int{}
+		invalid := 0
+		for index := range users {
+			rows[index] = userImportRow{Row: index + 2, Email: users[index].Email}
+
+			fieldErrors := validateUser(&users[index])
+			if len(fieldErrors) == 0 {
+				rows[index].Email = users[index].Email
+				if firstRow, ok := seen[users[index].Email]; ok {
+					fieldErrors["email"] = fmt.Sprintf("%s (row %d)", config.AppMessages.User.DuplicateInFile, firstRow)
+				} else {
+					seen[users[index].Email] = rows[index].Row
+				}
+			}
+
+			if len(fieldErrors) > 0 {
+				rows[index].Errors = fieldErrors
+				invalid++
+			}
+		}
+
+		if invalid > 0 {
+			return c.Status(400).JSON(fiber.Map{
+				"dry_run":      dryRun,
+				"invalid_rows": invalid,
+				"rows":         rows,
+				"status":       config.AppMessages.User.ValidationFailed,
+			})
+		}
+
+		summary := map[string]int{UserCreated: 0, UserUpdated: 0, UserUnchanged: 0, UserDeleted: 0}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			for index, user := range users {
+				_, result, err := upsertUser(tx, user)
+				if err != nil {
+					return fmt.Errorf("row %d: %w", rows[index].Row, err)
+				}
+				rows[index].Result = result
+				summary[result]++
+			}
+
+			// Everything was applied, so a dry run reports exactly what a real import would do
+			if dryRun {
+				return errDryRun
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errDryRun) {
+			log.Printf("🔴 Error while importing users: %v", err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.OperationUnsuccessful})
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"dry_run": dryRun,
+			"summary": summary,
+			"rows":    rows,
+			"status":  config.AppMessages.User.ImportSuccess,
+		})
+	}
+}
+
+// ExportUsers handles exporting users as CSV. It takes the GetAllUsers search filter and
+// every other QueryUsers filter.
+func ExportUsers(db *gorm.DB) fiber.Handler {
+	log.Println("🟢 GET: ExportUsers handler called")
+	return func(c *fiber.Ctx) error {
+		if c.Query("adminKey") != config.GetAppConfig().ADMIN_AUTH_KEY {
+			return c.Status(401).JSON(fiber.Map{"error": config.AppMessages.User.UnauthorizedAccess})
+		}
+
+		// Accept the same filters as the user query API, but export every match
+		q, paramErrors := parseUserQuery(c)
+		if len(paramErrors) > 0 {
+			return c.Status(400).JSON(fiber.Map{
+				"status": config.AppMessages.User.BadRequest,
+				"errors": paramErrors,
+			})
+		}
+		q.Fields = userCSVColumns
+		q.Limit = 0
+		if c.Query("order") == "" {
+			q.Order = "asc"
+		}
+
+		users, _, err := q.Run(db)
+		if err != nil {
+			log.Printf("🔴 Error while exporting users: %v", err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.FetchError})
+		}
+
+		var buffer strings.Builder
+		writer := csv.NewWriter(&buffer)
+		if err := writer.Write(userCSVColumns); err != nil {
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.OperationUnsuccessful})
+		}
+		for _, user := range users {
+			record := make([]string, len(userCSVColumns))
+			for index, column := range userCSVColumns {
+				switch value := user[column].(type) {
+				case nil:
+				case time.Time:
+					record[index] = value.Format(time.DateTime)
+				default:
+					record[index] = csvCell(fmt.Sprint(value))
+				}
+			}
+			if err := writer.Write(record); err != nil {
+				return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.OperationUnsuccessful})
+			}
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			log.Printf("🔴 Error while writing users CSV: %v", err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.OperationUnsuccessful})
+		}
+
+		filename := fmt.Sprintf("app_users-%s.csv", time.Now().Format("20060102"))
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+		return c.Status(200).SendString(buffer.String())
+	}
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestCSVCell(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"someone@example.com", "someone@example.com"},
+		{"=HYPERLINK(\"http://evil\")", "'=HYPERLINK(\"http://evil\")"},
+		{"+1", "'+1"},
+		{"-1", "'-1"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tcell", "'\tcell"},
+		{"\rcell", "'\rcell"},
+		{"a=b", "a=b"},
+	}
+	for _, tt := range tests {
+		if got := csvCell(tt.value); got != tt.want {
+			t.Errorf("csvCell(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestReadUserCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    []User
+		wantErr bool
+	}{
+		{
+			name: "export header",
+			csv:  "id,email,uni_id,batch,dept,role,img_url,created_at\n1,a@example.com,1,45,TE,student,,2026-01-01 00:00:00\n",
+			want: []User{{Email: "a@example.com", UniID: "1", Batch: "45", Dept: "TE", Role: "student"}},
+		},
+		{
+			name: "reordered header with byte order mark",
+			csv:  "\uFEFFRole,Dept,Batch,UNI_ID,Email,imgUrl\nstudent,TE,45,1,a@example.com,x.png\n",
+			want: []User{{Email: "a@example.com", UniID: "1", Batch: "45", Dept: "TE", Role: "student", ImgUrl: "x.png"}},
+		},
+		{
+			name: "quoted formulas from an export",
+			csv:  "email,uni_id,batch,dept,role,img_url\n'=a@example.com,1,45,TE,student,'-x.png\n",
+			want: []User{{Email: "=a@example.com", UniID: "1", Batch: "45", Dept: "TE", Role: "student", ImgUrl: "-x.png"}},
+		},
+		{
+			name: "apostrophes in plain values",
+			csv:  "email,uni_id,batch,dept,role\n'a@example.com,1,45,TE,student\n",
+			want: []User{{Email: "'a@example.com", UniID: "1", Batch: "45", Dept: "TE", Role: "student"}},
+		},
+		{name: "header only", csv: "email,uni_id,batch,dept,role\n", wantErr: true},
+		{name: "missing column", csv: "email,uni_id,batch,dept\na@example.com,1,45,TE\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, err := readUserCSV(strings.NewReader(tt.csv))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(users, tt.want) {
+				t.Errorf("users = %+v, want %+v", users, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportUsers(t *testing.T) {
+	useTestAdminKey(t)
+	db, rec := newRecordingDB(t)
+	createdAt := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	rec.respond = func(query string) ([]string, [][]driver.Value) {
+		if strings.Contains(query, "SELECT COUNT(*)") {
+			return []string{"count"}, [][]driver.Value{{int64(2)}}
+		}
+		return userCSVColumns, [][]driver.Value{
+			{int64(1), "a@example.com", "2019-1-60-001", "45", "TE", "student", nil, createdAt},
+			{int64(2), "b@example.com", "2019-1-60-002", "45", "TE", "student", "=HYPERLINK(\"http://evil\")", createdAt},
+		}
+	}
+	app := newTestApp()
+	app.Get("/users/app/export", ExportUsers(db))
+
+	status, body := doRequest(t, app, fiber.MethodGet, "/users/app/export?adminKey="+testAdminKey+"&dept=TE", "")
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d (body %s)", status, body)
+	}
+	want := "id,email,uni_id,batch,dept,role,img_url,created_at\n" +
+		"1,a@example.com,2019-1-60-001,45,TE,student,,2026-03-01 09:30:00\n" +
+		"2,b@example.com,2019-1-60-002,45,TE,student,\"'=HYPERLINK(\"\"http://evil\"\")\",2026-03-01 09:30:00\n"
+	if body != want {
+		t.Errorf("body =\n%s\nwant\n%s", body, want)
+	}
+	if queries := rec.Find("ORDER BY id ASC"); len(queries) != 1 || !reflect.DeepEqual(queries[0], []driver.Value{"TE"}) {
+		t.Errorf("export queries = %v, want every TE user in id order", queries)
+	}
+
+	// The export imports back unchanged
+	users, err := readUserCSV(strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if users[1].ImgUrl != "=HYPERLINK(\"http://evil\")" {
+		t.Errorf("re-imported img_url = %q", users[1].ImgUrl)
+	}
+}
+
+func TestImportUsers(t *testing.T) {
+	useTestAdminKey(t)
+	valid := "email,uni_id,batch,dept,role\nA@Example.com,2019-1-60-001,045,Textile,student\n"
+
+	tests := []struct {
+		name    string
+		query   string
+		csv     string
+		status  int
+		inserts int
+		summary map[string]int
+	}{
+		{
+			name:    "creates new users",
+			csv:     valid,
+			status:  fiber.StatusOK,
+			inserts: 1,
+			summary: map[string]int{UserCreated: 1, UserUpdated: 0, UserUnchanged: 0, UserDeleted: 0},
+		},
+		{
+			name:    "dry run reports the same summary",
+			query:   "&dryRun=true",
+			csv:     valid,
+			status:  fiber.StatusOK,
+			inserts: 1,
+			summary: map[string]int{UserCreated: 1, UserUpdated: 0, UserUnchanged: 0, UserDeleted: 0},
+		},
+		{
+			name:   "duplicate email in the file",
+			csv:    valid + "a@example.com,2019-1-60-002,45,TE,student\n",
+			status: fiber.StatusBadRequest,
+		},
+		{
+			name:   "invalid row",
+			csv:    valid + "not-an-email,2019-1-60-002,45,TE,student\n",
+			status: fiber.StatusBadRequest,
+		},
+		{
+			name:   "missing column",
+			csv:    "email,uni_id\na@example.com,1\n",
+			status: fiber.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := newRecordingDB(t)
+			rec.respond = storedUserRows(nil)
+			app := newTestApp()
+			app.Post("/users/app/import", ImportUsers(db))
+
+			status, body := doUpload(t, app, "/users/app/import?adminKey="+testAdminKey+tt.query, "file", "users.csv", []byte(tt.csv))
+			if status != tt.status {
+				t.Fatalf("status = %d, want %d (body %s)", status, tt.status, body)
+			}
+			inserts := rec.Find("INSERT INTO app_users")
+			if len(inserts) != tt.inserts {
+				t.Fatalf("inserts = %v, want %d", inserts, tt.inserts)
+			}
+			if tt.inserts > 0 && !reflect.DeepEqual(inserts[0][:5], []driver.Value{"a@example.com", "2019-1-60-001", "45", "TE", "student"}) {
+				t.Errorf("inserted %v, want the normalised row", inserts[0])
+			}
+			if tt.summary != nil {
+				var response struct {
+					Summary map[string]int `json:"summary"`
+				}
+				if err := json.Unmarshal([]byte(body), &response); err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(response.Summary, tt.summary) {
+					t.Errorf("summary = %v, want %v", response.Summary, tt.summary)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	appdb "github.com/TriptoAfsin/notebot-anlaytics-go/db"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type storedUser struct {
+	ID        int64      `json:"id"`
+	Email     string     `json:"email"`
+	UniID     string     `json:"uni_id"`
+	Batch     string     `json:"batch"`
+	Dept      string     `json:"dept"`
+	Role      string     `json:"role"`
+	ImgUrl    string     `json:"img_url"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+type userMergePlan struct {
+	Email      string     `json:"email"`
+	KeptID     int64      `json:"kept_id"`
+	RemovedIDs []int64    `json:"removed_ids"`
+	Merged     storedUser `json:"merged"`
+}
+
+// planUserMerge folds duplicate rows (sorted by id) into the earliest one. Each profile field
+// takes the most recent non-empty value, and the user stays active if any duplicate was active.
+func planUserMerge(rows []storedUser) userMergePlan {
+	merged := rows[0]
+	merged.Email = strings.ToLower(strings.TrimSpace(merged.Email))
+	plan := userMergePlan{Email: merged.Email, KeptID: merged.ID, RemovedIDs: []int64{}}
+
+	pick := func(current *string, candidate string) {
+		if candidate != "" && candidate != defaultUserImgUrl {
+			*current = candidate
+		}
+	}
+
+	for _, row := range rows[1:] {
+		plan.RemovedIDs = append(plan.RemovedIDs, row.ID)
+		pick(&merged.UniID, row.UniID)
+		pick(&merged.Batch, row.Batch)
+		pick(&merged.Dept, row.Dept)
+		pick(&merged.Role, row.Role)
+		pick(&merged.ImgUrl, row.ImgUrl)
+		if row.DeletedAt == nil {
+			merged.DeletedAt = nil
+		}
+	}
+
+	plan.Merged = merged
+	return plan
+}
+
+// MergeDuplicateUsers merges app users that share an email into the row with the earliest id,
+// then adds the unique email index. Pass dryRun=true to only report the planned merges.
+func MergeDuplicateUsers(db *gorm.DB) fiber.Handler {
+	log.Println("🔵 POST: MergeDuplicateUsers handler called")
+	return func(c *fiber.Ctx) error {
+		if c.Query("adminKey") != config.GetAppConfig().ADMIN_AUTH_KEY {
+			return c.Status(401).JSON(fiber.Map{"error": config.AppMessages.User.UnauthorizedAccess})
+		}
+
+		dryRun := c.QueryBool("dryRun")
+		plans := []userMergePlan{}
+		var rowsRemoved int64
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var emails []string
+			if err := tx.Raw("SELECT email FROM app_users GROUP BY email HAVING COUNT(*) > 1").
+				Scan(&emails).Error; err != nil {
+				return err
+			}
+
+			for _, email := range emails {
+				var rows []storedUser
+				if err := tx.Raw(`
+					SELECT id, email, uni_id, batch, dept, role, img_url, deleted_at
+					FROM app_users
+					WHERE email = ?
+					ORDER BY id ASC
+					FOR UPDATE`, email).Scan(&rows).Error; err != nil {
+					return err
+				}
+				if len(rows) < 2 {
+					continue
+				}
+
+				plan := planUserMerge(rows)
+				plans = append(plans, plan)
+				if dryRun {
+					continue
+				}
+
+				// Remove the newer duplicates, then fold their values into the kept row
+				deleted := tx.Exec("DELETE FROM app_users WHERE id IN ?", plan.RemovedIDs)
+				if deleted.Error != nil {
+					return deleted.Error
+				}
+				rowsRemoved += deleted.RowsAffected
+
+				merged := plan.Merged
+				if err := tx.Exec(`
+					UPDATE app_users
+					SET email = ?, uni_id = ?, batch = ?, dept = ?, role = ?, img_url = ?, deleted_at = ?
+					WHERE id = ?`,
+					merged.Email, merged.UniID, merged.Batch, merged.Dept, merged.Role, merged.ImgUrl, merged.DeletedAt, merged.ID,
+				).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("🔴 Error while merging duplicate users: %v", err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.OperationUnsuccessful})
+		}
+
+		uniqueIndex := false
+		if !dryRun {
+			if uniqueIndex, err = appdb.EnsureUserEmailUniqueIndex(db); err != nil {
+				log.Printf("🔴 Error while adding unique email index: %v", err)
+				return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.OperationUnsuccessful})
+			}
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"dry_run":      dryRun,
+			"merges":       plans,
+			"rows_removed": rowsRemoved,
+			"unique_index": uniqueIndex,
+			"status":       config.AppMessages.User.DedupeSuccess,
+		})
+	}
+}
+
+// NormalizeUsers rewrites the batch, dept and role of existing users to their catalog values.
+// The same normalisation runs as a migration on startup; this endpoint reports what it changed
+// and which values are left to fix by hand. Pass dryRun=true to only report the changes.
+func NormalizeUsers(db *gorm.DB) fiber.Handler {
+	log.Println("🔵 POST: NormalizeUsers handler called")
+	return func(c *fiber.Ctx) error {
+		if c.Query("adminKey") != config.GetAppConfig().ADMIN_AUTH_KEY {
+			return c.Status(401).JSON(fiber.Map{"error": config.AppMessages.User.UnauthorizedAccess})
+		}
+
+		dryRun := c.QueryBool("dryRun")
+		result, err := appdb.NormalizeUserValues(db, dryRun)
+		if err != nil {
+			log.Printf("🔴 Error while normalising users: %v", err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.OperationUnsuccessful})
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"dry_run":      dryRun,
+			"rows_updated": result.RowsUpdated,
+			"changes":      result.Changes,
+			"unmappable":   result.Unmappable,
+			"status":       config.AppMessages.User.NormalizeSuccess,
+		})
+	}
+}
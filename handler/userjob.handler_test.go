@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	appdb "github.com/TriptoAfsin/notebot-anlaytics-go/db"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestPlanUserMerge(t *testing.T) {
+	deletedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		rows []storedUser
+		want userMergePlan
+	}{
+		{
+			name: "newer values win",
+			rows: []storedUser{
+				{ID: 1, Email: " Someone@Example.com", UniID: "2019-1-60-001", Batch: "44", Dept: "TE", Role: "student", ImgUrl: "a.png"},
+				{ID: 5, Email: "someone@example.com", Batch: "45", ImgUrl: "b.png"},
+			},
+			want: userMergePlan{
+				Email: "someone@example.com", KeptID: 1, RemovedIDs: []int64{5},
+				Merged: storedUser{ID: 1, Email: "someone@example.com", UniID: "2019-1-60-001", Batch: "45", Dept: "TE", Role: "student", ImgUrl: "b.png"},
+			},
+		},
+		{
+			name: "the default image never replaces a real one",
+			rows: []storedUser{
+				{ID: 1, Email: "someone@example.com", ImgUrl: "a.png"},
+				{ID: 2, Email: "someone@example.com", ImgUrl: defaultUserImgUrl},
+				{ID: 3, Email: "someone@example.com", ImgUrl: ""},
+			},
+			want: userMergePlan{
+				Email: "someone@example.com", KeptID: 1, RemovedIDs: []int64{2, 3},
+				Merged: storedUser{ID: 1, Email: "someone@example.com", ImgUrl: "a.png"},
+			},
+		},
+		{
+			name: "active if any duplicate is active",
+			rows: []storedUser{
+				{ID: 1, Email: "someone@example.com", DeletedAt: &deletedAt},
+				{ID: 2, Email: "someone@example.com"},
+			},
+			want: userMergePlan{
+				Email: "someone@example.com", KeptID: 1, RemovedIDs: []int64{2},
+				Merged: storedUser{ID: 1, Email: "someone@example.com"},
+			},
+		},
+		{
+			name: "deleted if every duplicate is deleted",
+			rows: []storedUser{
+				{ID: 1, Email: "someone@example.com", DeletedAt: &deletedAt},
+				{ID: 2, Email: "someone@example.com", DeletedAt: &deletedAt},
+			},
+			want: userMergePlan{
+				Email: "someone@example.com", KeptID: 1, RemovedIDs: []int64{2},
+				Merged: storedUser{ID: 1, Email: "someone@example.com", DeletedAt: &deletedAt},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := planUserMerge(tt.rows); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("planUserMerge() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeDuplicateUsers(t *testing.T) {
+	useTestAdminKey(t)
+
+	for _, dryRun := range []bool{true, false} {
+		db, rec := newRecordingDB(t)
+		rec.respond = func(query string) ([]string, [][]driver.Value) {
+			switch {
+			case strings.Contains(query, "SELECT email FROM app_users GROUP BY email HAVING COUNT(*) > 1") && !strings.Contains(query, "COUNT(*) FROM ("):
+				return []string{"email"}, [][]driver.Value{{"someone@example.com"}}
+			case strings.Contains(query, "WHERE email = ?"):
+				return []string{"id", "email", "uni_id", "batch", "dept", "role", "img_url", "deleted_at"}, [][]driver.Value{
+					{int64(1), "someone@example.com", "2019-1-60-001", "44", "TE", "student", "a.png", nil},
+					{int64(5), "someone@example.com", "2019-1-60-001", "45", "TE", "student", "not given", nil},
+				}
+			}
+			return nil, nil
+		}
+		rec.affected = func(string) int64 { return 1 }
+		app := newTestApp()
+		app.Post("/users/app/dedupe", MergeDuplicateUsers(db))
+
+		target := "/users/app/dedupe?adminKey=" + testAdminKey
+		if dryRun {
+			target += "&dryRun=true"
+		}
+		status, body := doRequest(t, app, fiber.MethodPost, target, "")
+		if status != fiber.StatusOK {
+			t.Fatalf("dryRun=%v: status = %d (body %s)", dryRun, status, body)
+		}
+
+		var response struct {
+			Merges      []userMergePlan `json:"merges"`
+			RowsRemoved int64           `json:"rows_removed"`
+			UniqueIndex bool            `json:"unique_index"`
+		}
+		if err := json.Unmarshal([]byte(body), &response); err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Merges) != 1 || response.Merges[0].Merged.Batch != "45" || response.Merges[0].Merged.ImgUrl != "a.png" {
+			t.Errorf("dryRun=%v: merges = %+v", dryRun, response.Merges)
+		}
+
+		deletes := rec.Find("DELETE FROM app_users WHERE id IN")
+		updates := rec.Find("UPDATE app_users")
+		indexes := rec.Find("CREATE UNIQUE INDEX uq_app_users_email")
+		if dryRun {
+			if len(deletes)+len(updates)+len(indexes) != 0 || response.RowsRemoved != 0 || response.UniqueIndex {
+				t.Errorf("dry run changed users: %v", rec.Statements())
+			}
+			continue
+		}
+		if len(deletes) != 1 || !reflect.DeepEqual(deletes[0], []driver.Value{int64(5)}) || response.RowsRemoved != 1 {
+			t.Errorf("deletes = %v, rows removed %d, want user 5", deletes, response.RowsRemoved)
+		}
+		want := []driver.Value{"someone@example.com", "2019-1-60-001", "45", "TE", "student", "a.png", nil, int64(1)}
+		if len(updates) != 1 || !reflect.DeepEqual(updates[0], want) {
+			t.Errorf("updates = %v, want %v", updates, want)
+		}
+		if len(indexes) != 1 || !response.UniqueIndex {
+			t.Errorf("unique index created %d times, reported %v", len(indexes), response.UniqueIndex)
+		}
+	}
+}
+
+func TestNormalizeUsers(t *testing.T) {
+	useTestAdminKey(t)
+
+	for _, dryRun := range []bool{true, false} {
+		db, rec := newRecordingDB(t)
+		rec.respond = func(query string) ([]string, [][]driver.Value) {
+			if !strings.Contains(query, "FROM app_users") {
+				return nil, nil
+			}
+			return []string{"id", "batch", "dept", "role"}, [][]driver.Value{
+				{int64(1), "45", "TE", "student"},
+				{int64(2), "045", "Textile", "Student"},
+				{int64(3), "46", "Dept. of Textile Engg", "std"},
+				{int64(4), "46", "Computer Science", "student"},
+			}
+		}
+		app := newTestApp()
+		app.Post("/users/app/normalize", NormalizeUsers(db))
+
+		target := "/users/app/normalize?adminKey=" + testAdminKey
+		if dryRun {
+			target += "&dryRun=true"
+		}
+		status, body := doRequest(t, app, fiber.MethodPost, target, "")
+		if status != fiber.StatusOK {
+			t.Fatalf("dryRun=%v: status = %d (body %s)", dryRun, status, body)
+		}
+
+		var result appdb.UserNormalization
+		if err := json.Unmarshal([]byte(body), &result); err != nil {
+			t.Fatal(err)
+		}
+		if result.RowsUpdated != 2 {
+			t.Errorf("dryRun=%v: rows_updated = %d, want 2", dryRun, result.RowsUpdated)
+		}
+		if len(result.Unmappable["dept"]) != 1 || result.Unmappable["dept"][0].Value != "Computer Science" {
+			t.Errorf("dryRun=%v: unmappable depts = %+v", dryRun, result.Unmappable["dept"])
+		}
+
+		updates := rec.Find("UPDATE app_users")
+		if dryRun {
+			if len(updates) != 0 {
+				t.Errorf("dry run updated %d users", len(updates))
+			}
+			continue
+		}
+		want := [][]driver.Value{{"45", "TE", "student", int64(2)}, {"46", "TE", "student", int64(3)}}
+		if len(updates) != len(want) {
+			t.Fatalf("updated %d users, want %d", len(updates), len(want))
+		}
+		for i := range want {
+			for j := range want[i] {
+				if updates[i][j] != want[i][j] {
+					t.Errorf("update %d = %v, want %v", i, updates[i], want[i])
+					break
+				}
+			}
+		}
+	}
+}
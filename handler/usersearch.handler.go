@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/utils"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Columns that can be selected and sorted on, mapped to the SQL used for sorting
+var userSortColumns = map[string]string{
+	"id":         "id",
+	"email":      "email",
+	"uni_id":     "uni_id",
+	"batch":      "CAST(batch AS UNSIGNED)",
+	"dept":       "dept",
+	"role":       "role",
+	"img_url":    "img_url",
+	"created_at": "created_at",
+	"deleted_at": "deleted_at",
+}
+
+// UserQuery holds every supported user filter. Empty fields are not filtered on.
+// EmailLike and DeptLike are raw LIKE patterns, the rest are exact matches or ranges.
+// Dept is a catalog code; stored depts are normalised to codes on startup.
+type UserQuery struct {
+	Search         string
+	EmailLike      string
+	UniID          string
+	DeptLike       string
+	Dept           string
+	Role           string
+	Batch          string
+	BatchMin       int
+	BatchMax       int
+	CreatedFrom    string
+	CreatedTo      string
+	IncludeDeleted bool
+	Sort           string
+	Order          string
+	Fields         []string
+	Page           int
+	Limit          int
+}
+
+// where builds the WHERE clause and its parameters
+func (q UserQuery) where() (string, []interface{}) {
+	clauses := []string{}
+	params := []interface{}{}
+
+	if !q.IncludeDeleted {
+		clauses = append(clauses, "deleted_at IS NULL")
+	}
+	if q.Search != "" {
+		clauses = append(clauses, "(email LIKE ? OR dept LIKE ?)")
+		searchPattern := "%" + q.Search + "%"
+		params = append(params, searchPattern, searchPattern)
+	}
+	if q.EmailLike != "" {
+		clauses = append(clauses, "email LIKE ?")
+		params = append(params, q.EmailLike)
+	}
+	if q.UniID != "" {
+		clauses = append(clauses, "uni_id = ?")
+		params = append(params, q.UniID)
+	}
+	if q.DeptLike != "" {
+		clauses = append(clauses, "dept LIKE ?")
+		params = append(params, q.DeptLike)
+	}
+	if q.Dept != "" {
+		clauses = append(clauses, "dept = ?")
+		params = append(params, q.Dept)
+	}
+	if q.Role != "" {
+		clauses = append(clauses, "role = ?")
+		params = append(params, q.Role)
+	}
+	if q.Batch != "" {
+		clauses = append(clauses, "batch = ?")
+		params = append(params, q.Batch)
+	}
+	if q.BatchMin > 0 {
+		clauses = append(clauses, "CAST(batch AS UNSIGNED) >= ?")
+		params = append(params, q.BatchMin)
+	}
+	if q.BatchMax > 0 {
+		clauses = append(clauses, "CAST(batch AS UNSIGNED) <= ?")
+		params = append(params, q.BatchMax)
+	}
+	if q.CreatedFrom != "" {
+		clauses = append(clauses, "created_at >= ?")
+		params = append(params, q.CreatedFrom)
+	}
+	if q.CreatedTo != "" {
+		clauses = append(clauses, "created_at < DATE_ADD(?, INTERVAL 1 DAY)")
+		params = append(params, q.CreatedTo)
+	}
+
+	if len(clauses) == 0 {
+		return "1=1", params
+	}
+	return strings.Join(clauses, " AND "), params
+}
+
+// Run returns the matching users and their total count. A zero Limit returns every match.
+func (q UserQuery) Run(db *gorm.DB) ([]map[string]interface{}, int64, error) {
+	whereClause, params := q.where()
+
+	var total int64
+	if err := db.Raw("SELECT COUNT(*) FROM app_users WHERE "+whereClause, params...).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	columns := "*"
+	if len(q.Fields) > 0 {
+		columns = strings.Join(q.Fields, ", ")
+	}
+
+	sortColumn, ok := userSortColumns[q.Sort]
+	if !ok {
+		sortColumn = "id"
+	}
+	order := "DESC"
+	if strings.EqualFold(q.Order, "asc") {
+		order = "ASC"
+	}
+
+	query := "SELECT " + columns + " FROM app_users WHERE " + whereClause + " ORDER BY " + sortColumn + " " + order
+	if q.Limit > 0 {
+		page := q.Page
+		if page < 1 {
+			page = 1
+		}
+		query += " LIMIT ? OFFSET ?"
+		params = append(params, q.Limit, (page-1)*q.Limit)
+	}
+
+	var users []map[string]interface{}
+	if err := db.Raw(query, params...).Scan(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// parseUserQuery reads user filters from the query string and reports every invalid parameter
+func parseUserQuery(c *fiber.Ctx) (UserQuery, map[string]string) {
+	paramErrors := map[string]string{}
+	q := UserQuery{
+		Search:         c.Query("search"),
+		UniID:          strings.TrimSpace(c.Query("uni_id")),
+		Role:           strings.TrimSpace(c.Query("role")),
+		Batch:          strings.TrimSpace(c.Query("batch")),
+		BatchMin:       c.QueryInt("batch_min", 0),
+		BatchMax:       c.QueryInt("batch_max", 0),
+		CreatedFrom:    c.Query("created_from"),
+		CreatedTo:      c.Query("created_to"),
+		IncludeDeleted: c.QueryBool("include_deleted"),
+		Sort:           c.Query("sort", "id"),
+		Order:          c.Query("order", "desc"),
+		Page:           c.QueryInt("page", 1),
+		Limit:          c.QueryInt("limit", 500),
+	}
+
+	// Email and unknown depts are substring matches. Known spellings of a dept, role or batch
+	// are mapped to the catalog values stored users are normalised to and matched exactly,
+	// as a substring match on a dept code such as TE would also find TEM
+	if email := strings.TrimSpace(c.Query("email")); email != "" {
+		q.EmailLike = "%" + email + "%"
+	}
+	if dept := strings.TrimSpace(c.Query("dept")); dept != "" {
+		if code, ok := utils.NormalizeDept(dept); ok {
+			q.Dept = code
+		} else {
+			q.DeptLike = "%" + dept + "%"
+		}
+	}
+	if role, ok := utils.NormalizeRole(q.Role); ok {
+		q.Role = role
+	}
+	if batch, ok := utils.NormalizeBatch(q.Batch); ok {
+		q.Batch = batch
+	}
+
+	if q.BatchMin < 0 || q.BatchMax < 0 || (q.BatchMax > 0 && q.BatchMin > q.BatchMax) {
+		paramErrors["batch_min"] = config.AppMessages.Validation.InvalidFormat
+	}
+	for name, value := range map[string]string{"created_from": q.CreatedFrom, "created_to": q.CreatedTo} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, value); err != nil {
+			paramErrors[name] = config.AppMessages.Validation.InvalidFormat
+		}
+	}
+	if _, ok := userSortColumns[q.Sort]; !ok {
+		paramErrors["sort"] = config.AppMessages.Validation.InvalidFormat
+	}
+	if order := strings.ToLower(q.Order); order != "asc" && order != "desc" {
+		paramErrors["order"] = config.AppMessages.Validation.InvalidFormat
+	}
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Limit < 1 || q.Limit > 500 {
+		q.Limit = 500
+	}
+
+	if fields := c.Query("fields"); fields != "" {
+		for _, field := range strings.Split(fields, ",") {
+			field = strings.TrimSpace(field)
+			if _, ok := userSortColumns[field]; !ok {
+				paramErrors["fields"] = config.AppMessages.Validation.InvalidFormat
+				continue
+			}
+			q.Fields = append(q.Fields, field)
+		}
+	}
+
+	return q, paramErrors
+}
+
+// QueryUsers handles searching users with combinable filters, sorting, pagination and field selection.
+// Filters: search, email, uni_id, dept, role, batch, batch_min, batch_max, created_from, created_to
+// (YYYY-MM-DD), include_deleted. Sorting: sort=<column>&order=asc|desc. Fields: fields=id,email,...
+func QueryUsers(db *gorm.DB) fiber.Handler {
+	log.Println("🟢 GET: QueryUsers handler called")
+	return func(c *fiber.Ctx) error {
+		if c.Query("adminKey") != config.GetAppConfig().ADMIN_AUTH_KEY {
+			return c.Status(401).JSON(fiber.Map{"error": config.AppMessages.User.UnauthorizedAccess})
+		}
+
+		q, paramErrors := parseUserQuery(c)
+		if len(paramErrors) > 0 {
+			return c.Status(400).JSON(fiber.Map{
+				"status": config.AppMessages.User.BadRequest,
+				"errors": paramErrors,
+			})
+		}
+
+		users, total, err := q.Run(db)
+		if err != nil {
+			log.Printf("🔴 Error while querying users: %v", err)
+			return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.FetchError})
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"users":        users,
+			"total":        total,
+			"current_page": q.Page,
+			"limit":        q.Limit,
+			"total_pages":  (total + int64(q.Limit) - 1) / int64(q.Limit),
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseTestUserQuery runs parseUserQuery against a request with the given query string
+func parseTestUserQuery(t *testing.T, rawQuery string) (UserQuery, map[string]string) {
+	t.Helper()
+	var q UserQuery
+	var paramErrors map[string]string
+	app := newTestApp()
+	app.Get("/", func(c *fiber.Ctx) error {
+		q, paramErrors = parseUserQuery(c)
+		return nil
+	})
+	doRequest(t, app, fiber.MethodGet, "/?"+rawQuery, "")
+	return q, paramErrors
+}
+
+func TestParseUserQuery(t *testing.T) {
+	defaults := UserQuery{Sort: "id", Order: "desc", Page: 1, Limit: 500}
+	with := func(modify func(*UserQuery)) UserQuery {
+		q := defaults
+		modify(&q)
+		return q
+	}
+
+	tests := []struct {
+		rawQuery string
+		want     UserQuery
+	}{
+		{"", defaults},
+		{"email=butex", with(func(q *UserQuery) { q.EmailLike = "%butex%" })},
+		{"email=%20butex%20", with(func(q *UserQuery) { q.EmailLike = "%butex%" })},
+		{"dept=te", with(func(q *UserQuery) { q.Dept = "TE" })},
+		{"dept=Textile%20Engineering", with(func(q *UserQuery) { q.Dept = "TE" })},
+		{"dept=Textile%20Engineering%20Management", with(func(q *UserQuery) { q.Dept = "TEM" })},
+		{"dept=Computer", with(func(q *UserQuery) { q.DeptLike = "%Computer%" })},
+		{"role=Lecturer", with(func(q *UserQuery) { q.Role = "teacher" })},
+		{"role=admin", with(func(q *UserQuery) { q.Role = "admin" })},
+		{"batch=045", with(func(q *UserQuery) { q.Batch = "45" })},
+		{"batch_min=40&batch_max=45", with(func(q *UserQuery) { q.BatchMin, q.BatchMax = 40, 45 })},
+		{"created_from=2026-01-01&created_to=2026-02-01", with(func(q *UserQuery) {
+			q.CreatedFrom, q.CreatedTo = "2026-01-01", "2026-02-01"
+		})},
+		{"include_deleted=true&search=tex", with(func(q *UserQuery) { q.IncludeDeleted, q.Search = true, "tex" })},
+		{"sort=batch&order=asc&page=3&limit=50", with(func(q *UserQuery) { q.Sort, q.Order, q.Page, q.Limit = "batch", "asc", 3, 50 })},
+		{"page=0&limit=1000", defaults},
+		{"fields=id,%20email", with(func(q *UserQuery) { q.Fields = []string{"id", "email"} })},
+	}
+	for _, tt := range tests {
+		q, paramErrors := parseTestUserQuery(t, tt.rawQuery)
+		if len(paramErrors) != 0 {
+			t.Errorf("%q: unexpected errors %v", tt.rawQuery, paramErrors)
+		}
+		if !reflect.DeepEqual(q, tt.want) {
+			t.Errorf("%q:\n got  %+v\n want %+v", tt.rawQuery, q, tt.want)
+		}
+	}
+}
+
+func TestParseUserQueryErrors(t *testing.T) {
+	tests := []struct {
+		rawQuery string
+		param    string
+	}{
+		{"batch_min=45&batch_max=40", "batch_min"},
+		{"batch_min=-1", "batch_min"},
+		{"created_from=01-01-2026", "created_from"},
+		{"created_to=2026-02-30", "created_to"},
+		{"sort=password", "sort"},
+		{"order=sideways", "order"},
+		{"fields=id,password", "fields"},
+	}
+	for _, tt := range tests {
+		_, paramErrors := parseTestUserQuery(t, tt.rawQuery)
+		if _, ok := paramErrors[tt.param]; !ok || len(paramErrors) != 1 {
+			t.Errorf("%q: errors = %v, want only %s", tt.rawQuery, paramErrors, tt.param)
+		}
+	}
+}
+
+func TestUserQueryWhere(t *testing.T) {
+	where, params := UserQuery{Dept: "TE", DeptLike: "%x%", EmailLike: "%a%", Role: "student"}.where()
+	wantWhere := "deleted_at IS NULL AND email LIKE ? AND dept LIKE ? AND dept = ? AND role = ?"
+	if where != wantWhere {
+		t.Errorf("where = %q, want %q", where, wantWhere)
+	}
+	if !reflect.DeepEqual(params, []interface{}{"%a%", "%x%", "TE", "student"}) {
+		t.Errorf("params = %v", params)
+	}
+
+	if where, params := (UserQuery{IncludeDeleted: true}).where(); where != "1=1" || len(params) != 0 {
+		t.Errorf("empty query = %q %v, want 1=1", where, params)
+	}
+}
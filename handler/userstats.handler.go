@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Supported signup intervals and their MySQL DATE_FORMAT patterns
+var signupIntervals = map[string]string{
+	"day":   "%Y-%m-%d",
+	"week":  "%x-W%v",
+	"month": "%Y-%m",
+}
+
+// GetUserStats handles fetching user counts by dept, batch and role, a dept x batch
+// cross tabulation and signups over time (interval=day|week|month)
+func GetUserStats(db *gorm.DB) fiber.Handler {
+	log.Println("🟢 GET: GetUserStats handler called")
+	return func(c *fiber.Ctx) error {
+		interval := c.Query("interval", "month")
+		format, ok := signupIntervals[interval]
+		if !ok {
+			return c.Status(400).JSON(fiber.Map{"status": config.AppMessages.User.InvalidInterval})
+		}
+
+		var total int64
+		var byDept, byBatch, byRole, deptBatch, signups []map[string]interface{}
+		var undated struct {
+			Count int64
+			MinID *int64
+			MaxID *int64
+		}
+
+		queries := []struct {
+			name  string
+			query string
+			dest  interface{}
+		}{
+			{"total", "SELECT COUNT(*) FROM app_users WHERE deleted_at IS NULL", &total},
+			{"by dept", `
+				SELECT dept, COUNT(*) AS count
+				FROM app_users
+				WHERE deleted_at IS NULL
+				GROUP BY dept
+				ORDER BY count DESC`, &byDept},
+			{"by batch", `
+				SELECT batch, COUNT(*) AS count
+				FROM app_users
+				WHERE deleted_at IS NULL
+				GROUP BY batch
+				ORDER BY CAST(batch AS UNSIGNED) ASC`, &byBatch},
+			{"by role", `
+				SELECT role, COUNT(*) AS count
+				FROM app_users
+				WHERE deleted_at IS NULL
+				GROUP BY role
+				ORDER BY count DESC`, &byRole},
+			{"dept x batch", `
+				SELECT dept, batch, COUNT(*) AS count
+				FROM app_users
+				WHERE deleted_at IS NULL
+				GROUP BY dept, batch`, &deptBatch},
+			{"signups", `
+				SELECT DATE_FORMAT(created_at, '` + format + `') AS period, COUNT(*) AS count
+				FROM app_users
+				WHERE deleted_at IS NULL AND created_at IS NOT NULL
+				GROUP BY period
+				ORDER BY period ASC`, &signups},
+			// Users registered before created_at existed can only be placed by their id
+			{"undated signups", `
+				SELECT COUNT(*) AS count, MIN(id) AS min_id, MAX(id) AS max_id
+				FROM app_users
+				WHERE deleted_at IS NULL AND created_at IS NULL`, &undated},
+		}
+
+		for _, q := range queries {
+			if err := db.Raw(q.query).Scan(q.dest).Error; err != nil {
+				log.Printf("🔴 Error while fetching user stats (%s): %v", q.name, err)
+				return c.Status(500).JSON(fiber.Map{"status": config.AppMessages.User.FetchError})
+			}
+		}
+
+		// Pivot the dept x batch rows into dept -> batch -> count
+		crossTab := map[string]map[string]interface{}{}
+		for _, row := range deptBatch {
+			dept, batch := statKey(row["dept"]), statKey(row["batch"])
+			if crossTab[dept] == nil {
+				crossTab[dept] = map[string]interface{}{}
+			}
+			crossTab[dept][batch] = row["count"]
+		}
+
+		// Add a running total so growth can be read straight off the series
+		cumulative := undated.Count
+		for _, row := range signups {
+			if count, ok := row["count"].(int64); ok {
+				cumulative += count
+			}
+			row["cumulative"] = cumulative
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"total":        total,
+			"by_dept":      byDept,
+			"by_batch":     byBatch,
+			"by_role":      byRole,
+			"dept_x_batch": crossTab,
+			"signups": fiber.Map{
+				"interval": interval,
+				"series":   signups,
+				"undated": fiber.Map{
+					"count":  undated.Count,
+					"min_id": undated.MinID,
+					"max_id": undated.MaxID,
+				},
+			},
+		})
+	}
+}
+
+// statKey turns a grouped column value into a map key, naming missing values explicitly
+func statKey(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "unknown"
+	case string:
+		if v == "" {
+			return "unknown"
+		}
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestStatKey(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, "unknown"},
+		{"", "unknown"},
+		{"TE", "TE"},
+		{int64(45), "45"},
+	}
+	for _, tt := range tests {
+		if got := statKey(tt.value); got != tt.want {
+			t.Errorf("statKey(%#v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestGetUserStats(t *testing.T) {
+	db, rec := newRecordingDB(t)
+	rec.respond = func(query string) ([]string, [][]driver.Value) {
+		switch {
+		case strings.Contains(query, "SELECT COUNT(*) FROM app_users"):
+			return []string{"count"}, [][]driver.Value{{int64(6)}}
+		case strings.Contains(query, "GROUP BY dept, batch"):
+			return []string{"dept", "batch", "count"}, [][]driver.Value{
+				{"TE", "45", int64(3)}, {"TE", "46", int64(1)}, {nil, "45", int64(2)},
+			}
+		case strings.Contains(query, "AS period"):
+			return []string{"period", "count"}, [][]driver.Value{{"2026-01", int64(2)}, {"2026-02", int64(1)}}
+		case strings.Contains(query, "created_at IS NULL"):
+			return []string{"count", "min_id", "max_id"}, [][]driver.Value{{int64(3), int64(1), int64(3)}}
+		}
+		return nil, nil
+	}
+	app := newTestApp()
+	app.Get("/users/app/stats", GetUserStats(db))
+
+	status, body := doRequest(t, app, fiber.MethodGet, "/users/app/stats?interval=week", "")
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d (body %s)", status, body)
+	}
+
+	var response struct {
+		Total      int64                             `json:"total"`
+		DeptXBatch map[string]map[string]interface{} `json:"dept_x_batch"`
+		Signups    struct {
+			Interval string                   `json:"interval"`
+			Series   []map[string]interface{} `json:"series"`
+			Undated  map[string]interface{}   `json:"undated"`
+		} `json:"signups"`
+	}
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Total != 6 {
+		t.Errorf("total = %d, want 6", response.Total)
+	}
+	wantCrossTab := map[string]map[string]interface{}{
+		"TE":      {"45": float64(3), "46": float64(1)},
+		"unknown": {"45": float64(2)},
+	}
+	if !reflect.DeepEqual(response.DeptXBatch, wantCrossTab) {
+		t.Errorf("dept_x_batch = %v, want %v", response.DeptXBatch, wantCrossTab)
+	}
+
+	// Undated users come first in the running total
+	cumulative := []interface{}{}
+	for _, row := range response.Signups.Series {
+		cumulative = append(cumulative, row["cumulative"])
+	}
+	if response.Signups.Interval != "week" || !reflect.DeepEqual(cumulative, []interface{}{float64(5), float64(6)}) {
+		t.Errorf("signups = %+v, want a weekly series ending at 6", response.Signups)
+	}
+	if response.Signups.Undated["count"] != float64(3) {
+		t.Errorf("undated = %v", response.Signups.Undated)
+	}
+	if len(rec.Find("DATE_FORMAT(created_at, '%x-W%v')")) != 1 {
+		t.Errorf("statements = %v, want signups grouped by ISO week", rec.Statements())
+	}
+}
+
+func TestGetUserStatsRejectsUnknownInterval(t *testing.T) {
+	db, rec := newRecordingDB(t)
+	app := newTestApp()
+	app.Get("/users/app/stats", GetUserStats(db))
+
+	if status, body := doRequest(t, app, fiber.MethodGet, "/users/app/stats?interval=year", ""); status != fiber.StatusBadRequest {
+		t.Errorf("status = %d, want 400 (body %s)", status, body)
+	}
+	if statements := rec.Statements(); len(statements) != 0 {
+		t.Errorf("ran %v", statements)
+	}
+}
@@ -0,0 +1,185 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+)
+
+type RuleKind string
+
+const (
+	// RuleNewIssue fires when an error log creates a new issue
+	RuleNewIssue RuleKind = "new_issue"
+	// RuleErrorRate fires when the number of ingested logs within Window reaches Threshold
+	RuleErrorRate RuleKind = "error_rate"
+)
+
+type Rule struct {
+	Name      string
+	Kind      RuleKind
+	Threshold int
+	Window    time.Duration
+	Cooldown  time.Duration
+}
+
+// Event describes a single ingested error log
+type Event struct {
+	IssueID    int
+	IssueTitle string
+	IsNewIssue bool
+	AppVersion string
+	OS         string
+}
+
+// Manager evaluates alert rules against ingested error logs and dispatches notifications
+type Manager struct {
+	notifier  *Notifier
+	rules     []Rule
+	mu        sync.Mutex
+	lastFired map[string]time.Time
+	ingested  []time.Time
+	now       func() time.Time
+}
+
+// NewManager builds the default rule set from config. Alerting is disabled when no webhook URL is configured.
+func NewManager(alertConfig config.AlertConfig) *Manager {
+	rules := []Rule{
+		{Name: "new-issue", Kind: RuleNewIssue, Cooldown: alertConfig.NEW_ISSUE_COOLDOWN},
+	}
+	if alertConfig.RATE_THRESHOLD > 0 && alertConfig.RATE_WINDOW > 0 {
+		rules = append(rules, Rule{
+			Name:      "error-rate",
+			Kind:      RuleErrorRate,
+			Threshold: alertConfig.RATE_THRESHOLD,
+			Window:    alertConfig.RATE_WINDOW,
+			Cooldown:  alertConfig.RATE_COOLDOWN,
+		})
+	}
+
+	if len(alertConfig.WEBHOOK_URLS) == 0 {
+		log.Println("⚠️ No ALERT_WEBHOOK_URLS configured, error alerts are disabled")
+	}
+
+	return &Manager{
+		notifier:  NewNotifier(alertConfig.WEBHOOK_URLS, alertConfig.MAX_RETRIES),
+		rules:     rules,
+		lastFired: map[string]time.Time{},
+		now:       time.Now,
+	}
+}
+
+// Enabled reports whether there is anywhere to send alerts
+func (m *Manager) Enabled() bool {
+	return m != nil && len(m.notifier.URLs) > 0
+}
+
+// Rules returns the active alert rules
+func (m *Manager) Rules() []Rule {
+	return m.rules
+}
+
+// ErrorIngested records an ingested error log and fires any rule it triggers.
+// Notifications are delivered in the background so ingestion is never slowed down.
+func (m *Manager) ErrorIngested(event Event) {
+	if !m.Enabled() {
+		return
+	}
+
+	for _, msg := range m.evaluate(event) {
+		go func(msg Message) {
+			if err := m.notifier.Send(context.Background(), msg); err != nil {
+				log.Printf("🔴 Error while delivering alert %q: %v", msg.Title, err)
+			}
+		}(msg)
+	}
+}
+
+// SendTest delivers a test message synchronously so webhook configuration can be verified
+func (m *Manager) SendTest(ctx context.Context) error {
+	return m.notifier.Send(ctx, Message{
+		Title: "🧪 Test alert",
+		Text:  "NoteBot Analytics error alerts are configured correctly.",
+	})
+}
+
+func (m *Manager) evaluate(event Event) []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.trackIngest(now)
+
+	var messages []Message
+	for _, rule := range m.rules {
+		var msg Message
+		switch rule.Kind {
+		case RuleNewIssue:
+			if !event.IsNewIssue {
+				continue
+			}
+			msg = Message{
+				Title: fmt.Sprintf("🆕 New issue #%d", event.IssueID),
+				Text:  fmt.Sprintf("%s\nOS: %s, app version: %s", event.IssueTitle, event.OS, orUnknown(event.AppVersion)),
+			}
+		case RuleErrorRate:
+			count := m.countSince(now.Add(-rule.Window))
+			if count < rule.Threshold {
+				continue
+			}
+			msg = Message{
+				Title: "📈 Error rate threshold exceeded",
+				Text:  fmt.Sprintf("%d errors in the last %s (threshold %d)", count, rule.Window, rule.Threshold),
+			}
+		default:
+			continue
+		}
+
+		if last, ok := m.lastFired[rule.Name]; ok && now.Sub(last) < rule.Cooldown {
+			continue
+		}
+		m.lastFired[rule.Name] = now
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+// trackIngest appends the ingest time and drops timestamps no rate rule can still look at
+func (m *Manager) trackIngest(now time.Time) {
+	var longest time.Duration
+	for _, rule := range m.rules {
+		if rule.Kind == RuleErrorRate && rule.Window > longest {
+			longest = rule.Window
+		}
+	}
+	if longest == 0 {
+		return
+	}
+
+	m.ingested = append(m.ingested, now)
+	cutoff := now.Add(-longest)
+	keepFrom := 0
+	for keepFrom < len(m.ingested) && m.ingested[keepFrom].Before(cutoff) {
+		keepFrom++
+	}
+	m.ingested = m.ingested[keepFrom:]
+}
+
+func (m *Manager) countSince(since time.Time) int {
+	count := 0
+	for i := len(m.ingested) - 1; i >= 0 && !m.ingested[i].Before(since); i-- {
+		count++
+	}
+	return count
+}
+
+func orUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
@@ -0,0 +1,132 @@
+package alerts
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+)
+
+// testClock is a manually advanced clock for cooldown tests
+type testClock struct{ now time.Time }
+
+func (c *testClock) Now() time.Time          { return c.now }
+func (c *testClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestManager(t *testing.T, alertConfig config.AlertConfig) (*Manager, *webhookStub, *testClock) {
+	t.Helper()
+	stub := newWebhookStub(t)
+	alertConfig.WEBHOOK_URLS = []string{stub.URL}
+	manager := NewManager(alertConfig)
+	clock := &testClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	manager.now = clock.Now
+	return manager, stub, clock
+}
+
+// expectAlerts waits for exactly the given titles to be delivered, in any order
+func expectAlerts(t *testing.T, stub *webhookStub, titles ...string) {
+	t.Helper()
+	for range titles {
+		select {
+		case payload := <-stub.received:
+			found := false
+			for _, title := range titles {
+				if strings.HasPrefix(payload.Content, "**"+title) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("unexpected alert %q", payload.Content)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for alerts %v", titles)
+		}
+	}
+	select {
+	case payload := <-stub.received:
+		t.Errorf("unexpected extra alert %q", payload.Content)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestNewIssueAlertCooldown(t *testing.T) {
+	manager, stub, clock := newTestManager(t, config.AlertConfig{NEW_ISSUE_COOLDOWN: 10 * time.Minute})
+
+	manager.ErrorIngested(Event{IssueID: 1, IssueTitle: "first", IsNewIssue: true})
+	expectAlerts(t, stub, "🆕 New issue #1")
+
+	// A burst of other new issues within the cooldown is held back
+	for id := 2; id <= 5; id++ {
+		clock.Advance(time.Minute)
+		manager.ErrorIngested(Event{IssueID: id, IssueTitle: "burst", IsNewIssue: true})
+	}
+	expectAlerts(t, stub)
+
+	// The next new issue after the cooldown alerts again
+	clock.Advance(6 * time.Minute)
+	manager.ErrorIngested(Event{IssueID: 6, IssueTitle: "later", IsNewIssue: true})
+	expectAlerts(t, stub, "🆕 New issue #6")
+
+	// Existing issues never trigger the new issue rule
+	clock.Advance(10 * time.Minute)
+	manager.ErrorIngested(Event{IssueID: 3, IssueTitle: "old"})
+	expectAlerts(t, stub)
+}
+
+func TestAlertCooldownsArePerRule(t *testing.T) {
+	manager, stub, _ := newTestManager(t, config.AlertConfig{
+		NEW_ISSUE_COOLDOWN: 10 * time.Minute,
+		RATE_THRESHOLD:     2,
+		RATE_WINDOW:        time.Minute,
+		RATE_COOLDOWN:      10 * time.Minute,
+	})
+
+	manager.ErrorIngested(Event{IssueID: 1, IsNewIssue: true})
+	expectAlerts(t, stub, "🆕 New issue #1")
+
+	// The new issue rule cooling down doesn't hold back the rate rule
+	manager.ErrorIngested(Event{IssueID: 2, IsNewIssue: true})
+	expectAlerts(t, stub, "📈 Error rate threshold exceeded")
+}
+
+func TestErrorRateAlert(t *testing.T) {
+	manager, stub, clock := newTestManager(t, config.AlertConfig{
+		RATE_THRESHOLD: 3,
+		RATE_WINDOW:    time.Minute,
+		RATE_COOLDOWN:  5 * time.Minute,
+	})
+
+	manager.ErrorIngested(Event{})
+	manager.ErrorIngested(Event{})
+	expectAlerts(t, stub)
+
+	manager.ErrorIngested(Event{})
+	expectAlerts(t, stub, "📈 Error rate threshold exceeded")
+
+	// Still above the threshold, but within the cooldown
+	manager.ErrorIngested(Event{})
+	expectAlerts(t, stub)
+
+	// Old errors fall out of the window
+	clock.Advance(6 * time.Minute)
+	manager.ErrorIngested(Event{})
+	expectAlerts(t, stub)
+
+	manager.ErrorIngested(Event{})
+	manager.ErrorIngested(Event{})
+	expectAlerts(t, stub, "📈 Error rate threshold exceeded")
+}
+
+func TestDisabledManagerSendsNothing(t *testing.T) {
+	manager := NewManager(config.AlertConfig{NEW_ISSUE_COOLDOWN: time.Minute})
+	if manager.Enabled() {
+		t.Fatal("manager without webhook URLs is enabled")
+	}
+	manager.ErrorIngested(Event{IsNewIssue: true})
+
+	var nilManager *Manager
+	if nilManager.Enabled() {
+		t.Fatal("nil manager is enabled")
+	}
+}
@@ -0,0 +1,109 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Message is a single alert notification
+type Message struct {
+	Title string
+	Text  string
+}
+
+// webhookPayload is accepted by both Discord ("content") and Slack ("text") incoming webhooks
+type webhookPayload struct {
+	Username string `json:"username"`
+	Content  string `json:"content"`
+	Text     string `json:"text"`
+}
+
+// Notifier delivers messages to webhook URLs, retrying failed deliveries with exponential backoff
+type Notifier struct {
+	URLs        []string
+	Client      *http.Client
+	MaxRetries  int
+	BaseBackoff time.Duration
+}
+
+// NewNotifier creates a notifier with sensible HTTP timeouts
+func NewNotifier(urls []string, maxRetries int) *Notifier {
+	return &Notifier{
+		URLs:        urls,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		MaxRetries:  maxRetries,
+		BaseBackoff: time.Second,
+	}
+}
+
+// Send posts the message to every configured URL and returns the combined delivery errors
+func (n *Notifier) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(webhookPayload{
+		Username: "NoteBot Analytics",
+		Content:  fmt.Sprintf("**%s**\n%s", msg.Title, msg.Text),
+		Text:     fmt.Sprintf("*%s*\n%s", msg.Title, msg.Text),
+	})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, url := range n.URLs {
+		if err := n.deliver(ctx, url, body); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", url, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) deliver(ctx context.Context, url string, body []byte) error {
+	var lastErr error
+	for attempt := 0; attempt <= n.MaxRetries; attempt++ {
+		if attempt > 0 {
+			backoff := n.BaseBackoff * time.Duration(1<<(attempt-1))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+		}
+
+		retry, err := n.post(ctx, url, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return lastErr
+}
+
+// post makes a single delivery attempt and reports whether a failure is worth retrying
+func (n *Notifier) post(ctx context.Context, url string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	// Rate limits and server errors may succeed later, other client errors will not
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+}
@@ -0,0 +1,112 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookStub is a local webhook endpoint that answers with the queued status
+// codes (200 once they run out) and records every payload it receives
+type webhookStub struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	payloads []webhookPayload
+	received chan webhookPayload
+}
+
+func newWebhookStub(t *testing.T, statuses ...int) *webhookStub {
+	t.Helper()
+	stub := &webhookStub{statuses: statuses, received: make(chan webhookPayload, 16)}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload webhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode webhook payload: %v", err)
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", r.Header.Get("Content-Type"))
+		}
+
+		stub.mu.Lock()
+		stub.payloads = append(stub.payloads, payload)
+		status := http.StatusOK
+		if len(stub.statuses) > 0 {
+			status, stub.statuses = stub.statuses[0], stub.statuses[1:]
+		}
+		stub.mu.Unlock()
+
+		w.WriteHeader(status)
+		stub.received <- payload
+	}))
+	t.Cleanup(stub.Close)
+	return stub
+}
+
+func (s *webhookStub) Count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.payloads)
+}
+
+func TestNotifierSend(t *testing.T) {
+	stub := newWebhookStub(t)
+	notifier := NewNotifier([]string{stub.URL}, 0)
+
+	if err := notifier.Send(context.Background(), Message{Title: "Title", Text: "Body"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	payload := <-stub.received
+	if payload.Username != "NoteBot Analytics" {
+		t.Errorf("username = %q", payload.Username)
+	}
+	if payload.Content != "**Title**\nBody" || payload.Text != "*Title*\nBody" {
+		t.Errorf("payload = %+v", payload)
+	}
+}
+
+func TestNotifierRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		wantCalls int
+		wantErr   bool
+	}{
+		{"server error then success", []int{500, 200}, 2, false},
+		{"rate limited then success", []int{429, 429, 200}, 3, false},
+		{"client error is not retried", []int{400}, 1, true},
+		{"gives up after max retries", []int{503, 503, 503, 503}, 3, true},
+	}
+	for _, tt := range tests {
+		stub := newWebhookStub(t, tt.statuses...)
+		notifier := NewNotifier([]string{stub.URL}, 2)
+		notifier.BaseBackoff = time.Millisecond
+
+		err := notifier.Send(context.Background(), Message{Title: "t"})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+		if got := stub.Count(); got != tt.wantCalls {
+			t.Errorf("%s: webhook called %d times, want %d", tt.name, got, tt.wantCalls)
+		}
+	}
+}
+
+func TestNotifierSendsToEveryURL(t *testing.T) {
+	failing := newWebhookStub(t, 404)
+	working := newWebhookStub(t)
+	notifier := NewNotifier([]string{failing.URL, working.URL}, 0)
+
+	err := notifier.Send(context.Background(), Message{Title: "t"})
+	if err == nil || !strings.Contains(err.Error(), failing.URL) {
+		t.Errorf("err = %v, want failure naming %s", err, failing.URL)
+	}
+	if working.Count() != 1 {
+		t.Errorf("working webhook called %d times, want 1", working.Count())
+	}
+}
@@ -0,0 +1,331 @@
+package jobs
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"gorm.io/gorm"
+)
+
+// ErrorLogRetention removes error logs older than the configured age, archiving them first
+// when running in archive mode. Issue aggregates in app_err_issues are never touched, so
+// occurrence counts and first/last seen dates survive after the raw rows are gone.
+// One instance is shared by the scheduler and the admin endpoints so their runs never overlap.
+type ErrorLogRetention struct {
+	db  *gorm.DB
+	cfg config.RetentionConfig
+	// Only one run may touch app_err_logs or the archives at a time
+	mu sync.Mutex
+}
+
+type RetentionPreview struct {
+	Cutoff        time.Time                `json:"cutoff"`
+	Mode          string                   `json:"mode"`
+	RowsToRemove  int64                    `json:"rows_to_remove"`
+	OldestLogDate *time.Time               `json:"oldest_log_date"`
+	NewestLogDate *time.Time               `json:"newest_log_date"`
+	ByIssue       []map[string]interface{} `json:"by_issue"`
+	Sample        []map[string]interface{} `json:"sample"`
+}
+
+type RetentionResult struct {
+	Cutoff      time.Time `json:"cutoff"`
+	Mode        string    `json:"mode"`
+	RowsRemoved int64     `json:"rows_removed"`
+	ArchiveFile string    `json:"archive_file,omitempty"`
+}
+
+func NewErrorLogRetention(db *gorm.DB, cfg config.RetentionConfig) *ErrorLogRetention {
+	return &ErrorLogRetention{db: db, cfg: cfg}
+}
+
+// Enabled reports whether a retention age is configured; a zero age keeps logs forever
+func (r *ErrorLogRetention) Enabled() bool {
+	return r.cfg.MAX_AGE > 0
+}
+
+// Cutoff is the date before which logs are removed
+func (r *ErrorLogRetention) Cutoff() time.Time {
+	return time.Now().Add(-r.cfg.MAX_AGE)
+}
+
+// Start runs the retention job in the background once immediately and then every configured interval
+func (r *ErrorLogRetention) Start() {
+	if !r.Enabled() || r.cfg.INTERVAL <= 0 {
+		log.Println("⚠️ Error log retention is disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(r.cfg.INTERVAL)
+		defer ticker.Stop()
+
+		for {
+			result, err := r.Run()
+			if err != nil {
+				log.Printf("🔴 Error while running error log retention: %v", err)
+			} else if result.RowsRemoved > 0 {
+				log.Printf("🟢 Error log retention removed %d logs older than %s", result.RowsRemoved, result.Cutoff.Format(time.DateOnly))
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// Preview reports what a run would remove without changing anything
+func (r *ErrorLogRetention) Preview(sampleSize int) (RetentionPreview, error) {
+	preview := RetentionPreview{
+		Cutoff:  r.Cutoff(),
+		Mode:    r.cfg.MODE,
+		ByIssue: []map[string]interface{}{},
+		Sample:  []map[string]interface{}{},
+	}
+	if !r.Enabled() {
+		return preview, nil
+	}
+
+	var bounds struct {
+		Total  int64
+		Oldest *time.Time
+		Newest *time.Time
+	}
+	if err := r.db.Raw(`
+		SELECT COUNT(*) AS total, MIN(date) AS oldest, MAX(date) AS newest
+		FROM app_err_logs
+		WHERE date < ?`, preview.Cutoff).Scan(&bounds).Error; err != nil {
+		return preview, err
+	}
+	preview.RowsToRemove = bounds.Total
+	preview.OldestLogDate = bounds.Oldest
+	preview.NewestLogDate = bounds.Newest
+
+	if err := r.db.Raw(`
+		SELECT i.id AS issue_id, i.title, i.status, COUNT(*) AS rows_to_remove
+		FROM app_err_logs l
+		LEFT JOIN app_err_issues i ON i.fingerprint = l.fingerprint
+		WHERE l.date < ?
+		GROUP BY i.id, i.title, i.status
+		ORDER BY rows_to_remove DESC
+		LIMIT 20`, preview.Cutoff).Scan(&preview.ByIssue).Error; err != nil {
+		return preview, err
+	}
+
+	if err := r.db.Raw("SELECT * FROM app_err_logs WHERE date < ? ORDER BY date ASC LIMIT ?", preview.Cutoff, sampleSize).
+		Scan(&preview.Sample).Error; err != nil {
+		return preview, err
+	}
+
+	return preview, nil
+}
+
+// Run removes every log older than the cutoff in batches, archiving each batch first in archive mode
+func (r *ErrorLogRetention) Run() (RetentionResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := RetentionResult{Cutoff: r.Cutoff(), Mode: r.cfg.MODE}
+	if !r.Enabled() {
+		return result, nil
+	}
+
+	var archive *logArchive
+	defer func() {
+		if archive != nil {
+			if err := archive.Close(); err != nil {
+				log.Printf("🔴 Error while closing error log archive: %v", err)
+			}
+		}
+	}()
+
+	for {
+		var rows []map[string]interface{}
+		if err := r.db.Raw("SELECT * FROM app_err_logs WHERE date < ? ORDER BY id ASC LIMIT ?", result.Cutoff, r.cfg.BATCH_SIZE).
+			Scan(&rows).Error; err != nil {
+			return result, err
+		}
+		if len(rows) == 0 {
+			return result, nil
+		}
+
+		if r.cfg.MODE == config.RetentionModeArchive {
+			if archive == nil {
+				var err error
+				if archive, err = newLogArchive(r.cfg.ARCHIVE_DIR); err != nil {
+					return result, err
+				}
+				result.ArchiveFile = archive.Path
+			}
+			if err := archive.Write(rows); err != nil {
+				return result, err
+			}
+		}
+
+		ids := make([]interface{}, 0, len(rows))
+		for _, row := range rows {
+			ids = append(ids, row["id"])
+		}
+
+		deleted := r.db.Exec("DELETE FROM app_err_logs WHERE id IN ?", ids)
+		if deleted.Error != nil {
+			return result, deleted.Error
+		}
+		result.RowsRemoved += deleted.RowsAffected
+	}
+}
+
+// logArchive writes error log rows as gzip compressed JSON lines
+type logArchive struct {
+	Path string
+	file *os.File
+	gz   *gzip.Writer
+	enc  *json.Encoder
+}
+
+func newLogArchive(dir string) (*logArchive, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	// Nanoseconds keep a manual run from colliding with a scheduled one started in the same second
+	path := filepath.Join(dir, fmt.Sprintf("app_err_logs-%s.jsonl.gz", time.Now().Format("20060102T150405.000000000")))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(file)
+	return &logArchive{Path: path, file: file, gz: gz, enc: json.NewEncoder(gz)}, nil
+}
+
+func (a *logArchive) Write(rows []map[string]interface{}) error {
+	for _, row := range rows {
+		// Keep JSON columns as nested objects rather than escaped strings
+		for _, column := range []string{"stack_trace", "tags"} {
+			if raw, ok := row[column].(string); ok && json.Valid([]byte(raw)) {
+				row[column] = json.RawMessage(raw)
+			}
+		}
+		if err := a.enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	return a.gz.Flush()
+}
+
+func (a *logArchive) Close() error {
+	if err := a.gz.Close(); err != nil {
+		a.file.Close()
+		return err
+	}
+	return a.file.Close()
+}
+
+// ArchiveScrubResult reports what ScrubArchives removed
+type ArchiveScrubResult struct {
+	FilesRewritten int   `json:"files_rewritten"`
+	RowsRemoved    int64 `json:"rows_removed"`
+}
+
+// ScrubArchives removes every archived error log of an email from the archive directory.
+// Archives holding a matching row are rewritten without it, others are left untouched.
+func (r *ErrorLogRetention) ScrubArchives(email string) (ArchiveScrubResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result ArchiveScrubResult
+	paths, err := filepath.Glob(filepath.Join(r.cfg.ARCHIVE_DIR, "app_err_logs-*.jsonl.gz"))
+	if err != nil {
+		return result, err
+	}
+
+	for _, path := range paths {
+		removed, err := scrubArchive(path, email)
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", path, err)
+		}
+		if removed > 0 {
+			result.FilesRewritten++
+			result.RowsRemoved += removed
+		}
+	}
+	return result, nil
+}
+
+// scrubArchive rewrites a single archive without the rows of email and reports how many it dropped
+func scrubArchive(path, email string) (int64, error) {
+	kept, removed, err := readArchiveWithout(path, email)
+	if err != nil || removed == 0 {
+		return 0, err
+	}
+
+	// Write the scrubbed copy next to the original, then swap it in
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".scrub-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	out := gzip.NewWriter(tmp)
+	enc := json.NewEncoder(out)
+	for _, line := range kept {
+		if err := enc.Encode(line); err != nil {
+			tmp.Close()
+			return 0, err
+		}
+	}
+	if err := out.Close(); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return 0, err
+	}
+	return removed, os.Rename(tmp.Name(), path)
+}
+
+// readArchiveWithout reads the rows of an archive, leaving out and counting those of email
+func readArchiveWithout(path, email string) ([]json.RawMessage, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var kept []json.RawMessage
+	var removed int64
+	dec := json.NewDecoder(gz)
+	for dec.More() {
+		var line json.RawMessage
+		if err := dec.Decode(&line); err != nil {
+			return nil, 0, err
+		}
+		var row struct {
+			Email string `json:"email"`
+		}
+		if err := json.Unmarshal(line, &row); err != nil {
+			return nil, 0, err
+		}
+		if strings.EqualFold(strings.TrimSpace(row.Email), email) {
+			removed++
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return kept, removed, nil
+}
@@ -0,0 +1,127 @@
+package jobs
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+)
+
+func writeTestArchive(t *testing.T, dir string, rows ...map[string]interface{}) string {
+	t.Helper()
+	archive, err := newLogArchive(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := archive.Write(rows); err != nil {
+		t.Fatal(err)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return archive.Path
+}
+
+func readTestArchive(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rows []map[string]interface{}
+	dec := json.NewDecoder(gz)
+	for dec.More() {
+		var row map[string]interface{}
+		if err := dec.Decode(&row); err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestScrubArchives(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestArchive(t, dir,
+		map[string]interface{}{"id": 1, "email": "erase@butex.edu.bd", "log": "boom", "tags": `{"a":"b"}`},
+		map[string]interface{}{"id": 2, "email": "keep@butex.edu.bd", "log": "boom", "tags": `{"a":"b"}`},
+		map[string]interface{}{"id": 3, "email": " Erase@butex.edu.bd", "log": "bang"},
+	)
+	before, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	retention := NewErrorLogRetention(nil, config.RetentionConfig{ARCHIVE_DIR: dir})
+	result, err := retention.ScrubArchives("erase@butex.edu.bd")
+	if err != nil {
+		t.Fatalf("ScrubArchives: %v", err)
+	}
+	if result.FilesRewritten != 1 || result.RowsRemoved != 2 {
+		t.Errorf("result = %+v, want 1 file and 2 rows", result)
+	}
+
+	rows := readTestArchive(t, path)
+	if len(rows) != 1 || rows[0]["email"] != "keep@butex.edu.bd" {
+		t.Fatalf("archive rows after scrub = %v", rows)
+	}
+	if tags, ok := rows[0]["tags"].(map[string]interface{}); !ok || tags["a"] != "b" {
+		t.Errorf("kept row lost its nested tags: %v", rows[0]["tags"])
+	}
+	after, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Mode() != before.Mode() {
+		t.Errorf("archive mode changed from %v to %v", before.Mode(), after.Mode())
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(dir, ".scrub-*")); len(leftovers) != 0 {
+		t.Errorf("temporary files left behind: %v", leftovers)
+	}
+
+	// A second scrub finds nothing and leaves the file alone
+	result, err = retention.ScrubArchives("erase@butex.edu.bd")
+	if err != nil || result.FilesRewritten != 0 || result.RowsRemoved != 0 {
+		t.Errorf("second scrub = %+v, %v, want nothing removed", result, err)
+	}
+}
+
+func TestScrubArchivesWithoutArchiveDir(t *testing.T) {
+	retention := NewErrorLogRetention(nil, config.RetentionConfig{ARCHIVE_DIR: filepath.Join(t.TempDir(), "missing")})
+	result, err := retention.ScrubArchives("erase@butex.edu.bd")
+	if err != nil || result.FilesRewritten != 0 {
+		t.Errorf("ScrubArchives = %+v, %v, want nothing to do", result, err)
+	}
+}
+
+func TestNewLogArchiveNamesAreUnique(t *testing.T) {
+	dir := t.TempDir()
+	paths := map[string]bool{}
+	for i := 0; i < 5; i++ {
+		path := writeTestArchive(t, dir, map[string]interface{}{"id": i})
+		if paths[path] {
+			t.Fatalf("archive %s was created twice", path)
+		}
+		if rows := readTestArchive(t, path); len(rows) != 1 || rows[0]["id"] != float64(i) {
+			t.Errorf("archive %s holds %v, want id %d", path, rows, i)
+		}
+		paths[path] = true
+	}
+
+	archives, err := filepath.Glob(filepath.Join(dir, "app_err_logs-*.jsonl.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archives) != 5 {
+		t.Errorf("found %d archives, want 5", len(archives))
+	}
+}
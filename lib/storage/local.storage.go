@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Storage stores uploaded files and returns the URL path they are served from
+type Storage interface {
+	// Save writes the content under name, replacing any existing file, and returns its public URL path
+	Save(name string, content io.Reader) (string, error)
+	// Delete removes the file behind a URL path previously returned by Save
+	Delete(url string) error
+	// Owns reports whether a URL path was produced by this storage
+	Owns(url string) bool
+}
+
+// LocalStorage keeps files on the local filesystem under Dir, served at BaseURL
+type LocalStorage struct {
+	Dir     string
+	BaseURL string
+}
+
+func NewLocalStorage(dir, baseURL string) *LocalStorage {
+	return &LocalStorage{Dir: dir, BaseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+var errInvalidName = errors.New("invalid file name")
+
+// localPath maps a storage name to a path inside Dir, rejecting anything that escapes it
+func (s *LocalStorage) localPath(name string) (string, error) {
+	cleaned := path.Clean("/" + name)
+	if cleaned == "/" {
+		return "", errInvalidName
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(cleaned)), nil
+}
+
+func (s *LocalStorage) Save(name string, content io.Reader) (string, error) {
+	target, err := s.localPath(name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", err
+	}
+
+	// Write to a temporary file first so a failed upload never leaves a half written file behind
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", err
+	}
+
+	return s.BaseURL + path.Clean("/"+name), nil
+}
+
+func (s *LocalStorage) Delete(url string) error {
+	if !s.Owns(url) {
+		return errInvalidName
+	}
+	target, err := s.localPath(strings.TrimPrefix(url, s.BaseURL))
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStorage) Owns(url string) bool {
+	return strings.HasPrefix(url, s.BaseURL+"/")
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStorageSave(t *testing.T) {
+	dir := t.TempDir()
+	store := NewLocalStorage(dir, "/uploads/")
+
+	tests := []struct {
+		name string
+		url  string
+		path string
+	}{
+		{"avatars/1.png", "/uploads/avatars/1.png", "avatars/1.png"},
+		{"/avatars/2.png", "/uploads/avatars/2.png", "avatars/2.png"},
+		{"../../etc/passwd", "/uploads/etc/passwd", "etc/passwd"},
+		{"avatars/../../3.png", "/uploads/3.png", "3.png"},
+	}
+	for _, tt := range tests {
+		url, err := store.Save(tt.name, strings.NewReader(tt.name))
+		if err != nil {
+			t.Fatalf("Save(%q): %v", tt.name, err)
+		}
+		if url != tt.url {
+			t.Errorf("Save(%q) = %q, want %q", tt.name, url, tt.url)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, tt.path))
+		if err != nil || string(data) != tt.name {
+			t.Errorf("Save(%q) wrote %q (%v) to %s", tt.name, data, err, tt.path)
+		}
+	}
+
+	if _, err := store.Save("/", strings.NewReader("x")); err == nil {
+		t.Error("Save(\"/\") succeeded")
+	}
+
+	// Saving again replaces the file and leaves no temporary files behind
+	if _, err := store.Save("avatars/1.png", strings.NewReader("new")); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "avatars", "1.png")); string(data) != "new" {
+		t.Errorf("replaced file = %q, want new", data)
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, "avatars"))
+	if len(entries) != 2 {
+		t.Errorf("avatars dir holds %d files, want 2", len(entries))
+	}
+}
+
+func TestLocalStorageDelete(t *testing.T) {
+	dir := t.TempDir()
+	store := NewLocalStorage(dir, "/uploads")
+
+	url, err := store.Save("avatars/1.png", strings.NewReader("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(url); err != nil {
+		t.Fatalf("Delete(%q): %v", url, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "avatars", "1.png")); !os.IsNotExist(err) {
+		t.Errorf("file still exists after Delete: %v", err)
+	}
+	if err := store.Delete(url); err != nil {
+		t.Errorf("deleting a missing file: %v", err)
+	}
+
+	for _, url := range []string{"https://example.com/a.png", "/uploadsx/a.png", "/uploads/"} {
+		if err := store.Delete(url); err == nil {
+			t.Errorf("Delete(%q) succeeded", url)
+		}
+	}
+}
+
+func TestLocalStorageOwns(t *testing.T) {
+	store := NewLocalStorage(t.TempDir(), "/uploads")
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"/uploads/avatars/1.png", true},
+		{"/uploads", false},
+		{"/uploadsx/1.png", false},
+		{"https://lh3.googleusercontent.com/a.png", false},
+		{"not given", false},
+	}
+	for _, tt := range tests {
+		if got := store.Owns(tt.url); got != tt.want {
+			t.Errorf("Owns(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
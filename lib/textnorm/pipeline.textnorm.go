@@ -0,0 +1,85 @@
+package textnorm
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Step is a single text transformation in a normalisation pipeline
+type Step func(string) string
+
+// Pipeline runs its steps in order
+type Pipeline []Step
+
+// Options selects the optional pipeline steps
+type Options struct {
+	RemoveStopwords bool
+}
+
+// New builds the normalisation pipeline for user typed text in English, Bangla script and Banglish:
+// Unicode NFC, case folding, punctuation and emoji stripping, whitespace collapsing and, if enabled,
+// stopword removal
+func New(opts Options) Pipeline {
+	pipeline := Pipeline{NFC, CaseFold, StripSymbols, CollapseWhitespace}
+	if opts.RemoveStopwords {
+		pipeline = append(pipeline, RemoveStopwords)
+	}
+	return pipeline
+}
+
+func (p Pipeline) Apply(text string) string {
+	for _, step := range p {
+		text = step(text)
+	}
+	return text
+}
+
+// NFC composes characters so the same Bangla or accented text always has the same code points
+func NFC(text string) string {
+	return norm.NFC.String(text)
+}
+
+// CaseFold lowercases text for caseless matching. Bangla has no case and passes through unchanged.
+func CaseFold(text string) string {
+	return cases.Fold().String(text)
+}
+
+// StripSymbols replaces punctuation, symbols and emoji with spaces. Letters, digits and combining
+// marks are kept, the marks carry Bangla vowel signs. Invisible format characters such as
+// zero width joiners are dropped so words typed with and without them match.
+func StripSymbols(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), unicode.IsNumber(r), unicode.Is(unicode.Mn, r), unicode.Is(unicode.Mc, r):
+			return r
+		case unicode.Is(unicode.Cf, r), unicode.Is(unicode.Me, r):
+			return -1
+		default:
+			return ' '
+		}
+	}, text)
+}
+
+// CollapseWhitespace trims text and joins its words with single spaces
+func CollapseWhitespace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// RemoveStopwords drops filler words. Text made only of stopwords is returned as is, since an empty
+// result would lose the query entirely.
+func RemoveStopwords(text string) string {
+	words := strings.Fields(text)
+	kept := make([]string, 0, len(words))
+	for _, word := range words {
+		if !stopwords[word] {
+			kept = append(kept, word)
+		}
+	}
+	if len(kept) == 0 {
+		return text
+	}
+	return strings.Join(kept, " ")
+}
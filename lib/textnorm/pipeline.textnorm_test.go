@@ -0,0 +1,66 @@
+package textnorm
+
+import "testing"
+
+func TestPipeline(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		stopwords bool
+		want      string
+	}{
+		{"empty", "", false, ""},
+		{"case", "PHYSICS", false, "physics"},
+		{"punctuation and emoji", "Physics!! 📚", false, "physics"},
+		{"whitespace", "  applied \t\n physics  ", false, "applied physics"},
+		{"symbols split words", "yarn-manufacturing/notes", false, "yarn manufacturing notes"},
+		{"digits kept", "Chem 2nd paper", false, "chem 2nd paper"},
+		{"case folding beyond lowercase", "STRAßE", false, "strasse"},
+		{"decomposed accents", "Café", false, "café"},
+		{"bangla vowel signs kept", "রসায়ন!", false, "রসায়ন"},
+		{"bangla precomposed yya", "রসায়ন", false, "রসায়ন"},
+		{"zero width joiner dropped", "র‍যাব", false, "রযাব"},
+		{"stopwords kept by default", "physics notes please", false, "physics notes please"},
+		{"english stopwords", "Please give me the Physics notes", true, "physics notes"},
+		{"banglish stopwords", "bhai physics er note dao", true, "physics note"},
+		{"bangla stopwords", "ভাই রসায়ন এর নোট দাও", true, "রসায়ন নোট"},
+		{"only stopwords", "please give me", true, "please give me"},
+	}
+	for _, tt := range tests {
+		got := New(Options{RemoveStopwords: tt.stopwords}).Apply(tt.text)
+		if got != tt.want {
+			t.Errorf("%s: Apply(%q) = %q, want %q", tt.name, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestPipelineIsIdempotent(t *testing.T) {
+	pipeline := New(Options{RemoveStopwords: true})
+	for _, text := range []string{"Physics!! 📚", "ভাই রসায়ন এর নোট দাও", "STRAßE  Café", "please"} {
+		once := pipeline.Apply(text)
+		if twice := pipeline.Apply(once); twice != once {
+			t.Errorf("Apply(%q) = %q, but applying again gives %q", text, once, twice)
+		}
+	}
+}
+
+func TestSteps(t *testing.T) {
+	tests := []struct {
+		name string
+		step Step
+		text string
+		want string
+	}{
+		{"NFC", NFC, "é", "é"},
+		{"CaseFold", CaseFold, "ÀBC", "àbc"},
+		{"StripSymbols", StripSymbols, "a,b.c", "a b c"},
+		{"StripSymbols keeps marks", StripSymbols, "কিি", "কিি"},
+		{"CollapseWhitespace", CollapseWhitespace, " a  b ", "a b"},
+		{"RemoveStopwords", RemoveStopwords, "the notes", "notes"},
+	}
+	for _, tt := range tests {
+		if got := tt.step(tt.text); got != tt.want {
+			t.Errorf("%s(%q) = %q, want %q", tt.name, tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package textnorm
+
+import "strings"
+
+// Similarity scores two normalised strings from 0 (unrelated) to 1 (identical)
+type Similarity func(a, b string) float64
+
+// Spaces are ignored when comparing, so "phy 1 notes" and "phy1 notes" score as identical
+func compact(text string) []rune {
+	return []rune(strings.ReplaceAll(text, " ", ""))
+}
+
+// Levenshtein returns the number of single rune insertions, deletions and substitutions
+// needed to turn a into b
+func Levenshtein(a, b []rune) int {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// EditSimilarity is the Levenshtein distance scaled by the longer string's length
+func EditSimilarity(a, b string) float64 {
+	ra, rb := compact(a), compact(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(Levenshtein(ra, rb))/float64(longest)
+}
+
+// trigrams returns the rune trigrams of a string padded at both ends, so short strings still get some
+func trigrams(runes []rune) map[string]int {
+	padded := append(append([]rune{' ', ' '}, runes...), ' ')
+	grams := map[string]int{}
+	for i := 0; i+3 <= len(padded); i++ {
+		grams[string(padded[i:i+3])]++
+	}
+	return grams
+}
+
+// TrigramSimilarity is the Dice coefficient of the two strings' trigram sets
+func TrigramSimilarity(a, b string) float64 {
+	ga, gb := trigrams(compact(a)), trigrams(compact(b))
+	total := 0
+	for _, n := range ga {
+		total += n
+	}
+	for _, n := range gb {
+		total += n
+	}
+	if total == 0 {
+		return 1
+	}
+
+	shared := 0
+	for gram, n := range ga {
+		shared += min(n, gb[gram])
+	}
+	return 2 * float64(shared) / float64(total)
+}
+
+// Cluster groups strings whose similarity is at least threshold and returns the groups as indexes
+// into texts. Grouping is transitive: if a matches b and b matches c, all three share a cluster.
+func Cluster(texts []string, threshold float64, similarity Similarity) [][]int {
+	parent := make([]int, len(texts))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range texts {
+		for j := i + 1; j < len(texts); j++ {
+			if find(i) == find(j) {
+				continue
+			}
+			if similarity(texts[i], texts[j]) >= threshold {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	groups := map[int][]int{}
+	order := []int{}
+	for i := range texts {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			order = append(order, root)
+		}
+		groups[root] = append(groups[root], i)
+	}
+
+	clusters := make([][]int, 0, len(order))
+	for _, root := range order {
+		clusters = append(clusters, groups[root])
+	}
+	return clusters
+}
@@ -0,0 +1,86 @@
+package textnorm
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"physics", "phisics", 1},
+		{"physics", "physcis", 2},
+		{"রসায়ন", "রসায়ণ", 1},
+	}
+	for _, tt := range tests {
+		if got := Levenshtein([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := Levenshtein([]rune(tt.b), []rune(tt.a)); got != tt.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestSimilarities(t *testing.T) {
+	tests := []struct {
+		name       string
+		similarity Similarity
+		a, b       string
+		want       float64
+	}{
+		{"edit identical", EditSimilarity, "physics", "physics", 1},
+		{"edit both empty", EditSimilarity, "", "", 1},
+		{"edit ignores spaces", EditSimilarity, "phy 1 notes", "phy1 notes", 1},
+		{"edit one typo", EditSimilarity, "physics", "phisics", 1 - 1.0/7},
+		{"edit unrelated", EditSimilarity, "abc", "xyz", 0},
+		{"trigram identical", TrigramSimilarity, "physics", "physics", 1},
+		{"trigram both empty", TrigramSimilarity, "", "", 1},
+		{"trigram ignores spaces", TrigramSimilarity, "phy 1 notes", "phy1 notes", 1},
+		{"trigram unrelated", TrigramSimilarity, "abc", "xyz", 0},
+		{"trigram one empty", TrigramSimilarity, "abc", "", 0},
+	}
+	for _, tt := range tests {
+		got := tt.similarity(tt.a, tt.b)
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: similarity(%q, %q) = %v, want %v", tt.name, tt.a, tt.b, got, tt.want)
+		}
+		if reverse := tt.similarity(tt.b, tt.a); math.Abs(got-reverse) > 1e-9 {
+			t.Errorf("%s: similarity is not symmetric: %v and %v", tt.name, got, reverse)
+		}
+	}
+
+	// A typo keeps most trigrams, an unrelated word shares none
+	if typo, other := TrigramSimilarity("chemistry", "chemestry"), TrigramSimilarity("chemistry", "physics"); typo <= 0.5 || other >= typo {
+		t.Errorf("trigram similarity of a typo = %v, of an unrelated word = %v", typo, other)
+	}
+}
+
+func TestCluster(t *testing.T) {
+	texts := []string{"physics", "chemistry", "phisics", "math", "physcs", "chemestry"}
+	got := Cluster(texts, 0.7, EditSimilarity)
+	want := [][]int{{0, 2, 4}, {1, 5}, {3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Cluster = %v, want %v", got, want)
+	}
+
+	// Grouping is transitive even when the ends of a chain are not similar themselves
+	chain := []string{"aaaa", "aaab", "aabb"}
+	if EditSimilarity(chain[0], chain[2]) >= 0.75 {
+		t.Fatal("chain ends are too similar for this test")
+	}
+	if got := Cluster(chain, 0.75, EditSimilarity); !reflect.DeepEqual(got, [][]int{{0, 1, 2}}) {
+		t.Errorf("chained Cluster = %v, want one cluster", got)
+	}
+
+	if got := Cluster(nil, 0.5, EditSimilarity); len(got) != 0 {
+		t.Errorf("Cluster(nil) = %v, want no clusters", got)
+	}
+}
@@ -0,0 +1,21 @@
+package textnorm
+
+// stopwords are filler words seen in bot queries, in English, Banglish and Bangla script.
+// Entries must already be case folded and NFC normalised.
+var stopwords = map[string]bool{
+	// English
+	"a": true, "an": true, "the": true, "is": true, "are": true, "was": true, "of": true,
+	"for": true, "to": true, "in": true, "on": true, "and": true, "or": true, "me": true,
+	"my": true, "i": true, "please": true, "pls": true, "plz": true, "give": true,
+	"want": true, "need": true, "about": true, "what": true, "show": true, "send": true,
+
+	// Banglish
+	"ki": true, "ta": true, "ti": true, "er": true, "ar": true, "o": true, "amake": true,
+	"ami": true, "dao": true, "den": true, "din": true, "chai": true, "lagbe": true,
+	"bhai": true, "vai": true, "ekta": true, "koi": true, "kothay": true,
+
+	// Bangla
+	"কি": true, "কী": true, "টা": true, "টি": true, "এর": true, "আর": true, "ও": true,
+	"আমাকে": true, "আমি": true, "দাও": true, "দেন": true, "দিন": true, "চাই": true,
+	"লাগবে": true, "ভাই": true, "একটা": true, "কোথায়": true,
+}
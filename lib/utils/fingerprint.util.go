@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+var (
+	uuidRegex   = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	hexRegex    = regexp.MustCompile(`0x[0-9a-f]+`)
+	numberRegex = regexp.MustCompile(`\d+`)
+	spaceRegex  = regexp.MustCompile(`\s+`)
+)
+
+// ErrorSummary returns the first non-empty line of an error message, trimmed to 255 characters
+func ErrorSummary(message string) string {
+	summary := ""
+	for _, line := range strings.Split(message, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			summary = line
+			break
+		}
+	}
+
+	if runes := []rune(summary); len(runes) > 255 {
+		summary = string(runes[:255])
+	}
+	return summary
+}
+
+// ErrorFingerprint groups error messages that only differ in volatile values
+// (ids, addresses, counters) by hashing a normalised summary of the message.
+// The culprit (usually the top stack frame) is optional and keeps identical
+// messages thrown from different places apart.
+func ErrorFingerprint(message, culprit string) string {
+	normalised := strings.ToLower(ErrorSummary(message))
+	normalised = uuidRegex.ReplaceAllString(normalised, "<uuid>")
+	normalised = hexRegex.ReplaceAllString(normalised, "<hex>")
+	normalised = numberRegex.ReplaceAllString(normalised, "<n>")
+	normalised = spaceRegex.ReplaceAllString(normalised, " ")
+
+	if culprit != "" {
+		normalised += "|" + strings.ToLower(culprit)
+	}
+
+	sum := sha1.Sum([]byte(normalised))
+	return hex.EncodeToString(sum[:])
+}
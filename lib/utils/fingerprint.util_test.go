@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestErrorSummary(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"", ""},
+		{"TypeError: x is undefined", "TypeError: x is undefined"},
+		{"\n\n  first line  \nsecond line", "first line"},
+		{strings.Repeat("a", 300), strings.Repeat("a", 255)},
+		{strings.Repeat("অ", 300), strings.Repeat("অ", 255)},
+	}
+	for _, tt := range tests {
+		if got := ErrorSummary(tt.message); got != tt.want {
+			t.Errorf("ErrorSummary(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestErrorFingerprint(t *testing.T) {
+	tests := []struct {
+		name      string
+		a, b      [2]string
+		wantEqual bool
+	}{
+		{"numbers", [2]string{"Timeout after 3000ms", ""}, [2]string{"Timeout after 5000ms", ""}, true},
+		{"uuids", [2]string{"Note 3f2b6c1e-8a4d-4f1e-9b2a-0c6d7e8f9a1b missing", ""}, [2]string{"Note 00000000-1111-2222-3333-444444444444 missing", ""}, true},
+		{"hex addresses", [2]string{"segfault at 0xdeadbeef", ""}, [2]string{"segfault at 0xcafe", ""}, true},
+		{"case and spacing", [2]string{"Network   Error", ""}, [2]string{"network error", ""}, true},
+		{"only first line", [2]string{"boom\nat a.js:1", ""}, [2]string{"boom\nat b.js:2", ""}, true},
+		{"different messages", [2]string{"Network error", ""}, [2]string{"Parse error", ""}, false},
+		{"different culprits", [2]string{"boom", "load@a.js"}, [2]string{"boom", "save@b.js"}, false},
+		{"culprit case", [2]string{"boom", "Load@A.js"}, [2]string{"boom", "load@a.js"}, true},
+		{"culprit against message only", [2]string{"boom", "load@a.js"}, [2]string{"boom", ""}, false},
+	}
+	for _, tt := range tests {
+		a := ErrorFingerprint(tt.a[0], tt.a[1])
+		b := ErrorFingerprint(tt.b[0], tt.b[1])
+		if (a == b) != tt.wantEqual {
+			t.Errorf("%s: fingerprints equal = %v, want %v", tt.name, a == b, tt.wantEqual)
+		}
+		if len(a) != 40 {
+			t.Errorf("%s: fingerprint %q is not 40 hex characters", tt.name, a)
+		}
+	}
+}
+
+func TestErrorFingerprintMessageOnlyIsStable(t *testing.T) {
+	// Issues created before stack traces were accepted are keyed by this hash,
+	// so logs without a culprit must keep producing it: sha1("timeout after <n>ms")
+	const want = "644a050f59787be5361bbe9d394888f60f9f707e"
+	if got := ErrorFingerprint("Timeout after 3000ms", ""); got != want {
+		t.Errorf("ErrorFingerprint changed for message-only logs: got %s, want %s", got, want)
+	}
+}
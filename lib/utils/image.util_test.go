@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		name   string
+		bounds image.Rectangle
+		size   int
+		want   int
+	}{
+		{"scaled down", image.Rect(0, 0, 400, 400), 100, 100},
+		{"landscape is centre cropped", image.Rect(0, 0, 300, 100), 50, 50},
+		{"portrait is centre cropped", image.Rect(0, 0, 100, 300), 50, 50},
+		{"offset bounds", image.Rect(10, 20, 110, 220), 40, 40},
+		{"small images are not upscaled", image.Rect(0, 0, 30, 60), 256, 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thumbnail := Thumbnail(image.NewRGBA(tt.bounds), tt.size)
+			if bounds := thumbnail.Bounds(); bounds != image.Rect(0, 0, tt.want, tt.want) {
+				t.Errorf("bounds = %v, want %dx%d", bounds, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestThumbnailCropsAndAverages(t *testing.T) {
+	// A 4x2 image: the outer columns are red and get cropped away, the inner
+	// two columns are black and white and get averaged into one grey pixel
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	red := color.RGBA{R: 255, A: 255}
+	for y := 0; y < 2; y++ {
+		src.Set(0, y, red)
+		src.Set(1, y, color.Black)
+		src.Set(2, y, color.White)
+		src.Set(3, y, red)
+	}
+
+	thumbnail := Thumbnail(src, 1)
+	got := thumbnail.RGBAAt(0, 0)
+	if got.R != got.G || got.G != got.B || got.R < 120 || got.R > 135 || got.A != 255 {
+		t.Errorf("pixel = %v, want an opaque mid grey", got)
+	}
+}
@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/textnorm"
+)
+
+// MaxMissedWordLen is the longest normalised missed word that is stored
+const MaxMissedWordLen = 255
+
+// NormalizeMissedWord reduces a missed word to the key it is aggregated under, so
+// "Physics!! 📚", "physics" and "PHYSICS" all count as the same word
+func NormalizeMissedWord(word string) string {
+	return textnorm.New(textnorm.Options{
+		RemoveStopwords: config.GetMissedWordConfig().REMOVE_STOPWORDS,
+	}).Apply(word)
+}
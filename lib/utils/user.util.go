@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+)
+
+var nonAlnumRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Words that carry no meaning when matching department names ("Dept. of Textile Engg" == "textile")
+var deptFillerWords = map[string]bool{
+	"dept": true, "department": true, "of": true, "and": true,
+	"eng": true, "engg": true, "engineering": true,
+}
+
+// catalogKey lowercases a value and reduces it to space separated alphanumeric words
+func catalogKey(value string) string {
+	value = strings.ReplaceAll(strings.ToLower(value), "&", " and ")
+	return strings.TrimSpace(nonAlnumRegex.ReplaceAllString(value, " "))
+}
+
+func withoutFillerWords(key string) string {
+	words := []string{}
+	for _, word := range strings.Fields(key) {
+		if !deptFillerWords[word] {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// NormalizeDept maps any known spelling of a department to its catalog code
+func NormalizeDept(value string) (string, bool) {
+	key := catalogKey(value)
+	if key == "" {
+		return "", false
+	}
+
+	for _, dept := range config.Departments {
+		if key == catalogKey(dept.Code) || key == catalogKey(dept.Name) {
+			return dept.Code, true
+		}
+		for _, alias := range dept.Aliases {
+			if key == catalogKey(alias) {
+				return dept.Code, true
+			}
+		}
+	}
+
+	// Fall back to comparing without filler words, e.g. "textile eng" and "Textile Engineering"
+	stripped := withoutFillerWords(key)
+	if stripped == "" {
+		return "", false
+	}
+	for _, dept := range config.Departments {
+		if stripped == withoutFillerWords(catalogKey(dept.Name)) {
+			return dept.Code, true
+		}
+		for _, alias := range dept.Aliases {
+			if stripped == withoutFillerWords(catalogKey(alias)) {
+				return dept.Code, true
+			}
+		}
+	}
+	return "", false
+}
+
+// NormalizeRole maps any known spelling of a role to its catalog code
+func NormalizeRole(value string) (string, bool) {
+	key := catalogKey(value)
+	for _, role := range config.UserRoles {
+		if key == role.Code {
+			return role.Code, true
+		}
+		for _, alias := range role.Aliases {
+			if key == catalogKey(alias) {
+				return role.Code, true
+			}
+		}
+	}
+	return "", false
+}
+
+// NormalizeBatch checks that a batch is a number within the configured range and strips leading zeros
+func NormalizeBatch(value string) (string, bool) {
+	batch, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || batch < config.UserBatchMin || batch > config.UserBatchMax {
+		return "", false
+	}
+	return strconv.Itoa(batch), true
+}
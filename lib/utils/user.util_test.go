@@ -0,0 +1,81 @@
+package utils
+
+import "testing"
+
+func TestNormalizeDept(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   string
+		wantOk bool
+	}{
+		{"TE", "TE", true},
+		{"te", "TE", true},
+		{" Te ", "TE", true},
+		{"Textile", "TE", true},
+		{"Textile Engineering", "TE", true},
+		{"textile eng", "TE", true},
+		{"Dept. of Textile Engg", "TE", true},
+		{"Department of Textile Engineering", "TE", true},
+		{"%tex%", "TE", true},
+		{"Textile Engineering Management", "TEM", true},
+		{"textile management", "TEM", true},
+		{"Wet Process", "WPE", true},
+		{"Fashion Design & Apparel Engineering", "FDAE", true},
+		{"dyes and chemical", "DCE", true},
+		{"IPE", "IPE", true},
+		{"", "", false},
+		{"   ", "", false},
+		{"Department of Engineering", "", false},
+		{"Computer Science", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := NormalizeDept(tt.value)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("NormalizeDept(%q) = (%q, %v), want (%q, %v)", tt.value, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestNormalizeRole(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   string
+		wantOk bool
+	}{
+		{"student", "student", true},
+		{"Student", "student", true},
+		{"STD", "student", true},
+		{"ex-student", "alumni", true},
+		{"Lecturer", "teacher", true},
+		{"officer", "staff", true},
+		{"", "", false},
+		{"admin", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := NormalizeRole(tt.value)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("NormalizeRole(%q) = (%q, %v), want (%q, %v)", tt.value, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestNormalizeBatch(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   string
+		wantOk bool
+	}{
+		{"45", "45", true},
+		{" 045 ", "45", true},
+		{"", "", false},
+		{"forty", "", false},
+		{"0", "", false},
+		{"1000", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := NormalizeBatch(tt.value)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("NormalizeBatch(%q) = (%q, %v), want (%q, %v)", tt.value, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
@@ -1,24 +1,49 @@
 package utils
 
 import (
+	"errors"
 	"regexp"
 
 	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
 	"github.com/gofiber/fiber/v2"
 )
 
-// ValidateAdminKey checks if the provided admin key matches the configured key
+// ErrUnauthorized is returned by ValidateAdminKey so callers stop handling the request
+var ErrUnauthorized = fiber.NewError(fiber.StatusUnauthorized, "🔴 Unauthorized Access !")
+
+// ValidateAdminKey checks if the provided admin key matches the configured key.
+// It returns ErrUnauthorized on mismatch, which ErrorHandler renders as a 401.
 func ValidateAdminKey(c *fiber.Ctx, appConfig config.AppConfig) error {
 	if adminKey := c.Query("adminKey"); adminKey != appConfig.ADMIN_AUTH_KEY {
-		return c.Status(401).JSON(fiber.Map{
-			"Error": "🔴 Unauthorized Access !",
-		})
+		return ErrUnauthorized
 	}
 	return nil
 }
 
+// ErrorHandler renders errors returned by handlers as JSON. Fiber errors keep
+// their status and message, anything else becomes a generic 500.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var fiberError *fiber.Error
+	if errors.As(err, &fiberError) {
+		return c.Status(fiberError.Code).JSON(fiber.Map{
+			"Error": fiberError.Message,
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"Error": config.AppMessages.Error.Internal,
+	})
+}
+
 // ValidateEmail checks if the provided email matches a valid email format
 func ValidateEmail(email string) bool {
 	emailRegex := regexp.MustCompile(`^[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,4}$`)
 	return emailRegex.MatchString(email)
 }
+
+// NullIfEmpty maps empty strings to nil so optional columns are stored as NULL
+func NullIfEmpty(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
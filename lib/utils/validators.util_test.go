@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestValidateAdminKey(t *testing.T) {
+	appConfig := config.AppConfig{ADMIN_AUTH_KEY: "secret"}
+	reached := false
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Get("/", func(c *fiber.Ctx) error {
+		if err := ValidateAdminKey(c, appConfig); err != nil {
+			return err
+		}
+		reached = true
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	tests := []struct {
+		target  string
+		status  int
+		reached bool
+	}{
+		{"/", fiber.StatusUnauthorized, false},
+		{"/?adminKey=wrong", fiber.StatusUnauthorized, false},
+		{"/?adminKey=secret", fiber.StatusOK, true},
+	}
+	for _, tt := range tests {
+		reached = false
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, tt.target, nil), -1)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.target, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.target, resp.StatusCode, tt.status)
+		}
+		if reached != tt.reached {
+			t.Errorf("%s: handler reached = %v, want %v", tt.target, reached, tt.reached)
+		}
+		if tt.status == fiber.StatusUnauthorized && string(body) != `{"Error":"🔴 Unauthorized Access !"}` {
+			t.Errorf("%s: body = %s", tt.target, body)
+		}
+	}
+}
+
+func TestValidateEmail(t *testing.T) {
+	tests := map[string]bool{
+		"student@butex.edu.bd": true,
+		"a.b+c@example.com":    true,
+		"":                     false,
+		"no-at-sign.com":       false,
+		"UPPER@example.com":    false,
+		"user@host":            false,
+	}
+	for email, want := range tests {
+		if got := ValidateEmail(email); got != want {
+			t.Errorf("ValidateEmail(%q) = %v, want %v", email, got, want)
+		}
+	}
+}
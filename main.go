@@ -5,7 +5,10 @@ import (
 	"log"
 	"os"
 
+	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
 	"github.com/TriptoAfsin/notebot-anlaytics-go/db"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/jobs"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/utils"
 	"github.com/TriptoAfsin/notebot-anlaytics-go/routes"
 
 	"github.com/gofiber/fiber/v2"
@@ -25,9 +28,16 @@ func main() {
 
 	// Init DB
 	db.InitDB()
+	db.Migrate(db.DB)
+
+	// Start background jobs
+	retention := jobs.NewErrorLogRetention(db.DB, config.GetRetentionConfig())
+	retention.Start()
 
 	// Init Fiber
-	app := fiber.New()
+	app := fiber.New(fiber.Config{
+		ErrorHandler: utils.ErrorHandler,
+	})
 
 	// Add CORS middleware
 	app.Use(cors.New(cors.Config{
@@ -37,7 +47,7 @@ func main() {
 	}))
 
 	// Init Route
-	routes.RouteInit(app, db.DB)
+	routes.RouteInit(app, db.DB, retention)
 
 	// Get port from environment variable or use default
 	port := os.Getenv("PORT")
@@ -3,11 +3,22 @@ package routes
 import (
 	"github.com/TriptoAfsin/notebot-anlaytics-go/config"
 	"github.com/TriptoAfsin/notebot-anlaytics-go/handler"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/alerts"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/jobs"
+	"github.com/TriptoAfsin/notebot-anlaytics-go/lib/storage"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
-func RouteInit(app *fiber.App, db *gorm.DB) {
+func RouteInit(app *fiber.App, db *gorm.DB, retention *jobs.ErrorLogRetention) {
+
+	alertManager := alerts.NewManager(config.GetAlertConfig())
+	storageConfig := config.GetStorageConfig()
+	store := storage.NewLocalStorage(storageConfig.UPLOAD_DIR, storageConfig.UPLOAD_BASE_URL)
+	privacyConfig := config.GetPrivacyConfig()
+
+	// Uploaded files such as profile images
+	app.Static(store.BaseURL, store.Dir)
 
 	app.Get("/", handler.ApiHandler)
 
@@ -27,87 +38,81 @@ func RouteInit(app *fiber.App, db *gorm.DB) {
 	app.Get("/games/notedino", handler.GetNoteDinoHof(db))
 
 	// Error logging routes
-	app.Post("/logs/err", handler.PostNewError(db, config.GetAppConfig()))
+	app.Post("/logs/err", handler.PostNewError(db, config.GetAppConfig(), alertManager))
+	app.Post("/logs/err/batch", handler.PostErrorBatch(db, config.GetAppConfig(), alertManager))
 	app.Post("/logs/err/email", handler.GetErrorsByEmail(db, config.GetAppConfig()))
 	app.Get("/logs/err", handler.GetErrorLogs(db, config.GetAppConfig()))
+	app.Get("/logs/err/stats", handler.GetErrorStats(db, config.GetAppConfig()))
+
+	// Error issue routes
+	app.Get("/logs/err/issues", handler.GetErrorIssues(db, config.GetAppConfig()))
+	app.Get("/logs/err/issues/:id", handler.GetErrorIssue(db, config.GetAppConfig()))
+	app.Patch("/logs/err/issues/:id", handler.UpdateErrorIssueStatus(db, config.GetAppConfig()))
+
+	// Error log retention routes
+	app.Get("/logs/err/retention", handler.PreviewErrorLogRetention(retention, config.GetAppConfig()))
+	app.Post("/logs/err/retention/run", handler.RunErrorLogRetention(retention, config.GetAppConfig()))
+
+	// Error alerting routes
+	app.Get("/alerts/rules", handler.GetAlertRules(alertManager, config.GetAppConfig()))
+	app.Post("/alerts/test", handler.TestAlertWebhook(alertManager, config.GetAppConfig()))
 
 	// User routes
 	app.Post("/user/new", handler.CreateUser(db))
 	app.Get("/users/app", handler.GetAllUsers(db))
+	app.Get("/users/catalog", handler.GetUserCatalog)
+	app.Get("/users/count", handler.GetUserCount(db))
+	app.Get("/users/stats", handler.GetUserStats(db))
 	app.Post("/users/app/email", handler.GetUsersByEmail(db))
 	app.Post("/users/app/batch_dept", handler.GetUsersByDeptAndBatch(db))
+	app.Get("/users/app/query", handler.QueryUsers(db))
+	app.Get("/users/app/lookup", handler.GetUserByEmail(db))
+	app.Post("/users/app/dedupe", handler.MergeDuplicateUsers(db))
+	app.Post("/users/app/normalize", handler.NormalizeUsers(db))
+	app.Post("/users/app/import", handler.ImportUsers(db))
+	app.Get("/users/app/export", handler.ExportUsers(db))
+	app.Post("/users/app/:id/avatar", handler.UploadUserAvatar(db, store, storageConfig))
+	app.Get("/users/app/:id", handler.GetUserByID(db))
+	app.Patch("/users/app/:id", handler.UpdateUser(db))
+	app.Delete("/users/app/:id", handler.DeleteUser(db))
+	app.Post("/users/app/:id/restore", handler.RestoreUser(db))
+
+	// Personal data routes
+	app.Get("/privacy/export", handler.ExportPersonalData(db, config.GetAppConfig()))
+	app.Post("/privacy/erase", handler.ErasePersonalData(db, store, retention, privacyConfig, config.GetAppConfig()))
+	app.Get("/privacy/audit", handler.GetErasureAudit(db, privacyConfig, config.GetAppConfig()))
 
 	// Missed words routes
 	app.Get("/missed", handler.GetMissedWords(db))
+	app.Get("/missed/clusters", handler.GetMissedWordClusters(db))
+	app.Get("/missed/trending", handler.GetTrendingMissedWords(db))
 	app.Post("/missed", handler.CreateMissedWord(db))
+	app.Post("/missed/aggregate", handler.AggregateMissedWords(db))
+	app.Get("/missed/mappings", handler.GetMissedWordMappings(db))
+	app.Post("/missed/mappings", handler.CreateMissedWordMappings(db))
+	app.Delete("/missed/mappings/:id", handler.DeleteMissedWordMapping(db))
+
+	// Subject catalog routes
+	app.Get("/catalog/subjects", handler.GetSubjectCatalog(db))
+	app.Get("/catalog/subjects/report", handler.GetSubjectCatalogReport(db))
+	app.Get("/catalog/subjects/:id", handler.GetSubjectCatalogEntry(db))
+	app.Post("/catalog/subjects", handler.CreateSubjectCatalogEntry(db))
+	app.Patch("/catalog/subjects/:id", handler.UpdateSubjectCatalogEntry(db))
+	app.Delete("/catalog/subjects/:id", handler.DeleteSubjectCatalogEntry(db))
+
+	// Academic analytics routes
+	app.Get("/academic/stats", handler.GetAcademicStats(db))
+	app.Get("/academic/ranking", handler.GetSubjectRanking(db))
 
 	// Notes routes
 	app.Get("/notes", handler.GetTopNoteSubjects(db))
 	app.Get("/notes/top", handler.GetTopNoteSubjects(db))
-
-	// Individual subject routes
-	app.Get("/notes/math1", handler.NotesMath1(db))
-	app.Get("/notes/math2", handler.NotesMath2(db))
-	app.Get("/notes/phy1", handler.NotesPhy1(db))
-	app.Get("/notes/phy2", handler.NotesPhy2(db))
-	app.Get("/notes/chem1", handler.NotesChem1(db))
-	app.Get("/notes/chem2", handler.NotesChem2(db))
-	app.Get("/notes/pse", handler.NotesPse(db))
-	app.Get("/notes/cp", handler.NotesCp(db))
-	app.Get("/notes/ntf", handler.NotesNtf(db))
-	app.Get("/notes/bce", handler.NotesBce(db))
-	app.Get("/notes/em", handler.NotesEm(db))
-	app.Get("/notes/am1", handler.NotesAm1(db))
-	app.Get("/notes/am2", handler.NotesAm2(db))
-	app.Get("/notes/ym1", handler.NotesYm1(db))
-	app.Get("/notes/ym2", handler.NotesYm2(db))
-	app.Get("/notes/fm1", handler.NotesFm1(db))
-	app.Get("/notes/fm2", handler.NotesFm2(db))
-	app.Get("/notes/wp1", handler.NotesWp1(db))
-	app.Get("/notes/wp2", handler.NotesWp2(db))
-	app.Get("/notes/stat", handler.NotesStat(db))
-	app.Get("/notes/market", handler.NotesMarket(db))
-	app.Get("/notes/feee", handler.NotesFeee(db))
-	app.Get("/notes/ttqc", handler.NotesTtqc(db))
-	app.Get("/notes/tp", handler.NotesTp(db))
-	app.Get("/notes/mp", handler.NotesMp(db))
-	app.Get("/notes/mmtf", handler.NotesMmtf(db))
-	app.Get("/notes/acm", handler.NotesAcm(db))
-	app.Get("/notes/tqm", handler.NotesTqm(db))
-	app.Get("/notes/fsd", handler.NotesFsd(db))
-	app.Get("/notes/ace", handler.NotesAce(db))
-	app.Get("/notes/mic", handler.NotesMic(db))
-	app.Get("/notes/sss1", handler.NotesSss1(db))
-	app.Get("/notes/sss2", handler.NotesSss2(db))
-	app.Get("/notes/wpp", handler.NotesWpp(db))
-	app.Get("/notes/econo", handler.NotesEcono(db))
+	app.Get("/notes/:code", handler.IncrementCatalogCount(db, config.SubjectTypeNote))
+	app.Get("/notes/:code/daily", handler.GetSubjectDailySeries(db, config.SubjectTypeNote))
 
 	// Labs routes
 	app.Get("/labs", handler.GetLabSubjects(db))
 	app.Get("/labs/top", handler.GetTopLabSubjects(db))
-
-	// We'll need to create these handlers in academic.handler.go
-	app.Get("/labs/phy1", handler.LabsPhy1(db))
-	app.Get("/labs/phy2", handler.LabsPhy2(db))
-	app.Get("/labs/chem1", handler.LabsChem1(db))
-	app.Get("/labs/chem2", handler.LabsChem2(db))
-	app.Get("/labs/cp", handler.LabsCP(db))
-	app.Get("/labs/bce", handler.LabsBce(db))
-	app.Get("/labs/msp", handler.LabsMsp(db))
-	app.Get("/labs/am1", handler.LabsAm1(db))
-	app.Get("/labs/am2", handler.LabsAm2(db))
-	app.Get("/labs/ym1", handler.LabsYm1(db))
-	app.Get("/labs/ym2", handler.LabsYm2(db))
-	app.Get("/labs/wp1", handler.LabsWp1(db))
-	app.Get("/labs/wp2", handler.LabsWp2(db))
-	app.Get("/labs/fm1", handler.LabsFm1(db))
-	app.Get("/labs/fm2", handler.LabsFm2(db))
-	app.Get("/labs/feee", handler.LabsFeee(db))
-	app.Get("/labs/fme", handler.LabsFme(db))
-	app.Get("/labs/ttqc", handler.LabsTtqc(db))
-	app.Get("/labs/ap1", handler.LabsAp1(db))
-	app.Get("/labs/ap2", handler.LabsAp2(db))
-	app.Get("/labs/mp", handler.LabsMp(db))
-	app.Get("/labs/fsd", handler.LabsFsd(db))
-	app.Get("/labs/lss", handler.LabsLss(db))
+	app.Get("/labs/:code", handler.IncrementCatalogCount(db, config.SubjectTypeLab))
+	app.Get("/labs/:code/daily", handler.GetSubjectDailySeries(db, config.SubjectTypeLab))
 }